@@ -0,0 +1,51 @@
+// Package abi implements the words of Ethereum contract ABI encoding shared by the address generators,
+// the connectors and the signer. It has no dependencies.
+package abi
+
+import (
+	"math/big"
+)
+
+// Sizes of ABI word and Ethereum address in bytes.
+const (
+	WordSize    = 32
+	AddressSize = 20
+)
+
+// AddressWord returns the ABI encoded address, zero address for nil.
+func AddressWord(addr []byte) []byte {
+	word := make([]byte, WordSize)
+	copy(word[WordSize-len(addr):], addr)
+	return word
+}
+
+// UintWord returns the ABI encoded unsigned number, zero for nil.
+func UintWord(n *big.Int) []byte {
+	word := make([]byte, WordSize)
+	if n != nil {
+		PutUint(word, n)
+	}
+	return word
+}
+
+// BytesWords returns the ABI encoded dynamic bytes: the size and the data padded to the words.
+func BytesWords(b []byte) []byte {
+	data := UintWord(big.NewInt(int64(len(b))))
+	data = append(data, b...)
+	return append(data, make([]byte, PaddedSize(b)-len(b))...)
+}
+
+// PaddedSize returns the size of the bytes padded to the words.
+func PaddedSize(b []byte) int {
+	return (len(b) + WordSize - 1) / WordSize * WordSize
+}
+
+// PutUint writes the unsigned number big-endian to the end of dst and zeroes the leading bytes.
+// The number shall fit dst.
+func PutUint(dst []byte, x *big.Int) {
+	b := x.Bytes()
+	for i := range dst[:len(dst)-len(b)] {
+		dst[i] = 0
+	}
+	copy(dst[len(dst)-len(b):], b)
+}
@@ -0,0 +1,28 @@
+package abi
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, append(make([]byte, 12), bytes.Repeat([]byte{0x35}, 20)...), AddressWord(bytes.Repeat([]byte{0x35}, 20)),
+		"unexpected address word")
+	assert.Equal(t, make([]byte, WordSize), AddressWord(nil), "unexpected zero address")
+	assert.Equal(t, append(make([]byte, 30), 0x01, 0x00), UintWord(big.NewInt(256)), "unexpected number word")
+	assert.Equal(t, make([]byte, WordSize), UintWord(nil), "unexpected zero number")
+
+	data := BytesWords([]byte{0xa9, 0x05})
+	assert.Len(t, data, 2*WordSize, "unexpected size")
+	assert.Equal(t, UintWord(big.NewInt(2)), data[:WordSize], "unexpected bytes size")
+	assert.Equal(t, []byte{0xa9, 0x05}, data[WordSize:WordSize+2], "unexpected bytes")
+	assert.Equal(t, 0, PaddedSize(nil), "unexpected empty padding")
+	assert.Equal(t, 2*WordSize, PaddedSize(make([]byte, WordSize+1)), "unexpected padding")
+
+	dst := bytes.Repeat([]byte{0xff}, 4)
+	PutUint(dst, big.NewInt(0x0102))
+	assert.Equal(t, []byte{0, 0, 1, 2}, dst, "the leading bytes shall be zeroed")
+}
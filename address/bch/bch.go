@@ -0,0 +1,141 @@
+package bch
+
+import (
+	"fmt"
+
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+type (
+	// Generator creates CashAddr addresses: P2SH multisig or P2PKH for a single signer.
+	// The legacy addresses are generated for the forks without CashAddr.
+	Generator struct {
+		btc          btc_example.Generator
+		prefix       string
+		net          *btcchaincfg.Params
+		noScriptHash bool
+	}
+)
+
+// New creates a generator of the addresses with the CashAddr prefix, see Prefix.
+func New(prefix string) Generator {
+	return Generator{btc: btc_example.New(), prefix: prefix}
+}
+
+// NewForFork creates a generator of the addresses of the fork network.
+func NewForFork(fork netparams.ForkParams) Generator {
+	return Generator{btc: btc_example.New(), prefix: fork.CashAddrPrefix, net: fork.Net, noScriptHash: fork.Standard.NoScriptHash}
+}
+
+// AddressGenerate - main function for wallet service address generation
+func (g Generator) AddressGenerate(params hd.GeneratorParameters) (string, error) {
+	if err := g.checkScriptHash(params); err != nil {
+		return "", err
+	}
+	legacy, err := g.btc.AddressGenerateForNet(params, g.legacyNet())
+	if err != nil {
+		return "", err
+	}
+	return g.cashAddress(legacy)
+}
+
+// AddressGenerateRange generates count addresses for the indexes starting from params.PathIndex.
+func (g Generator) AddressGenerateRange(params hd.GeneratorParameters, count uint32) ([]string, error) {
+	if err := g.checkScriptHash(params); err != nil {
+		return nil, err
+	}
+	addresses, err := g.btc.AddressGenerateRange(params, g.legacyNet(), count)
+	if err != nil {
+		return nil, err
+	}
+	for i := range addresses {
+		addresses[i], err = g.cashAddress(addresses[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return addresses, nil
+}
+
+// checkScriptHash rejects the multisig addresses, which are P2SH, if the fork does not accept P2SH.
+func (g Generator) checkScriptHash(params hd.GeneratorParameters) error {
+	if g.noScriptHash && len(params.SignersXpubs) > 1 {
+		return fmt.Errorf("P2SH multisig addresses are not supported by the network")
+	}
+	return nil
+}
+
+// legacyNet returns BTC params with the same legacy address versions as the network of the prefix.
+func (g Generator) legacyNet() btcchaincfg.Params {
+	if g.net != nil {
+		return *g.net
+	}
+	if g.prefix == PrefixMainNet {
+		return btcchaincfg.MainNetParams
+	}
+	return btcchaincfg.TestNet3Params
+}
+
+// cashAddress converts the legacy address generated for legacyNet.
+func (g Generator) cashAddress(legacy string) (string, error) {
+	net := g.legacyNet()
+	return CashAddress(legacy, g.prefix, &net)
+}
+
+// CashAddress converts the legacy address of net into CashAddr with the prefix.
+// The address is returned as is if the prefix is empty.
+func CashAddress(legacy, prefix string, net *btcchaincfg.Params) (string, error) {
+	hash, version, err := base58.CheckDecode(legacy)
+	if err != nil {
+		return "", err
+	}
+	var addrType byte
+	switch version {
+	case net.PubKeyHashAddrID:
+		addrType = AddrTypeP2PKH
+	case net.ScriptHashAddrID:
+		addrType = AddrTypeP2SH
+	default:
+		return "", fmt.Errorf("unexpected address version %d", version)
+	}
+	if prefix == "" {
+		return legacy, nil
+	}
+	return EncodeCashAddress(prefix, addrType, hash)
+}
+
+// LegacyAddress converts CashAddr with the prefix (or without it) into the legacy address of net.
+// The legacy addresses of net are returned as is, CashAddr is not accepted if the prefix is empty.
+func LegacyAddress(addr, prefix string, net *btcchaincfg.Params) (string, error) {
+	if hash, version, err := base58.CheckDecode(addr); err == nil {
+		if version != net.PubKeyHashAddrID && version != net.ScriptHashAddrID {
+			return "", fmt.Errorf("unexpected address version %d", version)
+		}
+		if len(hash) != 20 {
+			return "", fmt.Errorf("invalid hash length %d", len(hash))
+		}
+		return addr, nil
+	}
+	if prefix == "" {
+		return "", fmt.Errorf("invalid address %s", addr)
+	}
+	addrType, hash, err := DecodeCashAddress(addr, prefix)
+	if err != nil {
+		return "", err
+	}
+	if len(hash) != 20 {
+		return "", fmt.Errorf("unsupported hash length %d", len(hash))
+	}
+	switch addrType {
+	case AddrTypeP2PKH:
+		return base58.CheckEncode(hash, net.PubKeyHashAddrID), nil
+	case AddrTypeP2SH:
+		return base58.CheckEncode(hash, net.ScriptHashAddrID), nil
+	}
+	return "", fmt.Errorf("unsupported address type %d", addrType)
+}
@@ -0,0 +1,118 @@
+package bch
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+func TestCashAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		prefix   string
+		addrType byte
+		hash     string
+	}{
+		{"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", PrefixMainNet, AddrTypeP2PKH, "76a04053bda0a88bda5177b86a15c3b29f559873"},
+		{"bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq", PrefixMainNet, AddrTypeP2SH, "76a04053bda0a88bda5177b86a15c3b29f559873"},
+		{"bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", PrefixRegTest, AddrTypeP2PKH, "b9e6fa37edaf12df0a0036257e7e89a9abb42fae"},
+		{"bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", PrefixRegTest, AddrTypeP2SH, "af70bbab80fb64dbf90b212f4971cc4807d0b880"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			hash, _ := hex.DecodeString(tt.hash)
+			address, err := EncodeCashAddress(tt.prefix, tt.addrType, hash)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.address, address, "unexpected address")
+
+			addrType, decoded, err := DecodeCashAddress(tt.address, tt.prefix)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.addrType, addrType, "unexpected type")
+			assert.Equal(t, hash, decoded, "unexpected hash")
+
+			// without the prefix
+			_, _, err = DecodeCashAddress(tt.address[len(tt.prefix)+1:], tt.prefix)
+			assert.Nil(t, err, "unexpected error")
+			_, _, err = DecodeCashAddress(tt.address, PrefixTestNet)
+			assert.NotNil(t, err, "error expected for another prefix")
+		})
+	}
+
+	_, _, err := DecodeCashAddress("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b", "")
+	assert.NotNil(t, err, "error expected for invalid checksum")
+}
+
+func TestGenerator_AddressGenerate(t *testing.T) {
+	xpubs := []string{
+		"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+		"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+		"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+	}
+	params := hd.GeneratorParameters{SignersXpubs: xpubs, SignersRequired: 2, PathIndex: 1000}
+
+	// the wallet address of the BCH regtest transactions
+	address, err := New(PrefixRegTest).AddressGenerate(params)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", address, "unexpected address")
+
+	addresses, err := New(Prefix("regtest")).AddressGenerateRange(params, 2)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, address, addresses[0], "unexpected address")
+
+	address, err = New(PrefixMainNet).AddressGenerate(hd.GeneratorParameters{SignersXpubs: xpubs[:1], SignersRequired: 1})
+	assert.Nil(t, err, "unexpected error")
+	addrType, _, err := DecodeCashAddress(address, PrefixMainNet)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, AddrTypeP2PKH, addrType, "single signer address shall be P2PKH")
+
+	bsv := NewForFork(netparams.BsvMainNetFork)
+	_, err = bsv.AddressGenerate(params)
+	assert.NotNil(t, err, "expected error for BSV P2SH address")
+	_, err = bsv.AddressGenerateRange(params, 2)
+	assert.NotNil(t, err, "expected error for BSV P2SH addresses")
+	address, err = bsv.AddressGenerate(hd.GeneratorParameters{SignersXpubs: xpubs[:1], SignersRequired: 1})
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "1", address[:1], "BSV single signer address shall be P2PKH")
+}
+
+func TestLegacyAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		fork    netparams.ForkParams
+		address string
+		legacy  string
+		wantErr bool
+	}{
+		{"bch cash", netparams.BchMainNetFork, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", false},
+		{"bch legacy", netparams.BchMainNetFork, "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", false},
+		{"xec cash", netparams.XecMainNetFork, "ecash:qpm2qsznhks23z7629mms6s4cwef74vcwva87rkuu2", "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", false},
+		{"xec cash without prefix", netparams.XecMainNetFork, "qpm2qsznhks23z7629mms6s4cwef74vcwva87rkuu2", "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", false},
+		{"xec with bch prefix", netparams.XecMainNetFork, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", "", true},
+		{"bsv legacy", netparams.BsvMainNetFork, "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", false},
+		{"bsv cash", netparams.BsvMainNetFork, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", "", true},
+		{"bsv testnet legacy on mainnet", netparams.BsvMainNetFork, "mrLC19Je2BuWQDkWSTriGYPyQJXKkkBmCx", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legacy, err := LegacyAddress(tt.address, tt.fork.CashAddrPrefix, tt.fork.Net)
+			if tt.wantErr {
+				assert.NotNil(t, err, "expected error")
+				return
+			}
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.legacy, legacy, "unexpected legacy address")
+
+			address, err := CashAddress(legacy, tt.fork.CashAddrPrefix, tt.fork.Net)
+			assert.Nil(t, err, "unexpected error")
+			if tt.fork.CashAddrPrefix == "" {
+				assert.Equal(t, legacy, address, "unexpected address")
+			} else {
+				assert.Equal(t, tt.fork.CashAddrPrefix+":qpm2qsznhks23z7629mms6s4cwef74vcw", address[:len(tt.fork.CashAddrPrefix)+34], "unexpected address")
+			}
+		})
+	}
+}
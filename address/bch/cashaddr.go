@@ -0,0 +1,166 @@
+package bch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CashAddr prefixes of the networks.
+const (
+	PrefixMainNet = "bitcoincash"
+	PrefixTestNet = "bchtest"
+	PrefixRegTest = "bchreg"
+)
+
+// CashAddr types of the addresses.
+const (
+	AddrTypeP2PKH byte = 0
+	AddrTypeP2SH  byte = 1
+)
+
+const cashAddrCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// hashSizes are the hash sizes in bytes by the size code of the version byte.
+var hashSizes = []int{20, 24, 28, 32, 40, 48, 56, 64}
+
+// Prefix returns the CashAddr prefix for the ChainConfig of the wallet.
+func Prefix(chainConfig string) string {
+	switch chainConfig {
+	case "regtest":
+		return PrefixRegTest
+	case "testnet":
+		return PrefixTestNet
+	}
+	return PrefixMainNet
+}
+
+// EncodeCashAddress encodes the hash of the type into the CashAddr with the prefix.
+func EncodeCashAddress(prefix string, addrType byte, hash []byte) (string, error) {
+	sizeCode := -1
+	for code, size := range hashSizes {
+		if size == len(hash) {
+			sizeCode = code
+		}
+	}
+	if sizeCode < 0 {
+		return "", fmt.Errorf("invalid hash length %d", len(hash))
+	}
+	if addrType > 0x0f {
+		return "", fmt.Errorf("invalid address type %d", addrType)
+	}
+	payload := append([]byte{addrType<<3 | byte(sizeCode)}, hash...)
+	data, err := convertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := polymod(append(prefixData(prefix), append(data, 0, 0, 0, 0, 0, 0, 0, 0)...))
+	for i := 0; i < 8; i++ {
+		data = append(data, byte(checksum>>uint(5*(7-i)))&0x1f)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteByte(':')
+	for _, b := range data {
+		sb.WriteByte(cashAddrCharset[b])
+	}
+	return sb.String(), nil
+}
+
+// DecodeCashAddress decodes the CashAddr, the address without the prefix is decoded with defaultPrefix.
+// The prefix shall be defaultPrefix if it's not empty.
+func DecodeCashAddress(addr, defaultPrefix string) (addrType byte, hash []byte, err error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return 0, nil, fmt.Errorf("mixed case address")
+	}
+	addr = strings.ToLower(addr)
+	prefix, payload := defaultPrefix, addr
+	if i := strings.LastIndexByte(addr, ':'); i >= 0 {
+		prefix, payload = addr[:i], addr[i+1:]
+		if defaultPrefix != "" && prefix != defaultPrefix {
+			return 0, nil, fmt.Errorf("unexpected prefix %q", prefix)
+		}
+	}
+	if prefix == "" {
+		return 0, nil, fmt.Errorf("prefix is absent")
+	}
+	if len(payload) <= 8 {
+		return 0, nil, fmt.Errorf("invalid address length")
+	}
+
+	data := make([]byte, len(payload))
+	for i := range payload {
+		pos := strings.IndexByte(cashAddrCharset, payload[i])
+		if pos < 0 {
+			return 0, nil, fmt.Errorf("invalid character %q", payload[i])
+		}
+		data[i] = byte(pos)
+	}
+	if polymod(append(prefixData(prefix), data...)) != 0 {
+		return 0, nil, fmt.Errorf("invalid checksum")
+	}
+	decoded, err := convertBits(data[:len(data)-8], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(decoded) == 0 || decoded[0]&0x80 != 0 {
+		return 0, nil, fmt.Errorf("invalid version")
+	}
+	hash = decoded[1:]
+	if len(hash) != hashSizes[decoded[0]&0x07] {
+		return 0, nil, fmt.Errorf("invalid hash length %d", len(hash))
+	}
+	return decoded[0] >> 3, hash, nil
+}
+
+// prefixData returns the lower 5 bits of the prefix characters followed by the separator.
+func prefixData(prefix string) []byte {
+	data := make([]byte, len(prefix)+1)
+	for i := range prefix {
+		data[i] = prefix[i] & 0x1f
+	}
+	return data
+}
+
+// polymod calculates the CashAddr checksum, it's 0 for the valid data with the checksum.
+func polymod(values []byte) uint64 {
+	generators := []uint64{0x98f2bc8e61, 0x79b76d99e2, 0xf33e5fb3c4, 0xae2eabe2a8, 0x1e4f43e470}
+	c := uint64(1)
+	for _, d := range values {
+		c0 := byte(c >> 35)
+		c = ((c & 0x07ffffffff) << 5) ^ uint64(d)
+		for i, g := range generators {
+			if c0&(1<<uint(i)) != 0 {
+				c ^= g
+			}
+		}
+	}
+	return c ^ 1
+}
+
+// convertBits regroups the bits of the data.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, value := range data {
+		if uint32(value)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data value %d", value)
+		}
+		acc = acc<<fromBits | uint32(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return out, nil
+}
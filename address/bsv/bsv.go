@@ -0,0 +1,17 @@
+package bsv
+
+import (
+	"github.com/stanche/crypto-interface/address/bch"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// New creates a generator of BSV main network addresses, BSV uses the legacy addresses only.
+// The multisig addresses are rejected: P2SH is not evaluated since the Genesis upgrade, see StandardPolicy.NoScriptHash.
+func New() bch.Generator {
+	return bch.NewForFork(netparams.BsvMainNetFork)
+}
+
+// NewTestnet creates a generator of BSV test network addresses.
+func NewTestnet() bch.Generator {
+	return bch.NewForFork(netparams.BsvTestNetFork)
+}
@@ -1,14 +1,15 @@
 package btc_example
 
 import (
-	"crypto/ecdsa"
 	"fmt"
+	"runtime"
 	"sort"
+	"sync"
+	"sync/atomic"
 
 	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
 	btscript "github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
-	btckeychain "github.com/btcsuite/btcutil/hdkeychain"
 
 	"github.com/stanche/crypto-interface/address/hd"
 )
@@ -17,99 +18,144 @@ const MaxSigners = 15
 
 type (
 	// Generator is a struct able to create a trx address.
-	Generator struct{}
+	// Branch keys of the xpubs are cached, so the generator shall be reused for many addresses.
+	Generator struct {
+		cache *hd.BranchCache
+		net   *btcchaincfg.Params
+	}
 )
 
 // New creates a new trx generator instance
 func New() Generator {
-	return Generator{}
+	return Generator{cache: hd.NewBranchCache()}
+}
+
+// NewForNet creates a generator of the addresses of a BTC-like chain.
+func NewForNet(netParams *btcchaincfg.Params) Generator {
+	return Generator{cache: hd.NewBranchCache(), net: netParams}
 }
 
 // AddressGenerate - main function for wallet service address generation
 func (g Generator) AddressGenerate(params hd.GeneratorParameters) (address string, err error) {
 	netParams := btcchaincfg.TestNet3Params
+	if g.net != nil {
+		netParams = *g.net
+	}
 	return g.AddressGenerateForNet(params, netParams)
 }
 
-func (Generator) AddressGenerateForNet(params hd.GeneratorParameters, netParams btcchaincfg.Params) (address string, err error) {
-	//func Generate(hdPath string, xPubs []string, signersRequired uint8) (address string, tag string, err error) {
+func (g Generator) AddressGenerateForNet(params hd.GeneratorParameters, netParams btcchaincfg.Params) (address string, err error) {
+	if err = validateParams(params); err != nil {
+		return "", err
+	}
+	return g.address(params, params.PathIndex, &netParams)
+}
+
+// AddressGenerateRange generates count addresses for the indexes starting from params.PathIndex.
+// The addresses are derived in parallel and returned in the index order.
+func (g Generator) AddressGenerateRange(params hd.GeneratorParameters, netParams btcchaincfg.Params, count uint32) ([]string, error) {
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+	if uint64(params.PathIndex)+uint64(count) > hd.HardenedKeyStart {
+		return nil, fmt.Errorf("index range exceeds non-hardened keys")
+	}
+	if g.cache == nil {
+		// share the branch keys between the workers
+		g.cache = hd.NewBranchCache()
+	}
+
+	addresses := make([]string, count)
+	errs := make([]error, count)
+	workers := runtime.NumCPU()
+	if workers > int(count) {
+		workers = int(count)
+	}
+	var next uint32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddUint32(&next, 1) - 1
+				if i >= count {
+					return
+				}
+				addresses[i], errs[i] = g.address(params, params.PathIndex+i, &netParams)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("address %d error: %s", params.PathIndex+uint32(i), err.Error())
+		}
+	}
+	return addresses, nil
+}
 
+func validateParams(params hd.GeneratorParameters) error {
 	signersTotal := len(params.SignersXpubs)
 
 	if signersTotal < 1 || signersTotal > MaxSigners {
-		return "", fmt.Errorf("invalid signers quantity")
+		return fmt.Errorf("invalid signers quantity")
 	}
 	signersRequired := int(params.SignersRequired)
 	if signersRequired < 1 || signersRequired > signersTotal {
-		return "", fmt.Errorf("Invalid signersRequired: %d", signersRequired)
+		return fmt.Errorf("Invalid signersRequired: %d", signersRequired)
 	}
 
 	for i := 0; i < signersTotal; i++ {
 		if params.SignersXpubs[i] == "" {
-			return "", fmt.Errorf("invalid xpubs")
+			return fmt.Errorf("invalid xpubs")
 		}
 	}
+	return nil
+}
 
-	//var xPub string
-	//
-	var btcExtKey *btckeychain.ExtendedKey
-
-	hdPath := []uint32{
-		0, params.PathIndex,
-	}
-	xPub, err := hd.XPubByPath(params.SignersXpubs[0], hdPath)
-	if err != nil {
-
-		return "", fmt.Errorf("xPubByPath error: %s", err.Error())
-	}
-	//var public *ecdsa.PublicKey
-	_, btcExtKey, err = hd.PubKeyFromXpub(xPub)
-	if err != nil {
-		return "", err
+// address derives the address xpub/0/index of the validated params.
+func (g Generator) address(params hd.GeneratorParameters, index uint32, netParams *btcchaincfg.Params) (string, error) {
+	if len(params.SignersXpubs) == 1 {
+		btcExtKey, err := g.cache.Child(params.SignersXpubs[0], 0, index)
+		if err != nil {
+			return "", fmt.Errorf("xPubByPath error: %s", err.Error())
+		}
+		btcAddressObj, err := btcExtKey.Address(netParams)
+		if err != nil {
+			return "", err
+		}
+		return btcAddressObj.String(), nil
 	}
 
-	if signersTotal == 1 {
-		var btcAddressObj *btcutil.AddressPubKeyHash
-
-		btcAddressObj, err = btcExtKey.Address(&netParams)
+	pubKeysList := make(hd.BtcPubkeyList, 0, len(params.SignersXpubs))
+	for _, xpub := range params.SignersXpubs {
+		btcExtKey, err := g.cache.Child(xpub, 0, index)
 		if err != nil {
-			return "", err
+			return "", fmt.Errorf("GenerateKeyPairByHDKey error: %s", err.Error())
 		}
-		address = btcAddressObj.String()
-	} else {
-		var pubKeysList hd.BtcPubkeyList
-		var publicECDSA *ecdsa.PublicKey
-		var addressPubKey *btcutil.AddressPubKey
-		var multisigScript []byte
-		var addrScriptHash *btcutil.AddressScriptHash
-		for i := 0; i < signersTotal; i++ {
-			publicECDSA, err = hd.XPublicByHdPath(params.SignersXpubs[i], hdPath)
-			if err != nil {
-				return "", fmt.Errorf("GenerateKeyPairByHDKey error: %s", err.Error())
-			}
-			addressPubKey, err = btcutil.NewAddressPubKey(
-				hd.BtcecPublicKeyFromECDSA(publicECDSA).SerializeCompressed(),
-				&netParams)
-			if err != nil {
-				return "", fmt.Errorf("NewAddressPubKey error: %s", err.Error())
-			}
-			pubKeysList = append(pubKeysList, addressPubKey)
+		pubKey, err := btcExtKey.ECPubKey()
+		if err != nil {
+			return "", fmt.Errorf("GenerateKeyPairByHDKey error: %s", err.Error())
 		}
-
-		sort.Sort(pubKeysList)
-
-		multisigScript, err = btscript.MultiSigScript(pubKeysList, int(signersRequired))
+		addressPubKey, err := btcutil.NewAddressPubKey(pubKey.SerializeCompressed(), netParams)
 		if err != nil {
-
-			return "", fmt.Errorf("MultiSigScript error: %s", err.Error())
+			return "", fmt.Errorf("NewAddressPubKey error: %s", err.Error())
 		}
+		pubKeysList = append(pubKeysList, addressPubKey)
+	}
 
-		addrScriptHash, err = btcutil.NewAddressScriptHash(multisigScript, &netParams)
-		if err != nil {
+	sort.Sort(pubKeysList)
 
-			return "", fmt.Errorf("NewAddressScriptHash error: %s", err.Error())
-		}
-		address = addrScriptHash.String()
+	multisigScript, err := btscript.MultiSigScript(pubKeysList, int(params.SignersRequired))
+	if err != nil {
+		return "", fmt.Errorf("MultiSigScript error: %s", err.Error())
+	}
+
+	addrScriptHash, err := btcutil.NewAddressScriptHash(multisigScript, netParams)
+	if err != nil {
+		return "", fmt.Errorf("NewAddressScriptHash error: %s", err.Error())
 	}
-	return address, nil
+	return addrScriptHash.String(), nil
 }
@@ -3,6 +3,8 @@ package btc_example
 import (
 	"testing"
 
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+
 	"github.com/stanche/crypto-interface/address/hd"
 )
 
@@ -73,3 +75,39 @@ func TestGenerator_AddressGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerator_AddressGenerateRange(t *testing.T) {
+	params := hd.GeneratorParameters{
+		SignersXpubs:    []string{"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK", "xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ", "xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv"},
+		SignersRequired: 2,
+		PathIndex:       990,
+	}
+	g := New()
+	addresses, err := g.AddressGenerateRange(params, btcchaincfg.TestNet3Params, 20)
+	if err != nil {
+		t.Fatalf("Generator.AddressGenerateRange() error = %v", err)
+	}
+	if len(addresses) != 20 {
+		t.Fatalf("Generator.AddressGenerateRange() returned %d addresses, want 20", len(addresses))
+	}
+	if addresses[10] != "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT" {
+		t.Errorf("Generator.AddressGenerateRange()[10] = %v, want 2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT", addresses[10])
+	}
+	for i, address := range addresses {
+		single := params
+		single.PathIndex = params.PathIndex + uint32(i)
+		want, err := Generator{}.AddressGenerate(single)
+		if err != nil {
+			t.Fatalf("Generator.AddressGenerate() error = %v", err)
+		}
+		if address != want {
+			t.Errorf("Generator.AddressGenerateRange()[%d] = %v, want %v", i, address, want)
+		}
+	}
+
+	params.SignersXpubs = []string{"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufGBwALkBUK"}
+	params.SignersRequired = 1
+	if _, err = g.AddressGenerateRange(params, btcchaincfg.TestNet3Params, 5); err == nil {
+		t.Errorf("Generator.AddressGenerateRange() shall fail for invalid xpub")
+	}
+}
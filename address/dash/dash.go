@@ -0,0 +1,16 @@
+package dash
+
+import (
+	"github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// New creates a generator of Dash main network addresses.
+func New() btc_example.Generator {
+	return btc_example.NewForNet(&netparams.DashMainNetParams)
+}
+
+// NewTestnet creates a generator of Dash test network addresses.
+func NewTestnet() btc_example.Generator {
+	return btc_example.NewForNet(&netparams.DashTestNetParams)
+}
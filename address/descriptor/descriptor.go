@@ -0,0 +1,342 @@
+package descriptor
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	btscript "github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	btckeychain "github.com/btcsuite/btcutil/hdkeychain"
+
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/connector"
+)
+
+const (
+	// ScriptP2SH is a P2SH multisig wallet: sh(sortedmulti(...)).
+	ScriptP2SH = "sh"
+	// ScriptP2PKH is a single key wallet: pkh(...).
+	ScriptP2PKH = "pkh"
+
+	maxSigners = 15
+
+	inputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	checksumLength  = 8
+)
+
+type (
+	// Key is an extended public key of a descriptor: [origin]xpub/path/*.
+	Key struct {
+		// Origin is the fingerprint of the master key and the path from it to XPub.
+		// It is omitted from the descriptor if the fingerprint is zero.
+		Origin   hd.Path
+		XPub     string
+		Path     hd.Path
+		Wildcard bool
+	}
+
+	// Descriptor describes a wallet as an output script descriptor (BIP380).
+	// Only pkh(KEY) and sh(sortedmulti(k,KEY,...)) used by this library are supported.
+	Descriptor struct {
+		Script   string
+		Required int
+		Keys     []Key
+	}
+)
+
+var (
+	// ErrChecksum is returned when the descriptor checksum does not match.
+	ErrChecksum = fmt.Errorf("descriptor checksum mismatch")
+	// ErrUnsupportedPath is returned when the key derivation differs from xpub/0/* used by the wallets.
+	ErrUnsupportedPath = fmt.Errorf("only xpub/0/* derivation is supported by the wallet")
+)
+
+// FromWallet describes the wallet used by TxBuild and the address generator.
+// Origins of master xpubs (depth 0) are filled with their fingerprints.
+func FromWallet(wallet *connector.WalletSignStruct) (*Descriptor, error) {
+	if wallet == nil {
+		return nil, fmt.Errorf("wallet is nil")
+	}
+	n := len(wallet.XPubs)
+	m := int(wallet.Signers)
+	if n < 1 || n > maxSigners {
+		return nil, fmt.Errorf("invalid signers quantity")
+	}
+	if m < 1 || m > n {
+		return nil, fmt.Errorf("invalid signers required number")
+	}
+
+	d := &Descriptor{Script: ScriptP2SH, Required: m}
+	if n == 1 {
+		d.Script = ScriptP2PKH
+	}
+	for _, xpub := range wallet.XPubs {
+		xkey, err := btckeychain.NewKeyFromString(xpub)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpub %s: %s", xpub, err.Error())
+		}
+		if xkey.IsPrivate() {
+			return nil, fmt.Errorf("private key in the wallet")
+		}
+		key := Key{XPub: xpub, Path: hd.NewPath(0), Wildcard: true}
+		if xkey.Depth() == 0 {
+			key.Origin.Fingerprint, err = hd.KeyFingerprint(xkey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		d.Keys = append(d.Keys, key)
+	}
+	return d, nil
+}
+
+// Parse parses the descriptor. The checksum is verified if present.
+func Parse(s string) (*Descriptor, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '#'); i >= 0 {
+		sum, err := Checksum(s[:i])
+		if err != nil {
+			return nil, err
+		}
+		if sum != s[i+1:] {
+			return nil, ErrChecksum
+		}
+		s = s[:i]
+	}
+
+	d := &Descriptor{}
+	var body string
+	switch {
+	case strings.HasPrefix(s, "sh(sortedmulti(") && strings.HasSuffix(s, "))"):
+		d.Script = ScriptP2SH
+		body = s[len("sh(sortedmulti(") : len(s)-2]
+	case strings.HasPrefix(s, "pkh(") && strings.HasSuffix(s, ")"):
+		d.Script = ScriptP2PKH
+		body = "1," + s[len("pkh("):len(s)-1]
+	default:
+		return nil, fmt.Errorf("unsupported descriptor: %s", s)
+	}
+
+	items := strings.Split(body, ",")
+	required, err := strconv.Atoi(items[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q", items[0])
+	}
+	d.Required = required
+	for _, item := range items[1:] {
+		key, err := parseKey(item)
+		if err != nil {
+			return nil, err
+		}
+		d.Keys = append(d.Keys, key)
+	}
+	if len(d.Keys) < 1 || len(d.Keys) > maxSigners || d.Required < 1 || d.Required > len(d.Keys) {
+		return nil, fmt.Errorf("invalid threshold %d of %d keys", d.Required, len(d.Keys))
+	}
+	return d, nil
+}
+
+func parseKey(s string) (Key, error) {
+	var key Key
+	var err error
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return key, fmt.Errorf("invalid key origin: %s", s)
+		}
+		key.Origin, err = hd.ParsePath(s[:end+1])
+		if err != nil {
+			return key, err
+		}
+		s = s[end+1:]
+	}
+	items := strings.Split(s, "/")
+	key.XPub = items[0]
+	xkey, err := btckeychain.NewKeyFromString(key.XPub)
+	if err != nil {
+		return key, fmt.Errorf("invalid xpub %s: %s", key.XPub, err.Error())
+	}
+	if xkey.IsPrivate() {
+		return key, fmt.Errorf("private keys are not supported")
+	}
+	items = items[1:]
+	if n := len(items); n > 0 && items[n-1] == "*" {
+		key.Wildcard = true
+		items = items[:n-1]
+	}
+	if len(items) > 0 {
+		key.Path, err = hd.ParsePath("m/" + strings.Join(items, "/"))
+		if err != nil {
+			return key, err
+		}
+		if key.Path.IsHardened() {
+			return key, hd.ErrHardenedFromPublic
+		}
+	}
+	return key, nil
+}
+
+// String formats the descriptor with the checksum.
+func (d *Descriptor) String() string {
+	keys := make([]string, len(d.Keys))
+	for i := range d.Keys {
+		keys[i] = d.Keys[i].String()
+	}
+	var s string
+	if d.Script == ScriptP2PKH && len(keys) == 1 {
+		s = fmt.Sprintf("pkh(%s)", keys[0])
+	} else {
+		s = fmt.Sprintf("sh(sortedmulti(%d,%s))", d.Required, strings.Join(keys, ","))
+	}
+	sum, _ := Checksum(s)
+	return s + "#" + sum
+}
+
+// String formats the key as [origin]xpub/path/*.
+func (k Key) String() string {
+	var sb strings.Builder
+	if k.Origin.Fingerprint != 0 {
+		sb.WriteString("[" + k.Origin.Origin() + "]")
+	}
+	sb.WriteString(k.XPub)
+	sb.WriteString(strings.TrimPrefix(k.Path.String(), "m"))
+	if k.Wildcard {
+		sb.WriteString("/*")
+	}
+	return sb.String()
+}
+
+// WalletSignStruct returns the wallet for TxBuild. The keys shall use xpub/0/* derivation.
+func (d *Descriptor) WalletSignStruct() (*connector.WalletSignStruct, error) {
+	wallet := &connector.WalletSignStruct{
+		Signers: uint8(d.Required),
+		XPubs:   make([]string, len(d.Keys)),
+	}
+	for i, key := range d.Keys {
+		if !key.Wildcard || len(key.Path.Steps) != 1 || key.Path.Steps[0] != 0 {
+			return nil, ErrUnsupportedPath
+		}
+		wallet.XPubs[i] = key.XPub
+	}
+	if (d.Script == ScriptP2PKH) != (len(d.Keys) == 1) {
+		return nil, fmt.Errorf("unsupported script %s for %d keys", d.Script, len(d.Keys))
+	}
+	return wallet, nil
+}
+
+// Address derives the address of the descriptor at index like deriveaddresses of Bitcoin Core.
+// The index is ignored if the keys are not ranged.
+func (d *Descriptor) Address(index uint32, net *btcchaincfg.Params) (btcutil.Address, error) {
+	pubKeys := make([][]byte, len(d.Keys))
+	for i, key := range d.Keys {
+		xkey, err := btckeychain.NewKeyFromString(key.XPub)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpub %s: %s", key.XPub, err.Error())
+		}
+		path := key.Path
+		if key.Wildcard {
+			path = path.Child(index)
+		}
+		xkey, err = path.Derive(xkey)
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := xkey.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		pubKeys[i] = pubKey.SerializeCompressed()
+	}
+	if d.Script == ScriptP2PKH && len(pubKeys) == 1 {
+		return btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubKeys[0]), net)
+	}
+
+	sort.Slice(pubKeys, func(i, j int) bool { return bytes.Compare(pubKeys[i], pubKeys[j]) < 0 })
+	addressPubKeys := make([]*btcutil.AddressPubKey, len(pubKeys))
+	for i, pubKey := range pubKeys {
+		addressPubKey, err := btcutil.NewAddressPubKey(pubKey, net)
+		if err != nil {
+			return nil, fmt.Errorf("NewAddressPubKey error: %s", err.Error())
+		}
+		addressPubKeys[i] = addressPubKey
+	}
+	multisigScript, err := btscript.MultiSigScript(addressPubKeys, d.Required)
+	if err != nil {
+		return nil, fmt.Errorf("MultiSigScript error: %s", err.Error())
+	}
+	return btcutil.NewAddressScriptHash(multisigScript, net)
+}
+
+// GeneratorParameters returns the address generator parameters for the address index.
+func (d *Descriptor) GeneratorParameters(index uint32, regtest bool) (hd.GeneratorParameters, error) {
+	wallet, err := d.WalletSignStruct()
+	if err != nil {
+		return hd.GeneratorParameters{}, err
+	}
+	return hd.GeneratorParameters{
+		SignersXpubs:    wallet.XPubs,
+		SignersRequired: wallet.Signers,
+		PathIndex:       index,
+		Regtest:         regtest,
+	}, nil
+}
+
+// Checksum calculates BIP380 descriptor checksum.
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(inputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("invalid character %q in descriptor", ch)
+		}
+		// emit a symbol for the position inside the group, for every character
+		c = polyMod(c, pos&31)
+		// accumulate the group numbers
+		cls = cls*3 + pos>>5
+		clsCount++
+		if clsCount == 3 {
+			c = polyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polyMod(c, cls)
+	}
+	for i := 0; i < checksumLength; i++ {
+		c = polyMod(c, 0)
+	}
+	c ^= 1
+
+	sum := make([]byte, checksumLength)
+	for j := range sum {
+		sum[j] = checksumCharset[(c>>(5*(7-uint(j))))&31]
+	}
+	return string(sum), nil
+}
+
+func polyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
@@ -0,0 +1,105 @@
+package descriptor
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/connector"
+)
+
+func TestChecksum(t *testing.T) {
+	// BIP380 and bitcoin core importdescriptors examples
+	for desc, want := range map[string]string{
+		"raw(deadbeef)": "89f8spxm",
+		"addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)": "02wpgw69",
+	} {
+		sum, err := Checksum(desc)
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, want, sum, "unexpected checksum of %s", desc)
+	}
+
+	// example from bitcoin core doc/descriptors.md
+	desc := "pkh([d34db33f/44'/0'/0']xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL/1/*)"
+	sum, err := Checksum(desc)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "ml40v0wf", sum, "unexpected checksum")
+
+	d, err := Parse(desc + "#" + sum)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, desc+"#"+sum, d.String(), "unexpected descriptor")
+
+	_, err = Parse(desc + "#ml40v0wg")
+	assert.Equal(t, ErrChecksum, err, "unexpected error")
+
+	_, err = d.WalletSignStruct()
+	assert.Equal(t, ErrUnsupportedPath, err, "unexpected error")
+}
+
+func TestDescriptor_Wallet(t *testing.T) {
+	wallet := &connector.WalletSignStruct{
+		Signers: 2,
+		XPubs: []string{
+			"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+			"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+			"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+		},
+	}
+
+	d, err := FromWallet(wallet)
+	assert.Nil(t, err, "unexpected error")
+
+	parsed, err := Parse(d.String())
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, d, parsed, "unexpected descriptor")
+
+	got, err := parsed.WalletSignStruct()
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, wallet, got, "unexpected wallet")
+
+	params, err := parsed.GeneratorParameters(1000, false)
+	assert.Nil(t, err, "unexpected error")
+	address, err := btc_example.New().AddressGenerate(params)
+	assert.Nil(t, err, "unexpected error")
+	expected, err := parsed.Address(1000, &chaincfg.TestNet3Params)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, expected.String(), address, "unexpected address")
+}
+
+func TestDescriptor_Address(t *testing.T) {
+	tests := []struct {
+		name   string
+		desc   string
+		index  uint32
+		script string
+	}{
+		{
+			// BIP381
+			name:   "pkh",
+			desc:   "pkh([bd16bee5/2147483647']xpub69H7F5dQzmVd3vPuLKtcXJziMEQByuDidnX3YdwgtNsecY5HRGtAAQC5mXTt4dsv9RzyjgDjAQs9VGVV6ydYCHnprc9vvaA5YtqWyL6hyds/0)",
+			script: "76a914ebdc90806a9c4356c1c88e42216611e1cb4c1c1788ac",
+		},
+		{
+			// BIP383 with the xprvs of the vector replaced by their xpubs
+			name:   "sh sortedmulti",
+			desc:   "sh(sortedmulti(2,[00000000/111'/222]xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL,xpub68NZiKmJWnxxS6aaHmn81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjNLf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y/0))",
+			index:  5,
+			script: "a91445a9a622a8b0a1269944be477640eedc447bbd8487",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.desc)
+			assert.Nil(t, err, "unexpected error")
+			address, err := d.Address(tt.index, &chaincfg.MainNetParams)
+			assert.Nil(t, err, "unexpected error")
+			script, err := txscript.PayToAddrScript(address)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.script, hex.EncodeToString(script), "unexpected script")
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package doge
+
+import (
+	"github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// New creates a generator of Dogecoin main network addresses.
+func New() btc_example.Generator {
+	return btc_example.NewForNet(&netparams.DogeMainNetParams)
+}
+
+// NewTestnet creates a generator of Dogecoin test network addresses.
+func NewTestnet() btc_example.Generator {
+	return btc_example.NewForNet(&netparams.DogeTestNetParams)
+}
@@ -0,0 +1,104 @@
+// Package eth generates Ethereum addresses: the last 20 bytes of keccak256 of the public key,
+// hex encoded with EIP-55 checksum.
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/stanche/crypto-interface/abi"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/ethtx"
+	"github.com/stanche/crypto-interface/keccak"
+)
+
+// AddressSize is the size of the address in bytes.
+const AddressSize = abi.AddressSize
+
+// Generator creates the addresses of the single xpub on xpub/0/index.
+// Branch keys of the xpubs are cached, so the generator shall be reused for many addresses.
+type Generator struct {
+	cache *hd.BranchCache
+}
+
+// New creates a generator of Ethereum addresses.
+func New() Generator {
+	return Generator{cache: hd.NewBranchCache()}
+}
+
+// AddressGenerate returns the checksummed address of the key xpub/0/index.
+// Ethereum accounts have one key, so exactly one xpub is expected.
+func (g Generator) AddressGenerate(params hd.GeneratorParameters) (string, error) {
+	if len(params.SignersXpubs) != 1 || params.SignersXpubs[0] == "" {
+		return "", fmt.Errorf("invalid signers quantity: one xpub expected")
+	}
+	if params.SignersRequired != 1 {
+		return "", fmt.Errorf("Invalid signersRequired: %d", params.SignersRequired)
+	}
+	cache := g.cache
+	if cache == nil {
+		cache = hd.NewBranchCache()
+	}
+	extKey, err := cache.Child(params.SignersXpubs[0], 0, params.PathIndex)
+	if err != nil {
+		return "", fmt.Errorf("xPubByPath error: %s", err.Error())
+	}
+	pubKey, err := extKey.ECPubKey()
+	if err != nil {
+		return "", fmt.Errorf("ECPubKey error: %s", err.Error())
+	}
+	return ChecksumAddress(PubkeyToAddress(pubKey.ToECDSA())), nil
+}
+
+// Keccak256 returns the legacy Keccak-256 hash of the data used by Ethereum.
+func Keccak256(data ...[]byte) []byte {
+	return keccak.Sum256(data...)
+}
+
+// PubkeyToAddress returns the 20-byte address of the public key.
+func PubkeyToAddress(pub *ecdsa.PublicKey) []byte {
+	return ethtx.PubkeyToAddress(pub)
+}
+
+// ChecksumAddress encodes the address with EIP-55 mixed-case checksum and 0x prefix.
+func ChecksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	hash := Keccak256([]byte(lower))
+	encoded := []byte(lower)
+	for i, c := range encoded {
+		// the letter is uppercased if the corresponding nibble of the hash is 8 or more
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0x0f >= 8 {
+			encoded[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(encoded)
+}
+
+// ParseAddress decodes the hex address with or without 0x prefix. The mixed-case address shall have the valid
+// EIP-55 checksum, the all lower or upper case one is accepted without the checksum.
+func ParseAddress(address string) ([]byte, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	if len(s) != 2*AddressSize {
+		return nil, fmt.Errorf("invalid address %s: unexpected length", address)
+	}
+	addr, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %s", address, err.Error())
+	}
+	if s != strings.ToLower(s) && s != strings.ToUpper(s) && ChecksumAddress(addr)[2:] != s {
+		return nil, fmt.Errorf("invalid address %s: checksum mismatch", address)
+	}
+	return addr, nil
+}
+
+// ValidateAddress reports whether the address is the valid hex address, see ParseAddress.
+func ValidateAddress(address string) bool {
+	_, err := ParseAddress(address)
+	return err == nil
+}
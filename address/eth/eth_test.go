@@ -0,0 +1,70 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/address/hd"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// EIP-55 test vectors
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0xde709f2102306220921060314715629080e2fb77",
+	} {
+		addr, err := ParseAddress(strings.ToLower(want))
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, want, ChecksumAddress(addr), "unexpected checksum")
+		assert.True(t, ValidateAddress(want), "valid address %s", want)
+		assert.True(t, ValidateAddress(strings.ToUpper(want[2:])), "valid address %s", want)
+	}
+
+	for name, address := range map[string]string{
+		"checksum mismatch": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+		"short":             "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA",
+		"not hex":           "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg",
+		"empty":             "",
+	} {
+		assert.False(t, ValidateAddress(address), "invalid address expected for %s", name)
+	}
+}
+
+func TestPubkeyToAddress(t *testing.T) {
+	// the sender of EIP-155 example
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	assert.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", ChecksumAddress(PubkeyToAddress(key.PubKey().ToECDSA())),
+		"unexpected address")
+	assert.Equal(t, "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", hex.EncodeToString(Keccak256(
+		key.PubKey().SerializeUncompressed()[1:])[12:]), "unexpected hash")
+}
+
+func TestGenerator_AddressGenerate(t *testing.T) {
+	xpub := "xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK"
+	extKey, _ := hdkeychain.NewKeyFromString(xpub)
+	branch, _ := extKey.Child(0)
+	child, _ := branch.Child(1000)
+	pubKey, _ := child.ECPubKey()
+
+	address, err := New().AddressGenerate(hd.GeneratorParameters{SignersXpubs: []string{xpub}, SignersRequired: 1, PathIndex: 1000})
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, ChecksumAddress(PubkeyToAddress(pubKey.ToECDSA())), address, "unexpected address")
+	assert.Equal(t, "0x", address[:2], "unexpected address %s", address)
+
+	_, err = New().AddressGenerate(hd.GeneratorParameters{SignersXpubs: []string{xpub, xpub}, SignersRequired: 1})
+	assert.NotNil(t, err, "expected error for several xpubs")
+	_, err = New().AddressGenerate(hd.GeneratorParameters{SignersXpubs: []string{xpub}, SignersRequired: 2})
+	assert.NotNil(t, err, "expected error for signers required")
+	_, err = New().AddressGenerate(hd.GeneratorParameters{SignersXpubs: []string{"xpub"}, SignersRequired: 1})
+	assert.NotNil(t, err, "expected error for invalid xpub")
+}
@@ -0,0 +1,154 @@
+package eth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/stanche/crypto-interface/abi"
+	"github.com/stanche/crypto-interface/address/hd"
+)
+
+// Safe factory and setup selectors.
+var (
+	safeSetupSelector                = Keccak256([]byte("setup(address[],uint256,address,bytes,address,address,uint256,address)"))[:4]
+	safeCreateProxyWithNonceSelector = Keccak256([]byte("createProxyWithNonce(address,bytes,uint256)"))[:4]
+)
+
+// SafeParams are the Safe contracts deployed on the chain.
+type SafeParams struct {
+	ProxyFactory    []byte
+	Singleton       []byte
+	FallbackHandler []byte
+	// ProxyCreationCode is the result of proxyCreationCode() of the factory.
+	ProxyCreationCode []byte
+}
+
+// Safe130Params are the contracts of Safe v1.3.0 deployed on Ethereum mainnet and on the same addresses
+// on the other EVM chains: GnosisSafeProxyFactory, GnosisSafe and CompatibilityFallbackHandler.
+var Safe130Params = SafeParams{
+	ProxyFactory:    mustDecodeHex("a6b71e26c5e0845f74c812102ca7114b6a896ab2"),
+	Singleton:       mustDecodeHex("d9db270c1b5e3bd161e8c8503c55ceabee709552"),
+	FallbackHandler: mustDecodeHex("f48f2b2d2a534e402487b3ee7c18c33aec0fe5e4"),
+	ProxyCreationCode: mustDecodeHex(
+		"608060405234801561001057600080fd5b506040516101e63803806101e68339818101604052602081101561003357600080" +
+			"fd5b8101908080519060200190929190505050600073ffffffffffffffffffffffffffffffffffffffff168173ffffffffff" +
+			"ffffffffffffffffffffffffffffff1614156100ca576040517f08c379a00000000000000000000000000000000000000000" +
+			"000000000000000081526004018080602001828103825260228152602001806101c460229139604001915050604051809103" +
+			"90fd5b806000806101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffff" +
+			"ffffffffffffffffffffff1602179055505060ab806101196000396000f3fe608060405273ffffffffffffffffffffffffff" +
+			"ffffffffffffff600054167fa619486e00000000000000000000000000000000000000000000000000000000600035141560" +
+			"50578060005260206000f35b3660008037600080366000845af43d6000803e60008114156070573d6000fd5b3d6000f3fea2" +
+			"646970667358221220d1429297349653a4918076d650332de1a1068c5f3e07c5c82360c277770b955264736f6c6343000706" +
+			"0033496e76616c69642073696e676c65746f6e20616464726573732070726f7669646564"),
+}
+
+// SafeGenerator creates the counterfactual addresses of Safe wallets. The owners are the keys xpub/0/index
+// of the signers, the threshold is the signers required and the salt nonce is the index,
+// so every address is a separate Safe. The address receives the funds before the deployment,
+// the Safe shall be deployed with SafeCreateProxyCalldata before the first spending.
+type SafeGenerator struct {
+	params SafeParams
+	cache  *hd.BranchCache
+}
+
+// NewSafe creates a generator of Safe addresses.
+func NewSafe(params SafeParams) SafeGenerator {
+	return SafeGenerator{params: params, cache: hd.NewBranchCache()}
+}
+
+// AddressGenerate returns the checksummed address of the Safe.
+func (g SafeGenerator) AddressGenerate(params hd.GeneratorParameters) (string, error) {
+	owners, err := g.Owners(params)
+	if err != nil {
+		return "", err
+	}
+	return ChecksumAddress(SafeAddress(g.params, owners, params.SignersRequired, uint64(params.PathIndex))), nil
+}
+
+// Owners returns the owner addresses of the Safe in the order of the xpubs.
+func (g SafeGenerator) Owners(params hd.GeneratorParameters) ([][]byte, error) {
+	if len(params.SignersXpubs) == 0 {
+		return nil, fmt.Errorf("invalid signers quantity: %d", len(params.SignersXpubs))
+	}
+	if params.SignersRequired == 0 || int(params.SignersRequired) > len(params.SignersXpubs) {
+		return nil, fmt.Errorf("Invalid signersRequired: %d", params.SignersRequired)
+	}
+	cache := g.cache
+	if cache == nil {
+		cache = hd.NewBranchCache()
+	}
+	owners := make([][]byte, len(params.SignersXpubs))
+	for i, xpub := range params.SignersXpubs {
+		extKey, err := cache.Child(xpub, 0, params.PathIndex)
+		if err != nil {
+			return nil, fmt.Errorf("xPubByPath error: %s", err.Error())
+		}
+		pubKey, err := extKey.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("ECPubKey error: %s", err.Error())
+		}
+		owners[i] = PubkeyToAddress(pubKey.ToECDSA())
+	}
+	return owners, nil
+}
+
+// Create2Address returns the address of the contract created with CREATE2 as of EIP-1014.
+func Create2Address(deployer, salt, initCodeHash []byte) []byte {
+	return Keccak256([]byte{0xff}, deployer, salt, initCodeHash)[abi.WordSize-AddressSize:]
+}
+
+// SafeAddress returns the address of the Safe proxy created with createProxyWithNonce of the factory.
+func SafeAddress(params SafeParams, owners [][]byte, threshold uint8, saltNonce uint64) []byte {
+	initializer := SafeSetupCalldata(owners, threshold, params.FallbackHandler)
+	salt := Keccak256(Keccak256(initializer), abi.UintWord(new(big.Int).SetUint64(saltNonce)))
+	initCodeHash := Keccak256(params.ProxyCreationCode, abi.AddressWord(params.Singleton))
+	return Create2Address(params.ProxyFactory, salt, initCodeHash)
+}
+
+// SafeSetupCalldata returns the initializer of the Safe: setup of the owners and the threshold
+// without the module call and the payment.
+func SafeSetupCalldata(owners [][]byte, threshold uint8, fallbackHandler []byte) []byte {
+	const headSize = 8 * abi.WordSize
+	ownersSize := (1 + len(owners)) * abi.WordSize
+	data := append([]byte{}, safeSetupSelector...)
+	data = append(data, abi.UintWord(big.NewInt(headSize))...)
+	data = append(data, abi.UintWord(big.NewInt(int64(threshold)))...)
+	data = append(data, abi.AddressWord(nil)...)
+	data = append(data, abi.UintWord(big.NewInt(int64(headSize+ownersSize)))...)
+	data = append(data, abi.AddressWord(fallbackHandler)...)
+	data = append(data, abi.AddressWord(nil)...)
+	data = append(data, abi.UintWord(new(big.Int))...)
+	data = append(data, abi.AddressWord(nil)...)
+	data = append(data, abi.UintWord(big.NewInt(int64(len(owners))))...)
+	for _, owner := range owners {
+		data = append(data, abi.AddressWord(owner)...)
+	}
+	// empty data of the module call
+	return append(data, abi.UintWord(new(big.Int))...)
+}
+
+// SafeCreateProxyCalldata returns the call of the factory deploying the Safe of SafeAddress.
+func SafeCreateProxyCalldata(params SafeParams, owners [][]byte, threshold uint8, saltNonce uint64) []byte {
+	initializer := SafeSetupCalldata(owners, threshold, params.FallbackHandler)
+	data := append([]byte{}, safeCreateProxyWithNonceSelector...)
+	data = append(data, abi.AddressWord(params.Singleton)...)
+	data = append(data, abi.UintWord(big.NewInt(3*abi.WordSize))...)
+	data = append(data, abi.UintWord(new(big.Int).SetUint64(saltNonce))...)
+	data = append(data, abi.UintWord(big.NewInt(int64(len(initializer))))...)
+	data = append(data, initializer...)
+	// the bytes are padded to the word
+	if pad := len(initializer) % abi.WordSize; pad != 0 {
+		data = append(data, make([]byte, abi.WordSize-pad)...)
+	}
+	return data
+}
+
+// mustDecodeHex decodes the hex constant.
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,102 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/abi"
+	"github.com/stanche/crypto-interface/address/hd"
+)
+
+func TestCreate2Address(t *testing.T) {
+	// EIP-1014 examples
+	for _, tc := range []struct {
+		deployer, salt, initCode, want string
+	}{
+		{"0000000000000000000000000000000000000000", "0000000000000000000000000000000000000000000000000000000000000000",
+			"00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"deadbeef00000000000000000000000000000000", "0000000000000000000000000000000000000000000000000000000000000000",
+			"00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"00000000000000000000000000000000deadbeef", "00000000000000000000000000000000000000000000000000000000cafebabe",
+			"deadbeef", "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+	} {
+		deployer, _ := hex.DecodeString(tc.deployer)
+		salt, _ := hex.DecodeString(tc.salt)
+		initCode, _ := hex.DecodeString(tc.initCode)
+		assert.Equal(t, tc.want, ChecksumAddress(Create2Address(deployer, salt, Keccak256(initCode))), "unexpected address")
+	}
+}
+
+func TestSafeAddress(t *testing.T) {
+	for name, tc := range map[string]struct {
+		address string
+		want    []byte
+	}{
+		"factory":          {"0xa6B71E26C5e0845f74c812102Ca7114b6a896AB2", Safe130Params.ProxyFactory},
+		"singleton":        {"0xd9Db270c1B5E3Bd161E8c8503c55cEABeE709552", Safe130Params.Singleton},
+		"fallback handler": {"0xf48f2B2d2a534e402487b3ee7C18c33Aec0Fe5e4", Safe130Params.FallbackHandler},
+	} {
+		address, err := ParseAddress(tc.address)
+		assert.Nil(t, err, "unexpected error of the %s", name)
+		assert.Equal(t, tc.want, address, "unexpected %s", name)
+	}
+	assert.Equal(t, "56e3081a3d1bb38ed4eed1a39f7729c3cc77c7825794c15bbf326f3047fd779c",
+		hex.EncodeToString(Keccak256(Safe130Params.ProxyCreationCode, abi.AddressWord(Safe130Params.Singleton))),
+		"unexpected init code hash of the proxy")
+
+	owners := [][]byte{
+		bytes.Repeat([]byte{0x11}, AddressSize),
+		bytes.Repeat([]byte{0x22}, AddressSize),
+		bytes.Repeat([]byte{0x33}, AddressSize),
+	}
+	assert.Equal(t, "0x64E7dD83E74B6aB3F6a5cEb65C83e63708298559", ChecksumAddress(SafeAddress(Safe130Params, owners, 2, 42)),
+		"unexpected Safe address")
+}
+
+func TestSafeGenerator_AddressGenerate(t *testing.T) {
+	assert.Equal(t, "b63e800d", hex.EncodeToString(safeSetupSelector), "unexpected setup selector")
+	assert.Equal(t, "1688f0b9", hex.EncodeToString(safeCreateProxyWithNonceSelector), "unexpected createProxyWithNonce selector")
+
+	var xpubs []string
+	for i := byte(1); i <= 3; i++ {
+		master, _ := hdkeychain.NewMaster(bytes.Repeat([]byte{i}, 32), &chaincfg.MainNetParams)
+		xpub, _ := master.Neuter()
+		xpubs = append(xpubs, xpub.String())
+	}
+	params := Safe130Params
+	g := NewSafe(params)
+	gp := hd.GeneratorParameters{SignersXpubs: xpubs, SignersRequired: 2, PathIndex: 7}
+
+	owners, err := g.Owners(gp)
+	assert.Nil(t, err, "unexpected error")
+	assert.Len(t, owners, 3, "unexpected owners")
+	single, _ := New().AddressGenerate(hd.GeneratorParameters{SignersXpubs: xpubs[1:2], SignersRequired: 1, PathIndex: 7})
+	assert.Equal(t, single, ChecksumAddress(owners[1]), "unexpected owner")
+
+	address, err := g.AddressGenerate(gp)
+	assert.Nil(t, err, "unexpected error")
+	setup := SafeSetupCalldata(owners, 2, params.FallbackHandler)
+	assert.Equal(t, 4+(8+1+3+1)*abi.WordSize, len(setup), "unexpected setup size")
+	salt := Keccak256(Keccak256(setup), abi.AddressWord([]byte{7}))
+	initCode := append(append([]byte{}, params.ProxyCreationCode...), abi.AddressWord(params.Singleton)...)
+	assert.Equal(t, ChecksumAddress(Create2Address(params.ProxyFactory, salt, Keccak256(initCode))), address, "unexpected address")
+
+	create := SafeCreateProxyCalldata(params, owners, 2, 7)
+	assert.Equal(t, safeCreateProxyWithNonceSelector, create[:4], "unexpected selector")
+	assert.Equal(t, setup, create[4+4*abi.WordSize:4+4*abi.WordSize+len(setup)], "unexpected initializer")
+	assert.Equal(t, 0, (len(create)-4)%abi.WordSize, "unexpected padding")
+
+	gp.PathIndex = 8
+	other, _ := g.AddressGenerate(gp)
+	assert.NotEqual(t, address, other, "unexpected address of the other index")
+
+	_, err = g.AddressGenerate(hd.GeneratorParameters{SignersXpubs: xpubs, SignersRequired: 4})
+	assert.NotNil(t, err, "expected error for signers required")
+	_, err = g.AddressGenerate(hd.GeneratorParameters{SignersRequired: 1})
+	assert.NotNil(t, err, "expected error for xpubs")
+}
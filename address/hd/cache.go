@@ -0,0 +1,68 @@
+package hd
+
+import (
+	"sync"
+
+	btckeychain "github.com/btcsuite/btcutil/hdkeychain"
+)
+
+type (
+	// BranchCache keeps the decoded extended keys of branches (xpub/branch) to avoid
+	// base58 decoding and the branch derivation for every address. It is safe for concurrent use.
+	// A nil *BranchCache derives the keys without caching.
+	BranchCache struct {
+		mu   sync.RWMutex
+		keys map[branchID]*btckeychain.ExtendedKey
+	}
+
+	branchID struct {
+		xpub   string
+		branch uint32
+	}
+)
+
+// NewBranchCache creates an empty cache.
+func NewBranchCache() *BranchCache {
+	return &BranchCache{keys: make(map[branchID]*btckeychain.ExtendedKey)}
+}
+
+// Branch returns the extended key xpub/branch.
+func (c *BranchCache) Branch(xpub string, branch uint32) (*btckeychain.ExtendedKey, error) {
+	id := branchID{xpub: xpub, branch: branch}
+	if c != nil {
+		c.mu.RLock()
+		xkey, ok := c.keys[id]
+		c.mu.RUnlock()
+		if ok {
+			return xkey, nil
+		}
+	}
+
+	xkey, err := btckeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return nil, err
+	}
+	if branch >= HardenedKeyStart && !xkey.IsPrivate() {
+		return nil, ErrHardenedFromPublic
+	}
+	xkey, err = xkey.Child(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		c.mu.Lock()
+		c.keys[id] = xkey
+		c.mu.Unlock()
+	}
+	return xkey, nil
+}
+
+// Child returns the extended key xpub/branch/index.
+func (c *BranchCache) Child(xpub string, branch, index uint32) (*btckeychain.ExtendedKey, error) {
+	xkey, err := c.Branch(xpub, branch)
+	if err != nil {
+		return nil, err
+	}
+	return xkey.Child(index)
+}
@@ -10,6 +10,15 @@ import (
 	"github.com/xbis/godash/btcec"
 )
 
+// XPubByPathString derives the extended key on the path given as a string like m/0/5.
+func XPubByPathString(xKeyMaster string, hdPath string) (xPub string, err error) {
+	path, err := ParsePath(hdPath)
+	if err != nil {
+		return "", err
+	}
+	return XPubByPath(xKeyMaster, path.Steps)
+}
+
 func XPubByPath(xKeyMaster string, hdPath []uint32) (xPub string, err error) {
 
 	xPub = xKeyMaster
@@ -32,6 +41,10 @@ func childXpub(xPubKey string, index uint32) (xPub string, err error) {
 
 	}
 
+	if index >= HardenedKeyStart && !extKey.IsPrivate() {
+		return "", ErrHardenedFromPublic
+	}
+
 	childKey, err := extKey.Child(index)
 	if err != nil {
 		return "", fmt.Errorf("Child generation error: %s", err.Error())
@@ -0,0 +1,195 @@
+package hd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil"
+	btckeychain "github.com/btcsuite/btcutil/hdkeychain"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key.
+	HardenedKeyStart = btckeychain.HardenedKeyStart
+
+	// Purposes of the BIP43 account layouts.
+	PurposeBIP44 = 44 // single key P2PKH
+	PurposeBIP45 = 45 // multisig P2SH with cosigner index
+	PurposeBIP48 = 48 // multisig with script type
+	PurposeBIP84 = 84 // single key P2WPKH
+
+	// Script types of BIP48.
+	ScriptTypeP2SHP2WSH = 1
+	ScriptTypeP2WSH     = 2
+)
+
+// ErrHardenedFromPublic is returned when a hardened step is derived from a public key.
+var ErrHardenedFromPublic = fmt.Errorf("hardened derivation requires a private key")
+
+// Path is a BIP32 derivation path. Fingerprint is the fingerprint of the key the path
+// starts from, it is zero if unknown.
+type Path struct {
+	Fingerprint uint32
+	Steps       []uint32
+}
+
+// Hardened returns the hardened index i'.
+func Hardened(i uint32) uint32 {
+	return i + HardenedKeyStart
+}
+
+// NewPath creates a path from the steps.
+func NewPath(steps ...uint32) Path {
+	return Path{Steps: steps}
+}
+
+// AccountPath returns the account path of the BIP43 layout: m/purpose'/coinType'/account'.
+// For BIP48 the script type is added as m/48'/coinType'/account'/scriptType'.
+func AccountPath(purpose, coinType, account uint32, scriptType ...uint32) Path {
+	steps := []uint32{Hardened(purpose), Hardened(coinType), Hardened(account)}
+	for _, st := range scriptType {
+		steps = append(steps, Hardened(st))
+	}
+	return Path{Steps: steps}
+}
+
+// ParsePath parses a path like m/48'/0'/0'/2'/0/5. Hardened steps are marked with ' or h.
+// The key origin form [d34db33f/48'/0'/0'/2'] is accepted as well and sets the fingerprint.
+func ParsePath(s string) (Path, error) {
+	var p Path
+	s = strings.TrimSpace(s)
+	origin := strings.HasPrefix(s, "[")
+	if origin {
+		if !strings.HasSuffix(s, "]") {
+			return p, fmt.Errorf("invalid key origin %q", s)
+		}
+		s = s[1 : len(s)-1]
+	}
+	items := strings.Split(s, "/")
+	switch {
+	case origin:
+		fp, err := hex.DecodeString(items[0])
+		if err != nil || len(fp) != 4 {
+			return p, fmt.Errorf("invalid fingerprint %q", items[0])
+		}
+		p.Fingerprint = binary.BigEndian.Uint32(fp)
+		items = items[1:]
+	case items[0] == "m" || items[0] == "M":
+		items = items[1:]
+	}
+	for _, item := range items {
+		step, err := parseStep(item)
+		if err != nil {
+			return Path{}, fmt.Errorf("invalid path %q: %s", s, err.Error())
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	return p, nil
+}
+
+func parseStep(item string) (uint32, error) {
+	var hardened bool
+	if n := len(item); n > 0 && (item[n-1] == '\'' || item[n-1] == 'h' || item[n-1] == 'H') {
+		hardened = true
+		item = item[:n-1]
+	}
+	if item == "" || item[0] == '+' || item[0] == '-' {
+		return 0, fmt.Errorf("invalid step %q", item)
+	}
+	i, err := strconv.ParseUint(item, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if i >= HardenedKeyStart {
+		return 0, fmt.Errorf("step %d is out of range", i)
+	}
+	if hardened {
+		return Hardened(uint32(i)), nil
+	}
+	return uint32(i), nil
+}
+
+// String formats the path as m/48'/0'/0'/2'/0/5.
+func (p Path) String() string {
+	return "m" + p.stepsString()
+}
+
+// Origin formats the path as a key origin d34db33f/48'/0'/0'/2' without the brackets.
+func (p Path) Origin() string {
+	return fmt.Sprintf("%08x", p.Fingerprint) + p.stepsString()
+}
+
+func (p Path) stepsString() string {
+	var sb strings.Builder
+	for _, step := range p.Steps {
+		sb.WriteByte('/')
+		if step >= HardenedKeyStart {
+			sb.WriteString(strconv.FormatUint(uint64(step-HardenedKeyStart), 10))
+			sb.WriteByte('\'')
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(step), 10))
+		}
+	}
+	return sb.String()
+}
+
+// IsHardened reports whether the path contains a hardened step.
+func (p Path) IsHardened() bool {
+	for _, step := range p.Steps {
+		if step >= HardenedKeyStart {
+			return true
+		}
+	}
+	return false
+}
+
+// Child returns a new path extended with the steps.
+func (p Path) Child(steps ...uint32) Path {
+	child := make([]uint32, 0, len(p.Steps)+len(steps))
+	child = append(child, p.Steps...)
+	return Path{Fingerprint: p.Fingerprint, Steps: append(child, steps...)}
+}
+
+// Derive returns the child of xkey on the path. Hardened steps require a private xkey.
+func (p Path) Derive(xkey *btckeychain.ExtendedKey) (*btckeychain.ExtendedKey, error) {
+	if !xkey.IsPrivate() && p.IsHardened() {
+		return nil, ErrHardenedFromPublic
+	}
+	var err error
+	for _, step := range p.Steps {
+		xkey, err = Child(xkey, step)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return xkey, nil
+}
+
+// Child returns the child i of xkey. btcutil does not pad the private keys shorter than 32 bytes
+// for the hardened derivation, so such keys are serialized with the padding and parsed back.
+func Child(xkey *btckeychain.ExtendedKey, i uint32) (*btckeychain.ExtendedKey, error) {
+	if i < HardenedKeyStart || !xkey.IsPrivate() {
+		return xkey.Child(i)
+	}
+	if priv, err := xkey.ECPrivKey(); err != nil || len(priv.D.Bytes()) == 32 {
+		return xkey.Child(i)
+	}
+	padded, err := btckeychain.NewKeyFromString(xkey.String())
+	if err != nil {
+		return nil, err
+	}
+	defer padded.Zero()
+	return padded.Child(i)
+}
+
+// KeyFingerprint returns the BIP32 fingerprint of the key: the first 4 bytes of HASH160 of the public key.
+func KeyFingerprint(xkey *btckeychain.ExtendedKey) (uint32, error) {
+	pub, err := xkey.ECPubKey()
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(btcutil.Hash160(pub.SerializeCompressed())[:4]), nil
+}
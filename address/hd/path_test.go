@@ -0,0 +1,151 @@
+package hd
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	btckeychain "github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    Path
+		wantStr string
+		wantErr bool
+	}{
+		{
+			name:    "root",
+			path:    "m",
+			want:    Path{},
+			wantStr: "m",
+		},
+		{
+			name:    "bip48",
+			path:    "m/48'/0'/0'/2'/0/5",
+			want:    NewPath(Hardened(48), Hardened(0), Hardened(0), Hardened(2), 0, 5),
+			wantStr: "m/48'/0'/0'/2'/0/5",
+		},
+		{
+			name:    "h marker",
+			path:    "m/84h/1H/0h",
+			want:    AccountPath(PurposeBIP84, 1, 0),
+			wantStr: "m/84'/1'/0'",
+		},
+		{
+			name:    "key origin",
+			path:    "[d34db33f/48'/0'/0'/2']",
+			want:    Path{Fingerprint: 0xd34db33f, Steps: AccountPath(PurposeBIP48, 0, 0, ScriptTypeP2WSH).Steps},
+			wantStr: "m/48'/0'/0'/2'",
+		},
+		{
+			name:    "fingerprint without brackets",
+			path:    "d34db33f/48'/0'/0'/2'",
+			wantErr: true,
+		},
+		{
+			name:    "numeric step of 8 digits",
+			path:    "12345678/0",
+			want:    NewPath(12345678, 0),
+			wantStr: "m/12345678/0",
+		},
+		{
+			name:    "invalid fingerprint",
+			path:    "[d34db3/0']",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			path:    "m/2147483648",
+			wantErr: true,
+		},
+		{
+			name:    "negative",
+			path:    "m/-1",
+			wantErr: true,
+		},
+		{
+			name:    "empty step",
+			path:    "m/0//1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want.Fingerprint, got.Fingerprint, "unexpected fingerprint")
+			assert.Equal(t, len(tt.want.Steps), len(got.Steps), "unexpected steps")
+			for i := range tt.want.Steps {
+				assert.Equal(t, tt.want.Steps[i], got.Steps[i], "unexpected step %d", i)
+			}
+			assert.Equal(t, tt.wantStr, got.String(), "unexpected string")
+		})
+	}
+}
+
+func TestPath_Derive(t *testing.T) {
+	// BIP32 test vector 1
+	master, _ := btckeychain.NewKeyFromString("xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi")
+
+	fp, err := KeyFingerprint(master)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, uint32(0x3442193e), fp, "unexpected fingerprint")
+
+	path, _ := ParsePath("m/0'/1")
+	child, err := path.Derive(master)
+	assert.Nil(t, err, "unexpected error")
+	xpub, _ := child.Neuter()
+	assert.Equal(t, "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", xpub.String(), "unexpected xpub")
+
+	masterPub, _ := master.Neuter()
+	_, err = path.Derive(masterPub)
+	assert.Equal(t, ErrHardenedFromPublic, err, "unexpected error")
+
+	_, err = XPubByPathString(masterPub.String(), "m/0'/1")
+	assert.NotNil(t, err, "expected error")
+}
+
+func TestPath_DeriveLeadingZeros(t *testing.T) {
+	tests := []struct {
+		name string
+		seed string
+		path string
+		want string
+	}{
+		{
+			// BIP32 test vector 3: the master key has leading zeros
+			name: "test vector 3 chain m/0H",
+			seed: "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+			path: "m/0'",
+			want: "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L",
+		},
+		{
+			// BIP32 test vector 4: the key m/0H has leading zeros
+			name: "test vector 4 chain m/0H/1H",
+			seed: "3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+			path: "m/0'/1'",
+			want: "xprv9xJocDuwtYCMNAo3Zw76WENQeAS6WGXQ55RCy7tDJ8oALr4FWkuVoHJeHVAcAqiZLE7Je3vZJHxspZdFHfnBEjHqU5hG1Jaj32dVoS6XLT1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, _ := hex.DecodeString(tt.seed)
+			master, err := btckeychain.NewMaster(seed, &chaincfg.MainNetParams)
+			assert.Nil(t, err, "unexpected error")
+			path, _ := ParsePath(tt.path)
+			child, err := path.Derive(master)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.want, child.String(), "unexpected xprv")
+		})
+	}
+}
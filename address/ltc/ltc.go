@@ -0,0 +1,16 @@
+package ltc
+
+import (
+	"github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// New creates a generator of Litecoin main network addresses.
+func New() btc_example.Generator {
+	return btc_example.NewForNet(&netparams.LtcMainNetParams)
+}
+
+// NewTestnet creates a generator of Litecoin test network addresses.
+func NewTestnet() btc_example.Generator {
+	return btc_example.NewForNet(&netparams.LtcTestNet4Params)
+}
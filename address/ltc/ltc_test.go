@@ -0,0 +1,40 @@
+package ltc
+
+import (
+	"testing"
+
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+func TestGenerator_AddressGenerate(t *testing.T) {
+	params := hd.GeneratorParameters{
+		SignersXpubs: []string{
+			"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+			"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+			"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+		},
+		SignersRequired: 2,
+		PathIndex:       1000,
+	}
+	btcAddress, err := btc_example.New().AddressGenerateForNet(params, btcchaincfg.MainNetParams)
+	assert.Nil(t, err, "unexpected error")
+	btcHash, _, _ := base58.CheckDecode(btcAddress)
+
+	address, err := New().AddressGenerate(params)
+	assert.Nil(t, err, "unexpected error")
+	hash, version, err := base58.CheckDecode(address)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "M", address[:1], "unexpected address %s", address)
+	assert.Equal(t, netparams.LtcMainNetParams.ScriptHashAddrID, version, "unexpected version")
+	assert.Equal(t, btcHash, hash, "the script hash shall be the same as BTC one")
+
+	address, err = NewTestnet().AddressGenerate(params)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "Q", address[:1], "unexpected address %s", address)
+}
@@ -0,0 +1,16 @@
+package xec
+
+import (
+	"github.com/stanche/crypto-interface/address/bch"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// New creates a generator of eCash main network addresses (ecash: prefix).
+func New() bch.Generator {
+	return bch.NewForFork(netparams.XecMainNetFork)
+}
+
+// NewTestnet creates a generator of eCash test network addresses (ectest: prefix).
+func NewTestnet() bch.Generator {
+	return bch.NewForFork(netparams.XecTestNetFork)
+}
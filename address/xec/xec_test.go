@@ -0,0 +1,36 @@
+package xec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/address/bch"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+func TestGenerator_AddressGenerate(t *testing.T) {
+	params := hd.GeneratorParameters{
+		SignersXpubs: []string{
+			"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+			"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+			"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+		},
+		SignersRequired: 2,
+		PathIndex:       1000,
+	}
+	// the script hash of bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg
+	_, bchHash, _ := bch.DecodeCashAddress("bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", bch.PrefixRegTest)
+
+	address, err := NewTestnet().AddressGenerate(params)
+	assert.Nil(t, err, "unexpected error")
+	addrType, hash, err := bch.DecodeCashAddress(address, netparams.XecTestNetFork.CashAddrPrefix)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, bch.AddrTypeP2SH, addrType, "unexpected address type")
+	assert.Equal(t, bchHash, hash, "the script hash shall be the same as BCH one")
+
+	address, err = New().AddressGenerate(params)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "ecash:p", address[:7], "unexpected address %s", address)
+}
@@ -0,0 +1,77 @@
+package bch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+)
+
+type (
+	testCurrency struct{}
+
+	testNode struct {
+		host string
+		port int
+	}
+)
+
+func (testCurrency) GetCode() string         { return "BCH" }
+func (testCurrency) GetPrecision() uint8     { return 8 }
+func (testCurrency) GetTokenAddress() string { return "" }
+func (testCurrency) GetTokenCode() int64     { return 0 }
+
+func (n testNode) GetHost() string     { return n.host }
+func (n testNode) GetPort() int        { return n.port }
+func (n testNode) GetUser() string     { return "user" }
+func (n testNode) GetPassword() string { return "pass" }
+
+func TestBchChainConnector_BalanceGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "getaddressbalance":
+			fmt.Fprint(w, `{"result":{"balance":120000000,"received":120000000},"error":null}`)
+		case "getaddressmempool":
+			fmt.Fprint(w, `{"result":[{"satoshis":-20000000}],"error":null}`)
+		case "getaddressutxos":
+			fmt.Fprint(w, `{"result":[{"address":"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX","txid":"aa","outputIndex":1,"satoshis":120000000,"height":10}],"error":null}`)
+		default:
+			fmt.Fprint(w, `{"result":null,"error":{"code":-32601,"message":"unexpected method"}}`)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	node := testNode{host: serverURL.Hostname(), port: port}
+
+	ibtc, err := btc_example.NewChainConnector(1, &connector.WalletParams{Active: true, Node: node, Core: node}, 1, &chaincfg.TestNet3Params)
+	assert.Nil(t, err, "unexpected error")
+	bch := &bchChainConnector{ibtc: ibtc}
+
+	balance, err := bch.BalanceGet(testCurrency{}, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", "invalid")
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "1.2", balance.Confirmed.String(), "unexpected confirmed")
+	assert.Equal(t, "-0.2", balance.Unconfirmed.String(), "unexpected unconfirmed")
+	assert.Equal(t, "0", balance.Unmatured.String(), "unexpected unmatured")
+
+	utxos, err := bch.Utxos(testCurrency{}, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX")
+	assert.Nil(t, err, "unexpected error")
+	assert.Len(t, utxos, 1, "unexpected utxos")
+	assert.Equal(t, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", utxos[0].Address, "unexpected address")
+
+	_, err = bch.BalanceGet(testCurrency{})
+	assert.NotNil(t, err, "error expected for empty addresses")
+}
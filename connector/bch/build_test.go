@@ -0,0 +1,108 @@
+package bch
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+	signers "github.com/stanche/crypto-interface/signer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+)
+
+// testWallet is the 2-of-3 wallet of signer-a, signer-b and signer-c.
+var testWallet = &connector.WalletSignStruct{
+	Signers: 2,
+	XPubs: []string{
+		"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+		"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+		"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+	},
+}
+
+func TestTxBuildBch(t *testing.T) {
+	amount, _ := decimal.NewFromString("1.0")
+	change, _ := decimal.NewFromString("9.099")
+
+	// the same transaction as built by the node in Test_nodeConnector_TxBuild
+	want := "0200000001d71f0514b1f210d374a7d5c1ea4b24bb199eb0bf1990dc9d8ec5252359b8eff600000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000e80300004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000e80300004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a00000000e803000053aeffffffff02e0f83b360000000017a914af70bbab80fb64dbf90b212f4971cc4807d0b8808700e1f505000000001976a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac00000000"
+
+	got, err := TxBuildBch(testWallet,
+		[]connector.UtxStruct{{TxHash: "f6efb8592325c58e9ddc9019bfb09e19bb244beac1d5a774d310f2b114051fd7", TxPos: 0, Index: 1000}},
+		[]connector.OutStruct{
+			{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Amount: amount},
+			{Address: "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", Amount: change},
+		})
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, want, got, "unexpected tx")
+}
+
+func TestTxBuildBch_Tokens(t *testing.T) {
+	const category = "7d2ee2a55f2ea35e0ae8e1c5e37c1bc0a5a0cbcc9a4b1b0df1e8a7a84d9db4f4"
+	// the secrets of signer-a and signer-b of testWallet
+	componentA, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	componentB, _ := hex.DecodeString("b918edc07dd94ad9b8f705cddc6d133bfbe3aa9bdaca4c1fb99c755ff222d461")
+	const amount = 1010000000
+
+	utxos := []connector.UtxStruct{{TxHash: "f6efb8592325c58e9ddc9019bfb09e19bb244beac1d5a774d310f2b114051fd7", TxPos: 0, Index: 1000,
+		Value: decimal.New(amount, -8), Token: &connector.TokenParsed{Category: category, Amount: big.NewInt(1000000)}}}
+	output := []connector.OutStruct{
+		{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Amount: decimal.New(1, 0)},
+		{Address: "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", Amount: decimal.New(9099, -3)},
+		{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Amount: decimal.New(2500, 0), Currency: tokenCurrency{category}},
+	}
+	txHex, err := TxBuildBch(testWallet, utxos, output)
+	if !assert.Nil(t, err, "unexpected error") {
+		return
+	}
+
+	// the coin outputs with the token ones and the token change exceed the input by 1 satoshi
+	output[1].Amount = decimal.New(909998001, -8)
+	_, err = TxBuildBch(testWallet, utxos, output)
+	assert.NotNil(t, err, "expected error for the outputs above the input")
+	utxos[0].Value = decimal.Decimal{}
+	_, err = TxBuildBch(testWallet, utxos, output)
+	assert.NotNil(t, err, "expected error for the input without value")
+
+	var signatures connector.TxInSignatures
+	for _, component := range [][]byte{componentA, componentB} {
+		signer := signers.NewBtcSigner("BCH", signers.New(component), &signers.BtcNetParams, signers.BchTxInputSignature)
+		signed, err := signer.Sign([]byte(txHex), []uint64{amount})
+		if !assert.Nil(t, err, "unexpected sign error") || !assert.Len(t, signed, 1, "unexpected signatures") {
+			return
+		}
+		var signature struct {
+			Val []byte `json:"v"`
+		}
+		data, _ := base64.StdEncoding.DecodeString(signed[0])
+		assert.Nil(t, json.Unmarshal(data, &signature), "unexpected signature")
+		signatures = append(signatures, hex.EncodeToString(signature.Val))
+	}
+
+	c := &bchChainConnector{}
+	signedHex, err := c.TxRebuildVerified(txHex, connector.TxSignatures{signatures}, []btc_example.PrevOut{{Amount: amount}})
+	if !assert.Nil(t, err, "unexpected rebuild error") {
+		return
+	}
+	msgTx, err := deserializeTx(t, signedHex)
+	assert.Nil(t, err, "unexpected error")
+
+	parser := &bchChainConnector{fork: &netparams.BchRegTestFork}
+	outputs, err := parser.ParseOutputs(msgTx.TxOut)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, []*connector.OutputParsed{
+		{Address: "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", Value: big.NewInt(909900000), TxPos: 0},
+		{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Value: big.NewInt(100000000), TxPos: 1},
+		{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Value: big.NewInt(TokenOutputValue), TxPos: 2,
+			Token: &connector.TokenParsed{Category: category, Amount: big.NewInt(250000)}},
+		// the token change returns to the address of the input
+		{Address: "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", Value: big.NewInt(TokenOutputValue), TxPos: 3,
+			Token: &connector.TokenParsed{Category: category, Amount: big.NewInt(750000)}},
+	}, outputs, "unexpected outputs")
+}
@@ -0,0 +1,288 @@
+package bch
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// CashTokens prefix of the locking script: PREFIX_TOKEN category bitfield [commitment] [amount].
+const (
+	tokenPrefix          = 0xef
+	tokenReserved        = 0x80
+	tokenHasCommitment   = 0x40
+	tokenHasNFT          = 0x20
+	tokenHasAmount       = 0x10
+	tokenCapabilityMask  = 0x0f
+	maxTokenCommitment   = 40
+	tokenCategorySize    = chainhash.HashSize
+	tokenCapabilityCount = 3
+)
+
+// TokenOutputValue is the satoshi value of the token outputs built by TxBuild, it is above the dust limit of them.
+const TokenOutputValue = 1000
+
+// tokenCapabilities are the NFT capabilities by the low nibble of the bitfield.
+var tokenCapabilities = [tokenCapabilityCount]string{"none", "mutable", "minting"}
+
+// splitTokenPrefix separates the CashTokens prefix from the locking script.
+// The token is nil if the output carries no tokens.
+func splitTokenPrefix(pkScript []byte) (*connector.TokenParsed, []byte, error) {
+	if len(pkScript) == 0 || pkScript[0] != tokenPrefix {
+		return nil, pkScript, nil
+	}
+	r := bytes.NewReader(pkScript[1:])
+	var category chainhash.Hash
+	if _, err := io.ReadFull(r, category[:]); err != nil || r.Len() < 1 {
+		return nil, nil, fmt.Errorf("invalid token prefix: truncated category")
+	}
+	bitfield, _ := r.ReadByte()
+	capability := bitfield & tokenCapabilityMask
+	hasNFT := bitfield&tokenHasNFT != 0
+	switch {
+	case bitfield&tokenReserved != 0:
+		return nil, nil, fmt.Errorf("invalid token prefix: reserved bit is set")
+	case capability >= tokenCapabilityCount:
+		return nil, nil, fmt.Errorf("invalid token prefix: unknown capability %d", capability)
+	case !hasNFT && (capability != 0 || bitfield&tokenHasCommitment != 0):
+		return nil, nil, fmt.Errorf("invalid token prefix: capability or commitment without NFT")
+	case !hasNFT && bitfield&tokenHasAmount == 0:
+		return nil, nil, fmt.Errorf("invalid token prefix: neither NFT nor amount")
+	}
+
+	token := &connector.TokenParsed{
+		Category: category.String(),
+		Amount:   new(big.Int),
+	}
+	if hasNFT {
+		token.Capability = tokenCapabilities[capability]
+	}
+	if bitfield&tokenHasCommitment != 0 {
+		size, err := wire.ReadVarInt(r, 0)
+		if err != nil || size == 0 || size > maxTokenCommitment || int(size) > r.Len() {
+			return nil, nil, fmt.Errorf("invalid token prefix: commitment size")
+		}
+		token.Commitment = make([]byte, size)
+		r.Read(token.Commitment)
+	}
+	if bitfield&tokenHasAmount != 0 {
+		amount, err := wire.ReadVarInt(r, 0)
+		if err != nil || amount == 0 || amount > math.MaxInt64 {
+			return nil, nil, fmt.Errorf("invalid token prefix: amount")
+		}
+		token.Amount.SetUint64(amount)
+	}
+	return token, pkScript[len(pkScript)-r.Len():], nil
+}
+
+// outputToken separates the tokens from the locking script if the chain supports CashTokens.
+func (c *bchChainConnector) outputToken(pkScript []byte) (*connector.TokenParsed, []byte, error) {
+	if !c.forkParams().CashTokens {
+		return nil, pkScript, nil
+	}
+	return splitTokenPrefix(pkScript)
+}
+
+// fungibleTokenPrefix returns the prefix of the output carrying the amount of the fungible tokens of the category.
+func fungibleTokenPrefix(category string, amount uint64) ([]byte, error) {
+	hash, err := chainhash.NewHashFromStr(category)
+	if err != nil || len(category) != 2*tokenCategorySize {
+		return nil, fmt.Errorf("invalid token category %s", category)
+	}
+	if amount == 0 || amount > math.MaxInt64 {
+		return nil, fmt.Errorf("invalid token amount %d", amount)
+	}
+	var b bytes.Buffer
+	b.WriteByte(tokenPrefix)
+	b.Write(hash[:])
+	b.WriteByte(tokenHasAmount)
+	wire.WriteVarInt(&b, 0, amount)
+	return b.Bytes(), nil
+}
+
+// splitTokenOutputs separates the outputs of the tokens, i.e. the ones with the token address of the currency.
+func splitTokenOutputs(output []connector.OutStruct) (coins, tokens []connector.OutStruct) {
+	for i := range output {
+		if output[i].Currency != nil && output[i].Currency.GetTokenAddress() != "" {
+			tokens = append(tokens, output[i])
+			continue
+		}
+		coins = append(coins, output[i])
+	}
+	return coins, tokens
+}
+
+// tokenBalance is the amount of the fungible tokens of the category spent by the tx.
+type tokenBalance struct {
+	category string
+	amount   *big.Int
+	// input is the first input carrying the category, the token change returns to its address
+	input int
+}
+
+// tokenBalances sums the fungible tokens of the inputs per category in the order of the inputs.
+// The NFTs are not supported, they would be burned by the tx.
+func tokenBalances(utxos []connector.UtxStruct) ([]*tokenBalance, error) {
+	var balances []*tokenBalance
+	for i := range utxos {
+		token := utxos[i].Token
+		if token == nil {
+			continue
+		}
+		if token.Capability != "" || len(token.Commitment) > 0 {
+			return nil, fmt.Errorf("input %d: NFT spending is not supported", i)
+		}
+		if token.Amount == nil || token.Amount.Sign() <= 0 {
+			return nil, fmt.Errorf("input %d: invalid token amount", i)
+		}
+		balance := findBalance(balances, token.Category)
+		if balance == nil {
+			balance = &tokenBalance{category: strings.ToLower(token.Category), amount: new(big.Int), input: i}
+			balances = append(balances, balance)
+		}
+		balance.amount.Add(balance.amount, token.Amount)
+	}
+	return balances, nil
+}
+
+func findBalance(balances []*tokenBalance, category string) *tokenBalance {
+	for _, balance := range balances {
+		if balance.category == strings.ToLower(category) {
+			return balance
+		}
+	}
+	return nil
+}
+
+// hasTokens reports whether any input carries the tokens.
+func hasTokens(utxos []connector.UtxStruct) bool {
+	for i := range utxos {
+		if utxos[i].Token != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// appendTokenOutputs adds the outputs of the fungible tokens to the unsigned tx and returns the tokens
+// of the inputs left to the wallet address of the input carrying them. The token amounts are in the currency
+// precision, the category is the token address of the currency. Each output carries TokenOutputValue satoshis.
+// The values of the inputs shall cover all the outputs, the tx is checked against the policy after the outputs are added.
+func appendTokenOutputs(txHex string, utxos []connector.UtxStruct, tokens []connector.OutStruct,
+	decoder btc_example.AddressDecoder, policy netparams.StandardPolicy) (string, error) {
+
+	txData, err := hex.DecodeString(txHex)
+	if err != nil {
+		return "", err
+	}
+	tx, err := btcutil.NewTxFromBytes(txData)
+	if err != nil {
+		return "", err
+	}
+	msgTx := tx.MsgTx()
+	balances, err := tokenBalances(utxos)
+	if err != nil {
+		return "", err
+	}
+	for i := range tokens {
+		currency := tokens[i].Currency
+		units := tokens[i].Amount.Mul(decimal.New(1, int32(currency.GetPrecision())))
+		if !units.Equal(decimal.New(units.IntPart(), 0)) || units.Sign() <= 0 {
+			return "", fmt.Errorf("invalid %s amount %s", currency.GetCode(), tokens[i].Amount.String())
+		}
+		balance := findBalance(balances, currency.GetTokenAddress())
+		if balance == nil || balance.amount.Cmp(big.NewInt(units.IntPart())) < 0 {
+			return "", fmt.Errorf("insufficient %s tokens in the inputs", currency.GetCode())
+		}
+		balance.amount.Sub(balance.amount, big.NewInt(units.IntPart()))
+
+		prefix, err := fungibleTokenPrefix(currency.GetTokenAddress(), uint64(units.IntPart()))
+		if err != nil {
+			return "", err
+		}
+		address, err := decoder(tokens[i].Address)
+		if err != nil {
+			return "", err
+		}
+		pkScript, err := btc_example.PayToAddrScript(address)
+		if err != nil {
+			return "", err
+		}
+		msgTx.AddTxOut(wire.NewTxOut(TokenOutputValue, append(prefix, pkScript...)))
+	}
+	for _, balance := range balances {
+		if balance.amount.Sign() == 0 {
+			continue
+		}
+		prefix, err := fungibleTokenPrefix(balance.category, balance.amount.Uint64())
+		if err != nil {
+			return "", err
+		}
+		pkScript, err := btc_example.InputPkScript(msgTx, balance.input)
+		if err != nil {
+			return "", err
+		}
+		msgTx.AddTxOut(wire.NewTxOut(TokenOutputValue, append(prefix, pkScript...)))
+	}
+	if err = checkInputsValue(msgTx, utxos); err != nil {
+		return "", err
+	}
+	if err = btc_example.CheckStandardUnsigned(msgTx, policy); err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	b.Grow(msgTx.SerializeSize())
+	if err = msgTx.Serialize(&b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b.Bytes()), nil
+}
+
+// checkInputsValue checks that the values of the inputs cover the outputs, including the token ones.
+func checkInputsValue(msgTx *wire.MsgTx, utxos []connector.UtxStruct) error {
+	var in, out int64
+	for i := range utxos {
+		value := utxos[i].Value.Mul(decimal.New(1, btcPrecision))
+		if value.Sign() <= 0 || !value.Equal(decimal.New(value.IntPart(), 0)) {
+			return fmt.Errorf("input %d: invalid value %s", i, utxos[i].Value.String())
+		}
+		in += value.IntPart()
+	}
+	for _, txOut := range msgTx.TxOut {
+		out += txOut.Value
+	}
+	if in < out {
+		return fmt.Errorf("insufficient inputs value %d for the outputs value %d", in, out)
+	}
+	return nil
+}
+
+// stripTokenPrefix returns the locking script of the output without the CashTokens prefix.
+func stripTokenPrefix(pkScript []byte) ([]byte, error) {
+	_, lockingScript, err := splitTokenPrefix(pkScript)
+	return lockingScript, err
+}
+
+// standardPolicy returns the standardness policy of the fork, the outputs of the chain with CashTokens
+// are checked without the token prefix.
+func standardPolicy(fork netparams.ForkParams) netparams.StandardPolicy {
+	policy := fork.Standard
+	if fork.CashTokens {
+		policy.LockingScript = stripTokenPrefix
+	}
+	return policy
+}
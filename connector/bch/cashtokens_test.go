@@ -0,0 +1,237 @@
+package bch
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+type tokenCurrency struct {
+	category string
+}
+
+func (tokenCurrency) GetCode() string           { return "TKN" }
+func (tokenCurrency) GetPrecision() uint8       { return 2 }
+func (c tokenCurrency) GetTokenAddress() string { return c.category }
+func (tokenCurrency) GetTokenCode() int64       { return 0 }
+
+func TestCashTokens(t *testing.T) {
+	const category = "7d2ee2a55f2ea35e0ae8e1c5e37c1bc0a5a0cbcc9a4b1b0df1e8a7a84d9db4f4"
+	// the category in the internal byte order
+	categoryBytes := "f4b49d4da8a7e8f10d1b4b9acccba0a5c01b7ce3c5e1e80a5ea32e5fa5e22e7d"
+	p2pkh, _ := hex.DecodeString("76a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac")
+	prefixedOnly := func(prefix string) []byte {
+		b, _ := hex.DecodeString("ef" + categoryBytes + prefix)
+		return b
+	}
+	prefixed := func(prefix string) []byte {
+		return append(prefixedOnly(prefix), p2pkh...)
+	}
+	const hash = "f6efb8592325c58e9ddc9019bfb09e19bb244beac1d5a774d310f2b114051fd7"
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive splitting the token prefix",
+			func(t *testing.T) {
+				token, pkScript, err := splitTokenPrefix(p2pkh)
+				assert.Nil(t, err, "unexpected error")
+				assert.Nil(t, token, "unexpected token")
+				assert.Equal(t, p2pkh, pkScript, "unexpected script")
+
+				// fungible amount 1000000
+				token, pkScript, err = splitTokenPrefix(prefixed("10fe40420f00"))
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, &connector.TokenParsed{Category: category, Amount: big.NewInt(1000000)}, token, "unexpected token")
+				assert.Equal(t, p2pkh, pkScript, "unexpected script")
+
+				// minting NFT with the commitment and amount 252
+				token, pkScript, err = splitTokenPrefix(prefixed("7202cafefc"))
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, &connector.TokenParsed{Category: category, Amount: big.NewInt(252),
+					Capability: "minting", Commitment: []byte{0xca, 0xfe}}, token, "unexpected token")
+				assert.Equal(t, p2pkh, pkScript, "unexpected script")
+
+				// immutable NFT without the commitment
+				token, _, err = splitTokenPrefix(prefixed("20"))
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, "none", token.Capability, "unexpected capability")
+				assert.Equal(t, int64(0), token.Amount.Int64(), "unexpected amount")
+			},
+		},
+		{
+			"Negative invalid token prefixes",
+			func(t *testing.T) {
+				for name, prefix := range map[string]string{
+					"reserved bit":             "9001",
+					"unknown capability":       "23",
+					"capability without NFT":   "1101",
+					"commitment without NFT":   "5001ca01",
+					"neither NFT nor amount":   "00",
+					"empty commitment":         "6000",
+					"truncated commitment":     "6028cafe",
+					"zero amount":              "1000",
+					"non-canonical amount":     "10fd0100",
+					"amount above the maximum": "10ffffffffffffffffff",
+				} {
+					_, _, err := splitTokenPrefix(prefixed(prefix))
+					assert.NotNil(t, err, "expected error for %s", name)
+				}
+				b, _ := hex.DecodeString("ef" + categoryBytes[:20])
+				_, _, err := splitTokenPrefix(b)
+				assert.NotNil(t, err, "expected error for truncated category")
+			},
+		},
+		{
+			"Positive parsing the token outputs",
+			func(t *testing.T) {
+				parser := &bchChainConnector{fork: &netparams.BchRegTestFork}
+				outputs, err := parser.ParseOutputs([]*wire.TxOut{
+					wire.NewTxOut(100000000, p2pkh),
+					wire.NewTxOut(1000, prefixed("10fe40420f00")),
+				})
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, []*connector.OutputParsed{
+					{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Value: big.NewInt(100000000), TxPos: 0},
+					{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Value: big.NewInt(1000), TxPos: 1,
+						Token: &connector.TokenParsed{Category: category, Amount: big.NewInt(1000000)}},
+				}, outputs, "unexpected outputs")
+
+				// the chain without CashTokens treats the prefix as the script
+				xec := &bchChainConnector{fork: &netparams.XecRegTestFork}
+				_, err = xec.ParseOutputs([]*wire.TxOut{wire.NewTxOut(1000, prefixed("10fe40420f00"))})
+				assert.NotNil(t, err, "expected error")
+			},
+		},
+		{
+			"Positive appending the token outputs",
+			func(t *testing.T) {
+				txHex, err := TxBuildBch(testWallet, []connector.UtxStruct{{TxHash: hash, Index: 1000}}, []connector.OutStruct{
+					{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Amount: decimal.New(1, 0)},
+				})
+				assert.Nil(t, err, "unexpected error")
+				decoder := func(addr string) (btcutil.Address, error) {
+					return &btc_example.CoinAddress{Addr: addr}, nil
+				}
+				policy := standardPolicy(netparams.BchMainNetFork)
+
+				coins, tokens := splitTokenOutputs([]connector.OutStruct{
+					{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: decimal.New(10000, 0), Currency: tokenCurrency{category}},
+					{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: decimal.New(1, 0), Currency: testCurrency{}},
+				})
+				assert.Len(t, coins, 1, "unexpected coin outputs")
+				utxos := []connector.UtxStruct{{TxHash: hash, Index: 1000, Value: decimal.New(2, 0),
+					Token: &connector.TokenParsed{Category: category, Amount: big.NewInt(1500000)}}}
+				got, err := appendTokenOutputs(txHex, utxos, tokens, decoder, policy)
+				assert.Nil(t, err, "unexpected error")
+
+				msgTx, _ := deserializeTx(t, txHex)
+				change, _ := btc_example.InputPkScript(msgTx, 0)
+				msgTx.AddTxOut(wire.NewTxOut(TokenOutputValue, prefixed("10fe40420f00")))
+				msgTx.AddTxOut(wire.NewTxOut(TokenOutputValue, append(prefixedOnly("10fe20a10700"), change...)))
+				assert.Equal(t, serializeTx(t, msgTx), got, "unexpected tx")
+
+				// the tokens of the inputs return to the wallet
+				got, err = appendTokenOutputs(txHex, utxos, nil, decoder, policy)
+				assert.Nil(t, err, "unexpected error")
+				msgTx, _ = deserializeTx(t, got)
+				if assert.Len(t, msgTx.TxOut, 2, "unexpected outputs") {
+					assert.Equal(t, append(prefixedOnly("10fe60e31600"), change...), msgTx.TxOut[1].PkScript, "unexpected token change")
+				}
+
+				_, err = appendTokenOutputs(txHex, utxos, []connector.OutStruct{
+					{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: decimal.New(15001, 0), Currency: tokenCurrency{category}},
+				}, decoder, policy)
+				assert.NotNil(t, err, "expected error for the amount above the inputs")
+				_, err = appendTokenOutputs(txHex, nil, tokens, decoder, policy)
+				assert.NotNil(t, err, "expected error for the inputs without tokens")
+				_, err = appendTokenOutputs(txHex, []connector.UtxStruct{{TxHash: hash, Index: 1000, Token: &connector.TokenParsed{
+					Category: category, Amount: big.NewInt(1500000), Capability: "none"}}}, tokens, decoder, policy)
+				assert.NotNil(t, err, "expected error for the NFT input")
+				_, err = appendTokenOutputs(txHex, utxos, []connector.OutStruct{
+					{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: decimal.New(1, -3), Currency: tokenCurrency{category}},
+				}, decoder, policy)
+				assert.NotNil(t, err, "expected error for the amount below the precision")
+				_, err = appendTokenOutputs(txHex, utxos, []connector.OutStruct{
+					{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: decimal.New(1, 0), Currency: tokenCurrency{"cafe"}},
+				}, decoder, policy)
+				assert.NotNil(t, err, "expected error for the invalid category")
+			},
+		},
+		{
+			"Positive standardness of the token outputs",
+			func(t *testing.T) {
+				msgTx := wire.NewMsgTx(2)
+				msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+				msgTx.AddTxOut(wire.NewTxOut(TokenOutputValue, prefixed("10fe40420f00")))
+				stdErr, ok := btc_example.CheckStandard(msgTx, netparams.BchStandardPolicy).(*btc_example.StandardError)
+				if assert.True(t, ok, "expected standardness error") {
+					assert.Equal(t, btc_example.ErrScriptPubKey, stdErr.Err, "unexpected error")
+				}
+				assert.Nil(t, btc_example.CheckStandard(msgTx, standardPolicy(netparams.BchRegTestFork)), "unexpected error")
+
+				// the prefix is not stripped on the chain without CashTokens
+				stdErr, ok = btc_example.CheckStandard(msgTx, standardPolicy(netparams.XecRegTestFork)).(*btc_example.StandardError)
+				if assert.True(t, ok, "expected standardness error") {
+					assert.Equal(t, btc_example.ErrScriptPubKey, stdErr.Err, "unexpected error")
+				}
+
+				// the token output below the dust threshold of its size
+				msgTx.TxOut[0].Value = 546
+				stdErr, ok = btc_example.CheckStandard(msgTx, standardPolicy(netparams.BchRegTestFork)).(*btc_example.StandardError)
+				if assert.True(t, ok, "expected standardness error") {
+					assert.Equal(t, btc_example.ErrDust, stdErr.Err, "unexpected error")
+				}
+
+				msgTx.TxOut[0].Value = TokenOutputValue
+				msgTx.TxOut[0].PkScript = prefixed("00")
+				stdErr, ok = btc_example.CheckStandard(msgTx, standardPolicy(netparams.BchRegTestFork)).(*btc_example.StandardError)
+				if assert.True(t, ok, "expected standardness error") {
+					assert.Equal(t, btc_example.ErrScriptPubKey, stdErr.Err, "unexpected error")
+				}
+			},
+		},
+		{
+			"Negative building the token outputs on the chain without CashTokens",
+			func(t *testing.T) {
+				xec := &bchChainConnector{fork: &netparams.XecRegTestFork}
+				_, err := xec.TxBuild(&connector.WalletSignStruct{}, []connector.UtxStruct{}, []connector.OutStruct{
+					{Address: "ecregtest:qzu7d73hakh39hc2qqmz2ln73x56hdp04cth7s2tee", Amount: decimal.New(1, 0), Currency: tokenCurrency{category}},
+				})
+				assert.NotNil(t, err, "expected error")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func serializeTx(t *testing.T, msgTx *wire.MsgTx) string {
+	var b bytes.Buffer
+	assert.Nil(t, msgTx.Serialize(&b), "unexpected error")
+	return hex.EncodeToString(b.Bytes())
+}
+
+func deserializeTx(t *testing.T, txHex string) (*wire.MsgTx, error) {
+	txData, err := hex.DecodeString(txHex)
+	assert.Nil(t, err, "unexpected error")
+	msgTx := new(wire.MsgTx)
+	return msgTx, msgTx.Deserialize(bytes.NewReader(txData))
+}
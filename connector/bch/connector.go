@@ -3,20 +3,28 @@ package bch
 import (
 	"fmt"
 	"math/big"
+	"sort"
 
 	bchchaincfg "github.com/bchsuite/bchd/chaincfg"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	btctxscript "github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 
-	"github.com/Messer4/bchaddr"
 	"github.com/bchsuite/bchd/chaincfg"
-	"github.com/bchsuite/bchd/txscript"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
 
+	bchaddress "github.com/stanche/crypto-interface/address/bch"
 	"github.com/stanche/crypto-interface/connector"
 	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+const (
+	sigHashForkID = 0x40
+	btcPrecision  = 8
 )
 
 type (
@@ -25,16 +33,26 @@ type (
 		ibtc    btc_example.IBtcChainConnector
 		chain   *chaincfg.Params
 		regtest bool
+		// fork is nil for BCH, which is configured with chain and regtest.
+		fork *netparams.ForkParams
 	}
+
+	// addressMap maps the legacy addresses to the requested ones.
+	addressMap map[string]string
 )
 
 // NewChainConnector returns the IBtcChainConnector interface
-// to use btc importer as ltc one
-func NewChainConnector(walletID uint64, cfg *connector.WalletParams) (btc_example.IBtcChainConnector, error) {
-	iBtcConnector, err := btc_example.NewBtcChainConnector(walletID, cfg)
+// to use btc importer as ltc one. The network is selected by cfg.ChainConfig, see netparams.BchFork.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (btc_example.IBtcChainConnector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	fork := netparams.BchFork(cfg.ChainConfig)
+	iBtcConnector, err := btc_example.NewChainConnector(walletID, cfg, txBatchSize, fork.Net)
 	if err != nil {
 		return nil, err
 	}
+	chain, regtest := bchChain(fork)
 
 	connector := &bchChainConnector{
 		Connector: connector.Connector{
@@ -43,36 +61,165 @@ func NewChainConnector(walletID uint64, cfg *connector.WalletParams) (btc_exampl
 			WalletType: cfg.Type,
 		},
 		ibtc:    iBtcConnector,
-		chain:   &bchchaincfg.TestNet3Params,
-		regtest: cfg.ChainConfig == "regtest",
+		chain:   chain,
+		regtest: regtest,
 	}
 	connector.ibtc.DecoderSet(connector.DecodeAddress)
 
 	return connector, nil
 }
 
-func (c *bchChainConnector) BalanceGet(_ connector.Currency, address ...string) (balance connector.AddressBalance, err error) {
-	// log.Warnf("bchChainConnector does not support BalanceGet method")
-	return balance, fmt.Errorf("unsupported method: BalanceGet")
+// NewForkChainConnector returns the connector of the chain forked from BCH, i.e. eCash or BSV.
+func NewForkChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int,
+	fork netparams.ForkParams) (btc_example.IBtcChainConnector, error) {
+
+	bcc, err := btc_example.NewChainConnector(walletID, cfg, txBatchSize, fork.Net)
+	if err != nil {
+		return nil, err
+	}
+	c := &bchChainConnector{
+		Connector: connector.Connector{
+			WalletId:   walletID,
+			Currency:   cfg.Currency,
+			WalletType: cfg.Type,
+		},
+		ibtc: bcc,
+		fork: &fork,
+	}
+	c.ibtc.DecoderSet(c.DecodeAddress)
+
+	return c, nil
 }
 
-func (c *bchChainConnector) ValidateAddress(address string) (bool, error) {
+// bchChain returns the chain params and the regtest flag of BCH network.
+func bchChain(fork netparams.ForkParams) (*chaincfg.Params, bool) {
+	switch fork.CashAddrPrefix {
+	case netparams.BchMainNetFork.CashAddrPrefix:
+		return &bchchaincfg.MainNetParams, false
+	case netparams.BchRegTestFork.CashAddrPrefix:
+		return &bchchaincfg.TestNet3Params, true
+	}
+	return &bchchaincfg.TestNet3Params, false
+}
 
-	_, err := bchaddr.ToLegacyAddress(address)
-	return err == nil, nil
+// forkParams returns the parameters of the connector chain.
+func (c *bchChainConnector) forkParams() netparams.ForkParams {
+	switch {
+	case c.fork != nil:
+		return *c.fork
+	case c.regtest:
+		return netparams.BchRegTestFork
+	case c.chain == &bchchaincfg.MainNetParams:
+		return netparams.BchMainNetFork
+	}
+	return netparams.BchTestNetFork
 }
 
-func (c *bchChainConnector) DecodeAddress(addr string) (btcutil.Address, error) {
+// standard returns the standardness policy of the connector chain.
+func (c *bchChainConnector) standard() netparams.StandardPolicy {
+	return standardPolicy(c.forkParams())
+}
+
+// legacyAddress converts the cash or legacy address into the legacy one.
+func (c *bchChainConnector) legacyAddress(addr string) (string, error) {
+	fork := c.forkParams()
+	return bchaddress.LegacyAddress(addr, fork.CashAddrPrefix, fork.Net)
+}
 
-	legacyAddress, err := bchaddr.ToLegacyAddress(addr)
+// BalanceGet converts the cash or legacy addresses into the legacy ones for the BTC address index.
+// The invalid addresses are skipped.
+func (c *bchChainConnector) BalanceGet(currency connector.Currency, addresses ...string) (balance connector.AddressBalance, err error) {
+	if len(addresses) == 0 {
+		return balance, fmt.Errorf("unsupported params: BalanceGet.addresses are empty")
+	}
+	legacy := legacyAddresses(addresses, c.legacyAddress)
+	if len(legacy) == 0 {
+		return balance, nil
+	}
+	return c.ibtc.BalanceGet(currency, legacy.list()...)
+}
+
+// Utxos returns the unspent outputs of the cash or legacy addresses, the addresses of the outputs are in the requested format.
+func (c *bchChainConnector) Utxos(currency connector.Currency, addresses ...string) ([]connector.UtxoStruct, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("unsupported params: Utxos.addresses are empty")
+	}
+	legacy := legacyAddresses(addresses, c.legacyAddress)
+	if len(legacy) == 0 {
+		return nil, nil
+	}
+	utxos, err := c.ibtc.Utxos(currency, legacy.list()...)
 	if err != nil {
 		return nil, err
 	}
+	for i := range utxos {
+		if addr, ok := legacy[utxos[i].Address]; ok {
+			utxos[i].Address = addr
+		}
+	}
+	return utxos, nil
+}
+
+// legacyAddresses converts the addresses skipping the invalid ones.
+func legacyAddresses(addresses []string, convert func(string) (string, error)) addressMap {
+	legacy := make(addressMap, len(addresses))
+	for _, addr := range addresses {
+		legacyAddress, err := convert(addr)
+		if err != nil {
+			continue
+		}
+		legacy[legacyAddress] = addr
+	}
+	return legacy
+}
+
+// list returns the legacy addresses in the stable order.
+func (m addressMap) list() []string {
+	list := make([]string, 0, len(m))
+	for legacy := range m {
+		list = append(list, legacy)
+	}
+	sort.Strings(list)
+	return list
+}
 
-	bchAddr := btc_example.CoinAddress{
-		Addr: legacyAddress,
+// ValidateAddress accepts the CashAddr addresses with the prefix of the connector network (or without the prefix)
+// and the legacy addresses of the network.
+func (c *bchChainConnector) ValidateAddress(address string) (bool, error) {
+	fork := c.forkParams()
+	if fork.CashAddrPrefix != "" {
+		if _, _, err := bchaddress.DecodeCashAddress(address, fork.CashAddrPrefix); err == nil {
+			return true, nil
+		}
+	}
+	_, version, err := base58.CheckDecode(address)
+	if err != nil {
+		return false, nil
+	}
+	if version == fork.Net.ScriptHashAddrID {
+		return !fork.Standard.NoScriptHash, nil
 	}
-	return &bchAddr, err
+	return version == fork.Net.PubKeyHashAddrID, nil
+}
+
+func (c *bchChainConnector) DecodeAddress(addr string) (btcutil.Address, error) {
+	legacyAddress, err := c.legacyAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &btc_example.CoinAddress{Addr: legacyAddress}, nil
+}
+
+// DecodeAddress converts the cash or legacy address of any BCH network into CoinAddress.
+func DecodeAddress(addr string) (btcutil.Address, error) {
+	var err error
+	for _, fork := range []netparams.ForkParams{netparams.BchMainNetFork, netparams.BchTestNetFork, netparams.BchRegTestFork} {
+		var legacyAddress string
+		if legacyAddress, err = bchaddress.LegacyAddress(addr, fork.CashAddrPrefix, fork.Net); err == nil {
+			return &btc_example.CoinAddress{Addr: legacyAddress}, nil
+		}
+	}
+	return nil, err
 }
 
 func (c *bchChainConnector) DecoderSet(decoder btc_example.AddressDecoder) {
@@ -85,17 +232,23 @@ func (c *bchChainConnector) CreateRawTransaction(inputs []btcjson.TransactionInp
 	return c.ibtc.CreateRawTransaction(inputs, amounts)
 }
 
+// ParseOutputs extracts the addresses of the outputs and the CashTokens they carry.
+// The addresses are in CashAddr format if the chain supports it.
 func (c *bchChainConnector) ParseOutputs(txOuts []*wire.TxOut) ([]*connector.OutputParsed, error) {
+	fork := c.forkParams()
 	var outputs []*connector.OutputParsed
 	for index := range txOuts {
-		_, addresses, _, err := txscript.ExtractPkScriptAddrs(txOuts[index].PkScript, c.chain)
+		token, pkScript, err := c.outputToken(txOuts[index].PkScript)
+		if err != nil {
+			return nil, err
+		}
+		_, addresses, _, err := btctxscript.ExtractPkScriptAddrs(pkScript, fork.Net)
 		if err != nil {
-			// log.Errorf("ExtractTxOutAddresses %s", err.Error())
 			return nil, err
 		}
 
 		for _, address := range addresses {
-			addr, err := bchaddr.ToCashAddress(address.EncodeAddress(), c.regtest)
+			addr, err := bchaddress.CashAddress(address.EncodeAddress(), fork.CashAddrPrefix, fork.Net)
 			if err != nil {
 				return nil, err
 			}
@@ -103,6 +256,7 @@ func (c *bchChainConnector) ParseOutputs(txOuts []*wire.TxOut) ([]*connector.Out
 				Address: addr,
 				Value:   big.NewInt(txOuts[index].Value),
 				TxPos:   uint(index),
+				Token:   token,
 			})
 		}
 	}
@@ -122,17 +276,109 @@ func (c *bchChainConnector) TxStatus(txID string, blockNo uint64) (*connector.Tx
 	return c.ibtc.TxStatus(txID, blockNo)
 }
 
+// TxBuild builds the unsigned multisig transaction. The outputs of the currencies with the token address
+// carry the fungible CashTokens of that category, see TxBuildBch.
 func (c *bchChainConnector) TxBuild(walletData *connector.WalletSignStruct,
 	utxosIn interface{}, output []connector.OutStruct) (string, error) {
 
-	return c.ibtc.TxBuild(walletData, utxosIn, output)
+	if c.fork == nil {
+		return TxBuildBch(walletData, utxosIn, output)
+	}
+	utxos, _ := utxosIn.([]connector.UtxStruct)
+	if _, tokens := splitTokenOutputs(output); len(tokens) > 0 || hasTokens(utxos) {
+		return "", fmt.Errorf("%s does not support tokens", c.fork.Code)
+	}
+	return btc_example.TxBuildBtc(walletData, utxosIn, scaleOutputs(output, c.fork.Precision), c.DecodeAddress, false, c.standard(), nil)
+}
+
+// TxBuildBch builds the unsigned multisig transaction without the node.
+// The token outputs follow the coin ones, the tokens of the inputs which are not sent return to the wallet
+// in the token change outputs. The satoshis of the token outputs are paid from the fee, so with the tokens
+// the values of the inputs are required to cover the coin and the token outputs.
+func TxBuildBch(walletData *connector.WalletSignStruct,
+	utxosIn interface{}, output []connector.OutStruct) (string, error) {
+
+	policy := standardPolicy(netparams.BchMainNetFork)
+	coins, tokens := splitTokenOutputs(output)
+	txHex, err := btc_example.TxBuildBtc(walletData, utxosIn, coins, DecodeAddress, false, policy, nil)
+	if err != nil {
+		return "", err
+	}
+	utxos, _ := utxosIn.([]connector.UtxStruct)
+	if len(tokens) == 0 && !hasTokens(utxos) {
+		return txHex, nil
+	}
+	return appendTokenOutputs(txHex, utxos, tokens, DecodeAddress, policy)
 }
 
 func (c *bchChainConnector) TxBroadcast(txHex string) (string, error) {
 	return c.ibtc.TxBroadcast(txHex)
 }
 
+// TxValidate checks the signed tx with testmempoolaccept of the node.
+// The node reports the fee in the coins of the fork, it is converted to satoshis.
+func (c *bchChainConnector) TxValidate(txHex string) (*connector.TxValidation, error) {
+	validation, err := c.ibtc.TxValidate(txHex)
+	if err != nil || validation.Fee == nil {
+		return validation, err
+	}
+	if precision := c.forkParams().Precision; precision < btcPrecision {
+		validation.Fee.Div(validation.Fee, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(btcPrecision-precision)), nil))
+	}
+	return validation, nil
+}
+
 // TxRebuild - combine parsed hex Tx with the signatures
 func (c *bchChainConnector) TxRebuild(txHex string, signatures connector.TxSignatures) (string, error) {
-	return btc_example.TxRebuildBtc(txHex, signatures)
+	return btc_example.TxRebuildBtc(txHex, signatures, c.standard())
+}
+
+// TxRebuildVerified checks the signatures with SIGHASH_FORKID digest and combines them with the tx.
+// Schnorr signatures are attributed to the cosigners keys and combined with the checkbits dummy.
+// The script engine is not run for BCH as it does not support SIGHASH_FORKID.
+func (c *bchChainConnector) TxRebuildVerified(txHex string, signatures connector.TxSignatures, prevOuts []btc_example.PrevOut) (string, error) {
+	amounts := make([]int64, len(prevOuts))
+	for i := range prevOuts {
+		amounts[i] = prevOuts[i].Amount
+	}
+	fork := c.forkParams()
+	if isSchnorr(signatures) {
+		if !fork.Schnorr {
+			return "", fmt.Errorf("%s does not accept Schnorr signatures", fork.Code)
+		}
+		cosigners, err := schnorrCosigners(txHex, signatures, amounts, ForkSigHash(fork.ForkID))
+		if err != nil {
+			return "", err
+		}
+		return btc_example.TxRebuildSchnorr(txHex, signatures, cosigners, standardPolicy(fork))
+	}
+	err := btc_example.VerifySignatures(txHex, signatures, amounts, ForkSigHash(fork.ForkID))
+	if err != nil {
+		return "", err
+	}
+	return btc_example.TxRebuildBtc(txHex, signatures, standardPolicy(fork))
+}
+
+// RBFSet does nothing, BCH does not support replace-by-fee.
+func (c *bchChainConnector) RBFSet(bool) {}
+
+// TxBump builds a CPFP child, BCH does not support replace-by-fee.
+func (c *bchChainConnector) TxBump(walletData *connector.WalletSignStruct, txHex string, params btc_example.TxBumpParams) (string, error) {
+	if params.Method != btc_example.BumpCPFP {
+		return "", fmt.Errorf("unsupported bump method %d: BCH supports CPFP only", params.Method)
+	}
+	return btc_example.TxBumpBtc(walletData, txHex, params, c.standard())
+}
+
+// SigHash calculates the BIP143-like signature hash with SIGHASH_FORKID used by BCH.
+func SigHash(subScript []byte, hashType btctxscript.SigHashType, tx *wire.MsgTx, idx int, amount int64) ([]byte, error) {
+	return btctxscript.CalcWitnessSigHash(subScript, btctxscript.NewTxSigHashes(tx),
+		hashType|sigHashForkID, tx, idx, amount)
+}
+
+// ForkSigHash returns the signature hash function with the fork id in the upper bits of the hash type.
+func ForkSigHash(forkID uint32) btc_example.SigHashFunc {
+	return func(subScript []byte, hashType btctxscript.SigHashType, tx *wire.MsgTx, idx int, amount int64) ([]byte, error) {
+		return SigHash(subScript, hashType|btctxscript.SigHashType(forkID<<8), tx, idx, amount)
+	}
 }
@@ -0,0 +1,21 @@
+package bch
+
+import (
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+// scaleOutputs converts the amounts of the coins with the precision into BTC-like ones with 8 decimal places,
+// so that the satoshi values stay the same.
+func scaleOutputs(output []connector.OutStruct, precision uint8) []connector.OutStruct {
+	if precision == btcPrecision {
+		return output
+	}
+	scaled := make([]connector.OutStruct, len(output))
+	copy(scaled, output)
+	for i := range scaled {
+		scaled[i].Amount = scaled[i].Amount.Mul(decimal.New(1, int32(precision)-btcPrecision))
+	}
+	return scaled
+}
@@ -0,0 +1,121 @@
+package bch
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+func TestForkChainConnector(t *testing.T) {
+	xec := &bchChainConnector{fork: &netparams.XecRegTestFork}
+	bsv := &bchChainConnector{fork: &netparams.BsvRegTestFork}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive validating the addresses",
+			func(t *testing.T) {
+				for address, want := range map[string]bool{
+					"ecregtest:qzu7d73hakh39hc2qqmz2ln73x56hdp04cth7s2tee": true,
+					"qzu7d73hakh39hc2qqmz2ln73x56hdp04cth7s2tee":           true,
+					"mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk":                   true,
+					"bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye":    false,
+				} {
+					valid, err := xec.ValidateAddress(address)
+					assert.Nil(t, err, "unexpected error")
+					assert.Equal(t, want, valid, "unexpected XEC validation of %s", address)
+				}
+				for address, want := range map[string]bool{
+					"mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk":                true,
+					"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu":                false,
+					"2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT":               false,
+					"bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye": false,
+				} {
+					valid, err := bsv.ValidateAddress(address)
+					assert.Nil(t, err, "unexpected error")
+					assert.Equal(t, want, valid, "unexpected BSV validation of %s", address)
+				}
+			},
+		},
+		{
+			"Positive parsing the outputs",
+			func(t *testing.T) {
+				p2pkh, _ := hex.DecodeString("76a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac")
+				p2sh, _ := hex.DecodeString("a914af70bbab80fb64dbf90b212f4971cc4807d0b88087")
+				txOuts := []*wire.TxOut{wire.NewTxOut(100000000, p2pkh), wire.NewTxOut(909900000, p2sh)}
+
+				outputs, err := xec.ParseOutputs(txOuts)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, []*connector.OutputParsed{
+					{Address: "ecregtest:qzu7d73hakh39hc2qqmz2ln73x56hdp04cth7s2tee", Value: big.NewInt(100000000), TxPos: 0},
+					{Address: "ecregtest:pzhhpwatsrakfklepvsj7jt3e3yq059csqpext7nyg", Value: big.NewInt(909900000), TxPos: 1},
+				}, outputs, "unexpected outputs")
+
+				outputs, err = bsv.ParseOutputs(txOuts)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", outputs[0].Address, "unexpected address")
+				assert.Equal(t, "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT", outputs[1].Address, "unexpected address")
+			},
+		},
+		{
+			"Positive building XEC tx in the coins with 2 decimal places",
+			func(t *testing.T) {
+				wallet := &connector.WalletSignStruct{
+					Signers: 2,
+					XPubs: []string{
+						"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+						"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+						"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+					},
+				}
+				amount, _ := decimal.NewFromString("1000000")
+				change, _ := decimal.NewFromString("9099000.00")
+
+				// the same satoshi values as in TestTxBuildBch
+				want := "0200000001d71f0514b1f210d374a7d5c1ea4b24bb199eb0bf1990dc9d8ec5252359b8eff600000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000e80300004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000e80300004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a00000000e803000053aeffffffff02e0f83b360000000017a914af70bbab80fb64dbf90b212f4971cc4807d0b8808700e1f505000000001976a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac00000000"
+
+				got, err := xec.TxBuild(wallet,
+					[]connector.UtxStruct{{TxHash: "f6efb8592325c58e9ddc9019bfb09e19bb244beac1d5a774d310f2b114051fd7", TxPos: 0, Index: 1000}},
+					[]connector.OutStruct{
+						{Address: "ecregtest:qzu7d73hakh39hc2qqmz2ln73x56hdp04cth7s2tee", Amount: amount},
+						{Address: "ecregtest:pzhhpwatsrakfklepvsj7jt3e3yq059csqpext7nyg", Amount: change},
+					})
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, want, got, "unexpected tx")
+			},
+		},
+		{
+			"Negative Schnorr signatures on BSV",
+			func(t *testing.T) {
+				txHex := "0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000"
+				signatures := connector.TxSignatures{{
+					"42cc995ad72865a45439e69db0aa72e24178be2960f936c22f8a2b3e0f95573bd442127baa962de059919fbc2c14a6c1c199b98170bc501068e60162764017ba41",
+					"012092d3454536bccd676bd25da09b04f44aac786a35811ac5c71b5a8364c5fdbd5235e253302e6bc15084a582e5b24cca7dca41b132e3eede3fa13e82172c7841",
+				}}
+
+				_, err := bsv.TxRebuildVerified(txHex, signatures, []btc_example.PrevOut{{Amount: 110000000}})
+				assert.NotNil(t, err, "expected error")
+
+				// eCash keeps BCH fork id
+				_, err = xec.TxRebuildVerified(txHex, signatures, []btc_example.PrevOut{{Amount: 110000000}})
+				assert.Nil(t, err, "unexpected error")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
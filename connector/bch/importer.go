@@ -0,0 +1,25 @@
+package bch
+
+import (
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// NewBlockChainImporter creates the importer of BCH blocks. The outputs are parsed with bchChainConnector.ParseOutputs,
+// so the operations have the CashAddr addresses (bchreg: prefix for "regtest" chainConfig, bchtest: for "testnet").
+func NewBlockChainImporter(node connector.NodeParams, chainConfig string, txBatchSize int) (connector.BlockChainImporter, error) {
+	fork := netparams.BchFork(chainConfig)
+	parser := &bchChainConnector{}
+	parser.chain, parser.regtest = bchChain(fork)
+	return btc_example.NewBlockChainImporterWithOptions(node, *fork.Net, txBatchSize,
+		btc_example.ImporterOptions{ParseOutputs: parser.ParseOutputs})
+}
+
+// NewForkBlockChainImporter creates the importer of the blocks of the chain forked from BCH.
+// The addresses of the operations are in CashAddr format with the fork prefix, or legacy if the fork has no CashAddr.
+func NewForkBlockChainImporter(node connector.NodeParams, fork netparams.ForkParams, txBatchSize int) (connector.BlockChainImporter, error) {
+	parser := &bchChainConnector{fork: &fork}
+	return btc_example.NewBlockChainImporterWithOptions(node, *fork.Net, txBatchSize,
+		btc_example.ImporterOptions{ParseOutputs: parser.ParseOutputs})
+}
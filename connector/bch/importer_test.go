@@ -0,0 +1,30 @@
+package bch
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	bchchaincfg "github.com/bchsuite/bchd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+func TestBchChainConnector_ParseOutputs(t *testing.T) {
+	p2pkh, _ := hex.DecodeString("76a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac")
+	p2sh, _ := hex.DecodeString("a914af70bbab80fb64dbf90b212f4971cc4807d0b88087")
+
+	// the parser of the regtest importer
+	parser := &bchChainConnector{chain: &bchchaincfg.TestNet3Params, regtest: true}
+	outputs, err := parser.ParseOutputs([]*wire.TxOut{
+		wire.NewTxOut(100000000, p2pkh),
+		wire.NewTxOut(909900000, p2sh),
+	})
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, []*connector.OutputParsed{
+		{Address: "bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", Value: big.NewInt(100000000), TxPos: 0},
+		{Address: "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", Value: big.NewInt(909900000), TxPos: 1},
+	}, outputs, "unexpected outputs")
+}
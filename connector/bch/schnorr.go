@@ -0,0 +1,112 @@
+package bch
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	btctxscript "github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/connector/btc_example/script"
+	"github.com/stanche/crypto-interface/signer/schnorr"
+)
+
+// TxRebuildSchnorr combines the tx with Schnorr signatures produced by signers.BchSchnorrTxInputSignature.
+// cosigners are the indexes of the signing keys in the sorted multisig keys in the order of the signatures.
+func (c *bchChainConnector) TxRebuildSchnorr(txHex string, signatures connector.TxSignatures, cosigners [][]int) (string, error) {
+	fork := c.forkParams()
+	if !fork.Schnorr {
+		return "", fmt.Errorf("%s does not accept Schnorr signatures", fork.Code)
+	}
+	return btc_example.TxRebuildSchnorr(txHex, signatures, cosigners, standardPolicy(fork))
+}
+
+// isSchnorr reports whether the signatures are Schnorr ones. BCH consensus treats any 64-byte
+// signature (without the hash type) as Schnorr, so the size is decisive.
+func isSchnorr(signatures connector.TxSignatures) bool {
+	for _, inSignatures := range signatures {
+		for _, sig := range inSignatures {
+			return len(sig) == 2*(schnorr.SignatureSize+1)
+		}
+	}
+	return false
+}
+
+// schnorrCosigners verifies the Schnorr signatures and returns the indexes of the signing keys
+// in the sorted multisig keys, as required by TxRebuildSchnorr.
+func schnorrCosigners(txHex string, signatures connector.TxSignatures, amounts []int64,
+	sigHash btc_example.SigHashFunc) ([][]int, error) {
+	txData, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := btcutil.NewTxFromBytes(txData)
+	if err != nil {
+		return nil, err
+	}
+	msgTx := tx.MsgTx()
+	countTxIn := len(msgTx.TxIn)
+	if countTxIn != len(signatures) {
+		return nil, fmt.Errorf("inconsistent tx inputs and signatures quantity: %d ~ %d", countTxIn, len(signatures))
+	}
+	if len(amounts) != countTxIn {
+		return nil, fmt.Errorf("inconsistent tx inputs and amounts quantity: %d ~ %d", countTxIn, len(amounts))
+	}
+	cosigners := make([][]int, countTxIn)
+	for indexTxIn := range msgTx.TxIn {
+		redeemScript, err := script.RedeemScriptFromTxin(msgTx.TxIn[indexTxIn])
+		if err != nil {
+			return nil, err
+		}
+		m, pubkeys, _, _, err := script.PubkeysIndexPathFromScript(redeemScript, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(signatures[indexTxIn]) != int(m) {
+			return nil, fmt.Errorf("inconsistent signatures (%d, expected %d) for input %d",
+				len(signatures[indexTxIn]), m, indexTxIn)
+		}
+
+		lastSorted := -1
+		for j, sigHex := range signatures[indexTxIn] {
+			sig, err := hex.DecodeString(sigHex)
+			if err == nil && len(sig) != schnorr.SignatureSize+1 {
+				err = fmt.Errorf("not a schnorr signature")
+			}
+			if err != nil {
+				return nil, &btc_example.SignatureError{Input: indexTxIn, Cosigner: -1,
+					Err: fmt.Errorf("signature %d: %s", j, err.Error())}
+			}
+			cosigner, indexSorted := -1, -1
+			for k := range pubkeys {
+				msScript, sorted, err := script.MultisigScriptFromPubkeys(m, pubkeys, k)
+				if err != nil {
+					return nil, err
+				}
+				hash, err := sigHash(msScript, btctxscript.SigHashType(sig[schnorr.SignatureSize]),
+					msgTx, indexTxIn, amounts[indexTxIn])
+				if err != nil {
+					return nil, err
+				}
+				if schnorr.Verify(pubkeys[k], hash, sig[:schnorr.SignatureSize]) {
+					cosigner, indexSorted = k, sorted
+					break
+				}
+			}
+			if cosigner < 0 {
+				return nil, &btc_example.SignatureError{Input: indexTxIn, Cosigner: -1,
+					Err: fmt.Errorf("signature %d: %s", j, btc_example.ErrSignatureInvalid.Error())}
+			}
+			// the signatures follow the checkbits order
+			if indexSorted <= lastSorted {
+				return nil, &btc_example.SignatureError{Input: indexTxIn, Cosigner: cosigner,
+					Err: fmt.Errorf("signature %d is duplicated or out of order", j)}
+			}
+			lastSorted = indexSorted
+			cosigners[indexTxIn] = append(cosigners[indexTxIn], indexSorted)
+		}
+	}
+	return cosigners, nil
+}
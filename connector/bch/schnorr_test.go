@@ -0,0 +1,73 @@
+package bch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+)
+
+func TestBchChainConnector_TxRebuildVerifiedSchnorr(t *testing.T) {
+	txHex := "0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000"
+	// signer-a, signer-b and signer-c with signers.BchSchnorrTxInputSignature, keys 0, 2 and 1 of the sorted ones
+	signatureA := "42cc995ad72865a45439e69db0aa72e24178be2960f936c22f8a2b3e0f95573bd442127baa962de059919fbc2c14a6c1c199b98170bc501068e60162764017ba41"
+	signatureB := "4914c453f16b3aff4e08cf696dd3806b7aca2c14dd72fb27053970ecb342f804dd36a9bef004f366a819a06734320238910a927c0d54bf5e9c4a26266877f87541"
+	signatureC := "012092d3454536bccd676bd25da09b04f44aac786a35811ac5c71b5a8364c5fdbd5235e253302e6bc15084a582e5b24cca7dca41b132e3eede3fa13e82172c7841"
+	prevOuts := []btc_example.PrevOut{{Amount: 110000000}}
+
+	c := &bchChainConnector{}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive signer-a and signer-c",
+			func(t *testing.T) {
+				want, _ := c.TxRebuildSchnorr(txHex, connector.TxSignatures{{signatureA, signatureC}}, [][]int{{0, 1}})
+
+				got, err := c.TxRebuildVerified(txHex, connector.TxSignatures{{signatureA, signatureC}}, prevOuts)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, want, got, "unexpected tx")
+			},
+		},
+		{
+			"Positive signer-c and signer-b, checkbits 0b110",
+			func(t *testing.T) {
+				got, err := c.TxRebuildVerified(txHex, connector.TxSignatures{{signatureC, signatureB}}, prevOuts)
+				assert.Nil(t, err, "unexpected error")
+				// OP_6 dummy follows the script length
+				assert.Equal(t, "56", got[84:86], "unexpected checkbits")
+			},
+		},
+		{
+			"Negative signatures out of order",
+			func(t *testing.T) {
+				_, err := c.TxRebuildVerified(txHex, connector.TxSignatures{{signatureB, signatureA}}, prevOuts)
+				_, ok := err.(*btc_example.SignatureError)
+				assert.True(t, ok, "unexpected error %v", err)
+				assert.Contains(t, err.Error(), "out of order", "unexpected error")
+			},
+		},
+		{
+			"Negative wrong amount",
+			func(t *testing.T) {
+				_, err := c.TxRebuildVerified(txHex, connector.TxSignatures{{signatureA, signatureC}},
+					[]btc_example.PrevOut{{Amount: 110000001}})
+				sigErr, ok := err.(*btc_example.SignatureError)
+				assert.True(t, ok, "unexpected error %v", err)
+				if ok {
+					assert.Equal(t, -1, sigErr.Cosigner, "unexpected cosigner")
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package bch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+func TestBchChainConnector_ValidateAddress(t *testing.T) {
+	newConnector := func(chainConfig string) btc_example.IBtcChainConnector {
+		c, err := NewChainConnector(1, &connector.WalletParams{Currency: "BCH", ChainConfig: chainConfig}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	regtest := newConnector("regtest")
+	testnet := newConnector("testnet")
+	mainnet := newConnector("")
+
+	tests := []struct {
+		name    string
+		c       btc_example.IBtcChainConnector
+		address string
+		want    bool
+	}{
+		{"regtest cash address", regtest, "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", true},
+		{"regtest cash address without prefix", regtest, "qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye", true},
+		{"regtest legacy address", regtest, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", true},
+		{"mainnet cash address on regtest", regtest, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", false},
+		{"mainnet legacy address on regtest", regtest, "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", false},
+		{"testnet legacy address", testnet, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", true},
+		{"mainnet cash address on testnet", testnet, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", false},
+		{"mainnet cash address", mainnet, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", true},
+		{"mainnet cash P2SH address", mainnet, "bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq", true},
+		{"mainnet legacy address", mainnet, "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", true},
+		{"regtest cash address on mainnet", mainnet, "bchreg:pzhhpwatsrakfklepvsj7jt3e3yq059csqw8u05deg", false},
+		{"testnet legacy address on mainnet", mainnet, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", false},
+		{"invalid checksum", mainnet, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := tt.c.ValidateAddress(tt.address)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.want, valid, "unexpected validation")
+		})
+	}
+
+	assert.Equal(t, netparams.BchMainNetFork.CashAddrPrefix, mainnet.(*bchChainConnector).forkParams().CashAddrPrefix,
+		"mainnet fork expected")
+	assert.Equal(t, netparams.BchTestNetFork.CashAddrPrefix, testnet.(*bchChainConnector).forkParams().CashAddrPrefix,
+		"testnet fork expected")
+	_, err := NewChainConnector(1, nil, 1)
+	assert.NotNil(t, err, "expected error")
+}
+
+func TestDecodeAddress(t *testing.T) {
+	for address, want := range map[string]string{
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a": "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+		"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu":                     "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+		"bchreg:qzu7d73hakh39hc2qqmz2ln73x56hdp04cyfy5q4ye":      "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk",
+		"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX":                     "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX",
+	} {
+		addr, err := DecodeAddress(address)
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, want, addr.EncodeAddress(), "unexpected legacy address of %s", address)
+	}
+	_, err := DecodeAddress("ecash:qpm2qsznhks23z7629mms6s4cwef74vcwva87rkuu2")
+	assert.NotNil(t, err, "expected error for eCash address")
+}
@@ -0,0 +1,26 @@
+// Package bsv provides the BSV connector built on the BCH one.
+package bsv
+
+import (
+	"fmt"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/bch"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// NewChainConnector returns the BSV connector. BSV uses the legacy addresses and does not accept Schnorr signatures.
+// P2SH outputs are not evaluated as P2SH since the Genesis upgrade, so the P2SH addresses are invalid
+// and TxBuild rejects P2SH outputs and change: the multisig wallets are not supported.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (btc_example.IBtcChainConnector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	return bch.NewForkChainConnector(walletID, cfg, txBatchSize, netparams.BsvFork(cfg.ChainConfig))
+}
+
+// NewBlockChainImporter creates the importer of BSV blocks.
+func NewBlockChainImporter(node connector.NodeParams, chainConfig string, txBatchSize int) (connector.BlockChainImporter, error) {
+	return bch.NewForkBlockChainImporter(node, netparams.BsvFork(chainConfig), txBatchSize)
+}
@@ -1,7 +1,10 @@
 package btc_example
 
 import (
+	"fmt"
+
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil/base58"
 )
 
@@ -46,3 +49,25 @@ func (a *CoinAddress) ScriptAddress() []byte {
 func (a *CoinAddress) IsForNet(*chaincfg.Params) bool {
 	return false
 }
+
+// PkScript returns the output script for the legacy P2PKH or P2SH address of BTC mainnet, testnet or regtest format.
+func (a *CoinAddress) PkScript() ([]byte, error) {
+	hash, version, err := base58.CheckDecode(a.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %s", a.Addr, err.Error())
+	}
+	if len(hash) != 20 {
+		return nil, fmt.Errorf("invalid address %s: unexpected hash length %d", a.Addr, len(hash))
+	}
+	builder := txscript.NewScriptBuilder()
+	switch version {
+	case chaincfg.MainNetParams.PubKeyHashAddrID, chaincfg.TestNet3Params.PubKeyHashAddrID:
+		builder.AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(hash).
+			AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG)
+	case chaincfg.MainNetParams.ScriptHashAddrID, chaincfg.TestNet3Params.ScriptHashAddrID:
+		builder.AddOp(txscript.OP_HASH160).AddData(hash).AddOp(txscript.OP_EQUAL)
+	default:
+		return nil, fmt.Errorf("invalid address %s: unknown version %d", a.Addr, version)
+	}
+	return builder.Script()
+}
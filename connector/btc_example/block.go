@@ -0,0 +1,79 @@
+package btc_example
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+// BlockDecoder decodes a serialized block of the chain. The chains which blocks differ from BTC ones
+// (AuxPoW headers, special transactions and so on) convert them into the BTC format.
+type BlockDecoder func(data []byte) (*wire.MsgBlock, error)
+
+// GetRawBlock returns the serialized block (getblock with verbosity 0).
+func GetRawBlock(client *rpcclient.Client, hash *chainhash.Hash) ([]byte, error) {
+	param, err := json.Marshal(hash.String())
+	if err != nil {
+		return nil, err
+	}
+	verbosity, err := json.Marshal(0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.RawRequest("getblock", []json.RawMessage{param, verbosity})
+	if err != nil {
+		return nil, err
+	}
+	var blockHex string
+	if err = json.Unmarshal(resp, &blockHex); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(blockHex)
+}
+
+// DecodeBlock decodes the block in BTC format.
+func DecodeBlock(data []byte) (*wire.MsgBlock, error) {
+	var block wire.MsgBlock
+	if err := block.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// getBlock returns the block by number and its hash as reported by the node, so the chains
+// hashing the headers differently are supported. The decoder is used if set.
+func getBlock(client *rpcclient.Client, number uint64, decoder BlockDecoder) (*chainhash.Hash, *wire.MsgBlock, error) {
+	if client == nil {
+		return nil, nil, connector.ErrClientNil
+	}
+	blockHash, err := client.GetBlockHash(int64(number))
+	if blockHash == nil || err != nil {
+		return nil, nil, connector.ErrNotFound
+	}
+
+	var block *wire.MsgBlock
+	if decoder == nil {
+		block, err = client.GetBlock(blockHash)
+		if block == nil || err != nil {
+			return nil, nil, connector.ErrNotFound
+		}
+		return blockHash, block, nil
+	}
+
+	data, err := GetRawBlock(client, blockHash)
+	if err != nil {
+		return nil, nil, connector.ErrNotFound
+	}
+	block, err = decoder(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("block %d decode: %s", number, err.Error())
+	}
+	return blockHash, block, nil
+}
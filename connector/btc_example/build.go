@@ -0,0 +1,182 @@
+package btc_example
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// rawTxVersion is the version of the transactions created by the node with createrawtransaction.
+const rawTxVersion = 2
+
+// PkScripter is implemented by addresses which are not supported by txscript.PayToAddrScript.
+type PkScripter interface {
+	PkScript() ([]byte, error)
+}
+
+// BuildRawTransaction creates the transaction as createrawtransaction of the node does, without the node.
+// The outputs are ordered by the address.
+func BuildRawTransaction(inputs []btcjson.TransactionInput,
+	amounts map[btcutil.Address]btcutil.Amount) (*wire.MsgTx, error) {
+
+	msg := wire.NewMsgTx(rawTxVersion)
+	for _, input := range inputs {
+		hash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %s: %s", input.Txid, err.Error())
+		}
+		msg.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, input.Vout), nil, nil))
+	}
+
+	addresses := make([]btcutil.Address, 0, len(amounts))
+	for address := range amounts {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].EncodeAddress() < addresses[j].EncodeAddress()
+	})
+	for _, address := range addresses {
+		pkScript, err := PayToAddrScript(address)
+		if err != nil {
+			return nil, err
+		}
+		amount := amounts[address]
+		if amount <= 0 || amount > btcutil.MaxSatoshi {
+			return nil, fmt.Errorf("invalid amount %d for %s", amount, address.EncodeAddress())
+		}
+		msg.AddTxOut(wire.NewTxOut(int64(amount), pkScript))
+	}
+	return msg, nil
+}
+
+// PayToAddrScript returns the output script paying to the address.
+func PayToAddrScript(address btcutil.Address) ([]byte, error) {
+	if scripter, ok := address.(PkScripter); ok {
+		return scripter.PkScript()
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported address %s: %s", address.EncodeAddress(), err.Error())
+	}
+	return pkScript, nil
+}
+
+// TxBuildBtc builds the unsigned multisig transaction locally, the decoder converts the output addresses.
+// rbf signals BIP125 replaceability of the inputs, policy is the standardness policy of the chain.
+// The output of the currency with the token code is the Omni simple send, it follows the coin outputs.
+// omniBalance checks the property balance of the sender, the Omni send fails without it.
+func TxBuildBtc(walletData *connector.WalletSignStruct, utxosIn interface{},
+	output []connector.OutStruct, decoder AddressDecoder, rbf bool, policy netparams.StandardPolicy,
+	omniBalance OmniBalanceGetter) (string, error) {
+
+	n := len(walletData.XPubs)
+	if n < 2 || n > 15 {
+		return "", fmt.Errorf("invalid signers quantity")
+	}
+	m := int(walletData.Signers)
+	if m > n || m < 1 || m > 15 {
+		return "", fmt.Errorf("invalid signers required number")
+	}
+
+	utxos, ok := utxosIn.([]connector.UtxStruct)
+	if !ok {
+		return "", fmt.Errorf("unexpected type of utxo input for BTC wallet: expected []connector.UtxStruct got: %+v", utxosIn)
+	}
+
+	inputs := make([]btcjson.TransactionInput, len(utxos))
+	for i := range utxos {
+		inputs[i] = btcjson.TransactionInput{
+			Txid: utxos[i].TxHash,
+			Vout: uint32(utxos[i].TxPos),
+		}
+	}
+
+	coins, omni, err := splitOmniOutputs(output)
+	if err != nil {
+		return "", err
+	}
+	amounts, err := outputAmounts(coins, decoder)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := BuildRawTransaction(inputs, amounts)
+	if err != nil {
+		return "", err
+	}
+	if err = buildInputScripts(msg, utxos, walletData, rbf); err != nil {
+		return "", err
+	}
+	if omni != nil {
+		if err = addOmniSend(msg, *omni, decoder, policy, omniBalance); err != nil {
+			return "", err
+		}
+	}
+	return unsignedTxHex(msg, policy)
+}
+
+// outputAmounts sums the amounts per address.
+func outputAmounts(output []connector.OutStruct, decoder AddressDecoder) (map[btcutil.Address]btcutil.Amount, error) {
+	values := make(map[string]decimal.Decimal)
+	var val, value decimal.Decimal
+	var ok bool
+	for i := range output {
+		value = output[i].Amount.Abs() // just to copy value
+		val, ok = values[output[i].Address]
+		if ok {
+			value = value.Add(val)
+		}
+		values[output[i].Address] = value
+	}
+
+	amounts := make(map[btcutil.Address]btcutil.Amount)
+	for addr, amt := range values {
+		address, err := decoder(addr)
+		if err != nil {
+			return nil, err
+		}
+		amounts[address] = btcutil.Amount(amt.Mul(decimal.New(1, int32(btcPrecision))).IntPart())
+	}
+	return amounts, nil
+}
+
+// buildInputScripts puts the unsigned scripts into the inputs.
+func buildInputScripts(msg *wire.MsgTx, utxos []connector.UtxStruct, walletData *connector.WalletSignStruct, rbf bool) error {
+	for inputNo := range msg.TxIn {
+		if rbf {
+			msg.TxIn[inputNo].Sequence = SequenceRBF
+		}
+		err := ScriptBuild(msg.TxIn[inputNo], utxos[inputNo].Index, int(walletData.Signers), walletData.XPubs, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unsignedTxHex checks the standardness of the tx with the unsigned input scripts and serializes it.
+func unsignedTxHex(msg *wire.MsgTx, policy netparams.StandardPolicy) (string, error) {
+	err := CheckStandardUnsigned(msg, policy)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	b.Grow(msg.SerializeSize())
+	err = msg.Serialize(&b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b.Bytes()), nil
+}
@@ -0,0 +1,158 @@
+package btc_example
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+)
+
+// the transactions were built by the node with createrawtransaction
+const (
+	buildTxHex1 = "0200000001d71f0514b1f210d374a7d5c1ea4b24bb199eb0bf1990dc9d8ec5252359b8eff600000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000e80300004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000e80300004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a00000000e803000053aeffffffff02e0f83b360000000017a914af70bbab80fb64dbf90b212f4971cc4807d0b8808700e1f505000000001976a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac00000000"
+	buildTxHex2 = "02000000016ba5d03946adc49ba39262a658d432515b98de0fb968849645fd008e12acfbb500000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000000000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000000000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000000000053aeffffffff0240420f00000000001976a9145df78188b31e0136f4a6c6aa570f5c41aa994b8d88ac6043993b000000001976a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac00000000"
+)
+
+func TestTxBuildBtc(t *testing.T) {
+	wallet := &connector.WalletSignStruct{
+		Signers: 2,
+		XPubs: []string{
+			"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+			"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+			"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+		},
+	}
+	btcDecoder := func(addr string) (btcutil.Address, error) {
+		return btcutil.DecodeAddress(addr, &chaincfg.TestNet3Params)
+	}
+	coinDecoder := func(addr string) (btcutil.Address, error) {
+		return &CoinAddress{Addr: addr}, nil
+	}
+	amount := func(s string) decimal.Decimal {
+		d, _ := decimal.NewFromString(s)
+		return d
+	}
+	usdt := Currency{Code: "USDT", Precision: 8, TokenCode: 31}
+
+	tests := []struct {
+		name    string
+		utxos   interface{}
+		output  []connector.OutStruct
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "payment with change",
+			utxos: []connector.UtxStruct{{TxHash: "f6efb8592325c58e9ddc9019bfb09e19bb244beac1d5a774d310f2b114051fd7", TxPos: 0, Index: 1000}},
+			output: []connector.OutStruct{
+				{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: amount("1.0")},
+				{Address: "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT", Amount: amount("9.099")},
+			},
+			want: buildTxHex1,
+		},
+		{
+			name:  "merged outputs to the same address",
+			utxos: []connector.UtxStruct{{TxHash: "b5fbac128e00fd45968468b90fde985b5132d458a66292a39bc4ad4639d0a56b", TxPos: 0, Index: 0}},
+			output: []connector.OutStruct{
+				{Address: "mp5odurSofzh9PZqpvtUen8827K9eYY797", Amount: amount("0.01")},
+				{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: amount("9.0")},
+				{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: amount("0.999")},
+			},
+			want: buildTxHex2,
+		},
+		{
+			name:    "invalid utxo type",
+			utxos:   []connector.UtxoStruct{},
+			wantErr: true,
+		},
+		{
+			name:  "two omni outputs",
+			utxos: []connector.UtxStruct{{TxHash: "b5fbac128e00fd45968468b90fde985b5132d458a66292a39bc4ad4639d0a56b"}},
+			output: []connector.OutStruct{
+				{Address: "mp5odurSofzh9PZqpvtUen8827K9eYY797", Amount: amount("1"), Currency: usdt},
+				{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: amount("1"), Currency: usdt},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "omni amount below the precision",
+			utxos:   []connector.UtxStruct{{TxHash: "b5fbac128e00fd45968468b90fde985b5132d458a66292a39bc4ad4639d0a56b"}},
+			output:  []connector.OutStruct{{Address: "mp5odurSofzh9PZqpvtUen8827K9eYY797", Amount: amount("0.000000001"), Currency: usdt}},
+			wantErr: true,
+		},
+		{
+			name:    "zero amount",
+			utxos:   []connector.UtxStruct{{TxHash: "b5fbac128e00fd45968468b90fde985b5132d458a66292a39bc4ad4639d0a56b"}},
+			output:  []connector.OutStruct{{Address: "mp5odurSofzh9PZqpvtUen8827K9eYY797", Amount: amount("0")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		for _, decoder := range []AddressDecoder{btcDecoder, coinDecoder} {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := TxBuildBtc(wallet, tt.utxos, tt.output, decoder, false, netparams.BtcStandardPolicy, nil)
+				if tt.wantErr {
+					assert.NotNil(t, err, "error expected")
+					return
+				}
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, tt.want, got, "unexpected tx")
+			})
+		}
+	}
+
+	// RBF signalling changes only the sequences
+	got, err := TxBuildBtc(wallet, tests[0].utxos, tests[0].output, btcDecoder, true, netparams.BtcStandardPolicy, nil)
+	assert.Nil(t, err, "unexpected error")
+	msgTx, _ := decodeTx(got)
+	assert.Equal(t, uint32(SequenceRBF), msgTx.TxIn[0].Sequence, "unexpected sequence")
+	msgTx.TxIn[0].Sequence = wire.MaxTxInSequenceNum
+	want, _ := decodeTx(buildTxHex1)
+	assert.Equal(t, want, msgTx, "unexpected tx")
+
+	// the omni simple send follows the coin outputs, the recipient is the last output
+	omniOutput := []connector.OutStruct{
+		{Address: "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT", Amount: amount("9.099")},
+		{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: amount("1.5"), Currency: usdt},
+	}
+	var sender []byte
+	balance := func(pkScript []byte, property uint32) (int64, error) {
+		sender = pkScript
+		assert.Equal(t, uint32(31), property, "unexpected property")
+		return 150000000, nil
+	}
+	got, err = TxBuildBtc(wallet, tests[0].utxos, omniOutput, btcDecoder, false, netparams.BtcStandardPolicy, balance)
+	assert.Nil(t, err, "unexpected error")
+	msgTx, _ = decodeTx(got)
+	if assert.Len(t, msgTx.TxOut, 3, "unexpected outputs") {
+		assert.Equal(t, "6a146f6d6e69000000000000001f0000000008f0d180", hex.EncodeToString(msgTx.TxOut[1].PkScript), "unexpected payload")
+		assert.Equal(t, int64(0), msgTx.TxOut[1].Value, "unexpected payload value")
+		assert.Equal(t, "76a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac", hex.EncodeToString(msgTx.TxOut[2].PkScript), "unexpected recipient")
+		assert.Equal(t, int64(546), msgTx.TxOut[2].Value, "unexpected recipient value")
+	}
+	// the balance is of the wallet address of the input
+	input, _ := InputPkScript(msgTx, 0)
+	assert.Equal(t, input, sender, "unexpected sender")
+
+	// the sender does not hold the amount
+	_, err = TxBuildBtc(wallet, tests[0].utxos, omniOutput, btcDecoder, false, netparams.BtcStandardPolicy,
+		func(pkScript []byte, property uint32) (int64, error) {
+			return 149999999, nil
+		})
+	assert.NotNil(t, err, "expected error for the insufficient balance")
+	_, err = TxBuildBtc(wallet, tests[0].utxos, omniOutput, btcDecoder, false, netparams.BtcStandardPolicy, nil)
+	assert.NotNil(t, err, "expected error for the unknown balance")
+	// Omni Core would take the sender of the largest input
+	_, err = TxBuildBtc(wallet, []connector.UtxStruct{
+		{TxHash: "f6efb8592325c58e9ddc9019bfb09e19bb244beac1d5a774d310f2b114051fd7", TxPos: 0, Index: 1000},
+		{TxHash: "b5fbac128e00fd45968468b90fde985b5132d458a66292a39bc4ad4639d0a56b", TxPos: 0, Index: 0},
+	}, omniOutput, btcDecoder, false, netparams.BtcStandardPolicy, balance)
+	assert.NotNil(t, err, "expected error for the inputs of two addresses")
+}
@@ -0,0 +1,212 @@
+package btc_example
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example/script"
+	"github.com/stanche/crypto-interface/netparams"
+	"github.com/stanche/crypto-interface/txsize"
+)
+
+const (
+	// SequenceRBF signals BIP125 replaceability of the input.
+	SequenceRBF = wire.MaxTxInSequenceNum - 2
+
+	// minRelayFeeRate is the default incremental relay fee of the nodes in satoshi per byte.
+	minRelayFeeRate = 1
+)
+
+const (
+	// BumpRBF replaces the transaction with one paying a higher fee from the change.
+	BumpRBF BumpMethod = iota
+	// BumpCPFP spends the change of the transaction with a child paying for both.
+	BumpCPFP
+)
+
+type (
+	// BumpMethod defines the way TxBump speeds up a transaction.
+	BumpMethod int
+
+	// TxBumpParams defines the transaction to speed up.
+	TxBumpParams struct {
+		Method BumpMethod
+		// Amounts of the outputs spent by the transaction in satoshi, in the order of inputs.
+		Amounts []int64
+		// ChangeOut is the output of the transaction returning to the wallet.
+		ChangeOut int
+		// ChangeIndex is the wallet address index of the change output, it's required for BumpCPFP.
+		ChangeIndex uint32
+		// FeeRate is the target fee rate in satoshi per byte, for BumpCPFP it's the rate of both transactions.
+		FeeRate int64
+	}
+)
+
+// RBFSet enables BIP125 replaceability of the transactions built with TxBuild.
+func (bcc *BtcChainConnector) RBFSet(enable bool) {
+	bcc.rbf = enable
+}
+
+// TxBump builds an unsigned transaction speeding up the one of txHex, the result goes through Sign and TxRebuild.
+// For BumpRBF txHex is the unsigned transaction from TxBuild, it's replaced by one with the same inputs and outputs
+// where the change pays the additional fee. For BumpCPFP txHex is the broadcasted parent, the child spends
+// its change back to the same address.
+func (bcc *BtcChainConnector) TxBump(walletData *connector.WalletSignStruct, txHex string, params TxBumpParams) (string, error) {
+	return TxBumpBtc(walletData, txHex, params, bcc.standard())
+}
+
+// TxBumpBtc builds the unsigned transaction for TxBump, policy is the standardness policy of the chain.
+func TxBumpBtc(walletData *connector.WalletSignStruct, txHex string, params TxBumpParams, policy netparams.StandardPolicy) (string, error) {
+	msgTx, err := decodeTx(txHex)
+	if err != nil {
+		return "", err
+	}
+	if len(params.Amounts) != len(msgTx.TxIn) {
+		return "", fmt.Errorf("inconsistnence amounts (%d) and countTxIn(%d)", len(params.Amounts), len(msgTx.TxIn))
+	}
+	if params.ChangeOut < 0 || params.ChangeOut >= len(msgTx.TxOut) {
+		return "", fmt.Errorf("invalid change output %d", params.ChangeOut)
+	}
+	if params.FeeRate <= 0 {
+		return "", fmt.Errorf("invalid fee rate %d", params.FeeRate)
+	}
+	var inputTotal, outputTotal int64
+	for _, amount := range params.Amounts {
+		inputTotal += amount
+	}
+	for _, txOut := range msgTx.TxOut {
+		outputTotal += txOut.Value
+	}
+	fee := inputTotal - outputTotal
+	if fee < 0 {
+		return "", fmt.Errorf("outputs (%d) exceed inputs (%d)", outputTotal, inputTotal)
+	}
+
+	var bumped *wire.MsgTx
+	switch params.Method {
+	case BumpRBF:
+		bumped, err = replacementTx(msgTx, fee, params, policy)
+	case BumpCPFP:
+		bumped, err = childTx(walletData, msgTx, fee, params, policy)
+	default:
+		err = fmt.Errorf("unknown bump method %d", params.Method)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	b.Grow(bumped.SerializeSize())
+	if err = bumped.Serialize(&b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b.Bytes()), nil
+}
+
+// replacementTx pays the higher fee from the change of the unsigned tx.
+func replacementTx(msgTx *wire.MsgTx, fee int64, params TxBumpParams, policy netparams.StandardPolicy) (*wire.MsgTx, error) {
+	bumped := msgTx.Copy()
+	for _, txIn := range bumped.TxIn {
+		if txIn.Sequence > SequenceRBF {
+			txIn.Sequence = SequenceRBF
+		}
+	}
+	size, err := signedSize(bumped)
+	if err != nil {
+		return nil, err
+	}
+	// BIP125 requires the replacement to pay for its own relay on top of the original fee
+	newFee := params.FeeRate * int64(size)
+	if minFee := fee + minRelayFeeRate*int64(size); newFee < minFee {
+		newFee = minFee
+	}
+	change := bumped.TxOut[params.ChangeOut]
+	change.Value -= newFee - fee
+	if change.Value <= 0 {
+		return nil, fmt.Errorf("change %d is not enough for fee %d", change.Value+newFee-fee, newFee)
+	}
+	return bumped, CheckStandardUnsigned(bumped, policy)
+}
+
+// childTx spends the change of the signed parent to the same script.
+func childTx(walletData *connector.WalletSignStruct, parent *wire.MsgTx, parentFee int64, params TxBumpParams,
+	policy netparams.StandardPolicy) (*wire.MsgTx, error) {
+	if walletData == nil {
+		return nil, fmt.Errorf("wallet is absent")
+	}
+	if txscript.GetScriptClass(parent.TxOut[params.ChangeOut].PkScript) != txscript.ScriptHashTy {
+		return nil, fmt.Errorf("change output %d is not P2SH", params.ChangeOut)
+	}
+	parentSize := parent.SerializeSize()
+	change := parent.TxOut[params.ChangeOut]
+
+	child := wire.NewMsgTx(rawTxVersion)
+	parentHash := parent.TxHash()
+	prevOut := wire.NewOutPoint(&parentHash, uint32(params.ChangeOut))
+	txIn := wire.NewTxIn(prevOut, nil, nil)
+	txIn.Sequence = SequenceRBF
+	child.AddTxIn(txIn)
+	child.AddTxOut(wire.NewTxOut(change.Value, change.PkScript))
+
+	err := ScriptBuild(txIn, params.ChangeIndex, int(walletData.Signers), walletData.XPubs, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the input spends the change only if the wallet at the index owns it
+	pkScript, err := InputPkScript(child, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pkScript, change.PkScript) {
+		return nil, fmt.Errorf("change output %d does not belong to the wallet index %d", params.ChangeOut, params.ChangeIndex)
+	}
+	childSize, err := signedSize(child)
+	if err != nil {
+		return nil, err
+	}
+	childFee := params.FeeRate*int64(parentSize+childSize) - parentFee
+	if minFee := minRelayFeeRate * int64(childSize); childFee < minFee {
+		childFee = minFee
+	}
+	child.TxOut[0].Value -= childFee
+	if child.TxOut[0].Value <= 0 {
+		return nil, fmt.Errorf("change %d is not enough for fee %d", change.Value, childFee)
+	}
+	return child, CheckStandardUnsigned(child, policy)
+}
+
+// InputPkScript returns the P2SH script of the multisig redeem script of the unsigned input.
+func InputPkScript(msgTx *wire.MsgTx, idx int) ([]byte, error) {
+	m, pubkeys, err := inputPubkeys(msgTx, idx)
+	if err != nil {
+		return nil, fmt.Errorf("input %d: %s", idx, err.Error())
+	}
+	msScript, _, err := script.MultisigScriptFromPubkeys(m, pubkeys, 0)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(msScript)).AddOp(txscript.OP_EQUAL).
+		Script()
+}
+
+// signedSize estimates the size of the transaction with all the unsigned inputs signed.
+func signedSize(msgTx *wire.MsgTx) (int, error) {
+	size := msgTx.SerializeSize()
+	for idx, txIn := range msgTx.TxIn {
+		m, pubkeys, err := inputPubkeys(msgTx, idx)
+		if err != nil {
+			return 0, fmt.Errorf("input %d: %s", idx, err.Error())
+		}
+		scriptSize := txsize.ScriptSigSize(int(m), len(pubkeys))
+		size += scriptSize + wire.VarIntSerializeSize(uint64(scriptSize)) -
+			len(txIn.SignatureScript) - wire.VarIntSerializeSize(uint64(len(txIn.SignatureScript)))
+	}
+	return size, nil
+}
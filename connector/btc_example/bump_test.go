@@ -0,0 +1,120 @@
+package btc_example
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+
+	btcaddress "github.com/stanche/crypto-interface/address/btc_example"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxBump(t *testing.T) {
+	wallet := &connector.WalletSignStruct{
+		Signers: 2,
+		XPubs: []string{
+			"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+			"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+			"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+		},
+	}
+	// the tx spends 66600000 to a single output of 66598300
+	amounts := []int64{66600000}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive replacing by fee",
+			func(t *testing.T) {
+				bumped, err := TxBumpBtc(wallet, verifyTxHex, TxBumpParams{Method: BumpRBF, Amounts: amounts, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.Nil(t, err, "unexpected error")
+
+				msgTx, err := decodeTx(bumped)
+				assert.Nil(t, err, "unexpected error")
+				original, _ := decodeTx(verifyTxHex)
+				assert.Equal(t, original.TxIn[0].SignatureScript, msgTx.TxIn[0].SignatureScript, "unsigned script shall be kept")
+				assert.Equal(t, uint32(SequenceRBF), msgTx.TxIn[0].Sequence, "unexpected sequence")
+				assert.Equal(t, original.TxOut[0].PkScript, msgTx.TxOut[0].PkScript, "unexpected output")
+				// 341 bytes signed at 10 satoshi per byte
+				assert.Equal(t, int64(66600000-3410), msgTx.TxOut[0].Value, "unexpected change")
+
+				// the replacement of the replacement pays at least the relay fee more
+				again, err := TxBumpBtc(wallet, bumped, TxBumpParams{Method: BumpRBF, Amounts: amounts, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.Nil(t, err, "unexpected error")
+				msgTx, _ = decodeTx(again)
+				assert.Equal(t, int64(66600000-3410-341), msgTx.TxOut[0].Value, "unexpected change")
+			},
+		},
+		{
+			"Positive paying for parent",
+			func(t *testing.T) {
+				// the parent returns its output to the wallet index 2 the input is spent from
+				parent, _ := decodeTx(verifyTxHex)
+				address, _ := btcaddress.New().AddressGenerate(hd.GeneratorParameters{
+					SignersXpubs: wallet.XPubs, SignersRequired: wallet.Signers, PathIndex: 2,
+				})
+				changeAddress, _ := btcutil.DecodeAddress(address, &chaincfg.TestNet3Params)
+				parent.TxOut[0].PkScript, _ = txscript.PayToAddrScript(changeAddress)
+				var b bytes.Buffer
+				_ = parent.Serialize(&b)
+				parentHex := hex.EncodeToString(b.Bytes())
+
+				childHex, err := TxBumpBtc(wallet, parentHex, TxBumpParams{Method: BumpCPFP, Amounts: amounts, ChangeIndex: 2, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.Nil(t, err, "unexpected error")
+				child, err := decodeTx(childHex)
+				assert.Nil(t, err, "unexpected error")
+
+				assert.Equal(t, parent.TxHash(), child.TxIn[0].PreviousOutPoint.Hash, "unexpected parent")
+				assert.Equal(t, uint32(0), child.TxIn[0].PreviousOutPoint.Index, "unexpected parent output")
+				_, pubkeys, err := inputPubkeys(child, 0)
+				assert.Nil(t, err, "child input shall be unsigned")
+				assert.Len(t, pubkeys, 3, "unexpected cosigners")
+
+				childSize, _ := signedSize(child)
+				fee := int64(10*(parent.SerializeSize()+childSize) - 1700)
+				assert.Equal(t, parent.TxOut[0].Value-fee, child.TxOut[0].Value, "unexpected child output")
+				assert.Equal(t, parent.TxOut[0].PkScript, child.TxOut[0].PkScript, "unexpected child script")
+
+				// the change of the other index does not belong to the wallet
+				_, err = TxBumpBtc(wallet, parentHex, TxBumpParams{Method: BumpCPFP, Amounts: amounts, ChangeIndex: 3, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.NotNil(t, err, "error expected for the other index")
+				other := &connector.WalletSignStruct{Signers: 1, XPubs: wallet.XPubs}
+				_, err = TxBumpBtc(other, parentHex, TxBumpParams{Method: BumpCPFP, Amounts: amounts, ChangeIndex: 2, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.NotNil(t, err, "error expected for the other wallet")
+
+				// the payout of the original tx is not the change
+				_, err = TxBumpBtc(wallet, verifyTxHex, TxBumpParams{Method: BumpCPFP, Amounts: amounts, ChangeIndex: 2, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.NotNil(t, err, "error expected for the payout")
+			},
+		},
+		{
+			"Negative bumping with insufficient change",
+			func(t *testing.T) {
+				_, err := TxBumpBtc(wallet, verifyTxHex, TxBumpParams{Method: BumpRBF, Amounts: amounts, FeeRate: 1000000}, netparams.BtcStandardPolicy)
+				assert.NotNil(t, err, "error expected")
+
+				_, err = TxBumpBtc(wallet, verifyTxHex, TxBumpParams{Method: BumpRBF, Amounts: nil, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.NotNil(t, err, "error expected")
+
+				_, err = TxBumpBtc(wallet, verifyTxHex, TxBumpParams{Method: BumpRBF, Amounts: amounts, ChangeOut: 1, FeeRate: 10}, netparams.BtcStandardPolicy)
+				assert.NotNil(t, err, "error expected")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
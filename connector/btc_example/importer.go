@@ -18,6 +18,20 @@ type (
 		client      *rpcclient.Client
 		chainParams chaincfg.Params
 		txBatchSize int
+		options     ImporterOptions
+	}
+
+	// OutputsParser extracts the output addresses in the format of the wallet addresses.
+	OutputsParser func(txOuts []*wire.TxOut) ([]*connector.OutputParsed, error)
+
+	// ImporterOptions defines the specifics of a BTC-like chain for the importer.
+	ImporterOptions struct {
+		// DecodeBlock decodes the blocks which differ from BTC format.
+		DecodeBlock BlockDecoder
+		// ParseOutputs replaces the parsing of the outputs with the chain params.
+		ParseOutputs OutputsParser
+		// OmniTransaction confirms the Omni sends, omni_gettransaction of the node by default.
+		OmniTransaction OmniTransactionGetter
 	}
 
 	// outputParsed - describes return of TxParse
@@ -25,6 +39,7 @@ type (
 		Address string
 		Value   *big.Int
 		TxPos   uint
+		Token   *connector.TokenParsed
 	}
 
 	// processTxData is used for processTransaction func as param
@@ -32,8 +47,11 @@ type (
 		block       *wire.MsgBlock
 		txMsg       *wire.MsgTx
 		blockNumber uint64
-		currency    connector.Currency
-		addresses   connector.AddressLister
+		// currency is the coin or the token with the address, nil if only omni properties are imported.
+		currency  connector.Currency
+		omni      []connector.Currency
+		omniTx    OmniTransactionGetter
+		addresses connector.AddressLister
 	}
 
 	// processTxData is used for processTransaction func as return
@@ -43,10 +61,16 @@ type (
 	}
 )
 
-var ErrBadCurrenciesCount = fmt.Errorf("bad currencies count provided: Bitcoin import was only supporting one currency BTC")
+var ErrBadCurrenciesCount = fmt.Errorf("bad currencies count provided: Bitcoin import supports one coin currency and Omni properties")
 
 // NewBlockChainImporter creates new instance of importer.BlockChainImporter as BtcBlockChainImporter
 func NewBlockChainImporter(node connector.NodeParams, chainParams chaincfg.Params, txBatchSize int) (connector.BlockChainImporter, error) {
+	return NewBlockChainImporterWithOptions(node, chainParams, txBatchSize, ImporterOptions{})
+}
+
+// NewBlockChainImporterWithOptions creates the importer of a BTC-like chain with the chain specifics.
+func NewBlockChainImporterWithOptions(node connector.NodeParams, chainParams chaincfg.Params, txBatchSize int,
+	options ImporterOptions) (connector.BlockChainImporter, error) {
 	cl, err := rpcclient.New(&rpcclient.ConnConfig{
 		Host:         fmt.Sprintf("%s:%d", node.GetHost(), node.GetPort()),
 		User:         node.GetUser(),
@@ -65,48 +89,52 @@ func NewBlockChainImporter(node connector.NodeParams, chainParams chaincfg.Param
 		client:      cl,
 		chainParams: chainParams,
 		txBatchSize: txBatchSize,
+		options:     options,
 	}, nil
 }
 
 // getBlockByNumber returns btcd/wire MsgBlock as well
 func (bci BtcBlockChainImporter) getBlockByNumber(number uint64) (block *wire.MsgBlock, err error) {
-	if bci.client == nil {
-		return nil, connector.ErrClientNil
-	}
-
-	blockHash, err := bci.client.GetBlockHash(int64(number))
-	if blockHash == nil || err != nil {
-		return nil, connector.ErrNotFound
-	}
-
-	block, err = bci.client.GetBlock(blockHash)
-	if block == nil || err != nil {
-		return nil, connector.ErrNotFound
-	}
-
-	return block, nil
+	_, block, err = getBlock(bci.client, number, bci.options.DecodeBlock)
+	return block, err
 }
 
 // GetBlockHashesByNumber returns block hash and previous block gash as strings
 func (bci BtcBlockChainImporter) GetBlockHashesByNumber(number uint64) (hash, prevHash string, err error) {
-	block, err := bci.getBlockByNumber(number)
+	blockHash, block, err := getBlock(bci.client, number, bci.options.DecodeBlock)
 	if err != nil {
 		return "", "", err
 	}
-	return block.Header.BlockHash().String(), block.Header.PrevBlock.String(), nil
+	return blockHash.String(), block.Header.PrevBlock.String(), nil
 }
 
 // ProcessBlock do all importer logic and returns operations with given addresses list included in a given block
 func (bci BtcBlockChainImporter) ProcessBlock(blockNumber uint64, currencies []connector.Currency, addresses connector.AddressLister) (operations []connector.Operation, err error) {
-	//BTC importer only supports one currency at all
-	if len(currencies) != 1 || strings.EqualFold(currencies[0].GetCode(), "BTC") {
+	// one coin currency at most, the others are Omni properties
+	if len(currencies) == 0 {
 		return operations, ErrBadCurrenciesCount
 	}
+	var coin connector.Currency
+	var omni []connector.Currency
+	for _, currency := range currencies {
+		if isOmni(currency) {
+			omni = append(omni, currency)
+			continue
+		}
+		if coin != nil {
+			return operations, ErrBadCurrenciesCount
+		}
+		coin = currency
+	}
 
 	block, err := bci.getBlockByNumber(blockNumber)
 	if err != nil {
 		return operations, err
 	}
+	omniTx := bci.options.OmniTransaction
+	if omniTx == nil {
+		omniTx = GetOmniTransaction(bci.client)
+	}
 	// Scan all transactions inside a block
 	i := 0
 	txCount := len(block.Transactions)
@@ -124,7 +152,9 @@ func (bci BtcBlockChainImporter) ProcessBlock(blockNumber uint64, currencies []c
 					block:       block,
 					txMsg:       txtoProcess,
 					blockNumber: blockNumber,
-					currency:    currencies[0],
+					currency:    coin,
+					omni:        omni,
+					omniTx:      omniTx,
 					addresses:   addresses,
 				})
 			}()
@@ -153,21 +183,37 @@ func (bci BtcBlockChainImporter) ProcessBlock(blockNumber uint64, currencies []c
 	return operations, nil
 }
 
-// processTransaction returns operations on given addresses list, which included in transaction
+// processTransaction returns operations on given addresses list, which included in transaction.
+// For the token currency the operations are the fungible token amounts of its category (token address).
+// The Omni operations have the currency code of the property.
 func (bci BtcBlockChainImporter) processTransaction(d processTxData) processTxResponse {
 	parsedOutputs, err := bci.parseOutputs(d.txMsg.TxOut)
 	if err != nil {
 		return processTxResponse{ops: nil, err: fmt.Errorf("btc processTransaction.ParseOutputs %s : %v", d.txMsg.TxHash(), err.Error())}
 	}
 
-	var operations []connector.Operation
+	operations, err := omniOperations(d, parsedOutputs)
+	if err != nil {
+		return processTxResponse{ops: nil, err: err}
+	}
+	if d.currency == nil {
+		return processTxResponse{ops: operations}
+	}
+	tokenAddress := d.currency.GetTokenAddress()
 	for _, output := range parsedOutputs {
+		value := output.Value
+		if tokenAddress != "" {
+			if output.Token == nil || !strings.EqualFold(output.Token.Category, tokenAddress) || output.Token.Amount.Sign() == 0 {
+				continue
+			}
+			value = output.Token.Amount
+		}
 		if d.addresses.HasAddress(output.Address, "") {
 			operations = append(operations, connector.Operation{
 				TxId:      d.txMsg.TxHash().String(),
 				TxOut:     output.TxPos,
 				ToAddress: output.Address,
-				Amount:    decimal.NewFromBigInt(output.Value, -int32(d.currency.GetPrecision())),
+				Amount:    decimal.NewFromBigInt(value, -int32(d.currency.GetPrecision())),
 			})
 		}
 	}
@@ -178,6 +224,17 @@ func (bci BtcBlockChainImporter) processTransaction(d processTxData) processTxRe
 // parseOutputs parses all BTC outputs and returns in convenient format outputParsed
 func (bci BtcBlockChainImporter) parseOutputs(txOuts []*wire.TxOut) ([]outputParsed, error) {
 	var outputs []outputParsed
+	if bci.options.ParseOutputs != nil {
+		parsed, err := bci.options.ParseOutputs(txOuts)
+		if err != nil {
+			return nil, err
+		}
+		for _, output := range parsed {
+			outputs = append(outputs, outputParsed(*output))
+		}
+		return outputs, nil
+	}
+
 	for i, txOut := range txOuts {
 		_, addresses, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, &bci.chainParams)
 		if err != nil {
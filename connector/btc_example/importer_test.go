@@ -0,0 +1,261 @@
+package btc_example
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+type addressList map[string]struct{}
+
+func (l addressList) HasAddress(address, _ string) bool {
+	_, ok := l[address]
+	return ok
+}
+
+func TestBtcBlockChainImporter_processTransaction(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(5000, mustPkScript(t, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX")))
+	tx.AddTxOut(wire.NewTxOut(7000, mustPkScript(t, "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT")))
+	currency := Currency{Code: "LTC", Precision: 8}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive outputs parsed with the chain params",
+			func(t *testing.T) {
+				bci := BtcBlockChainImporter{chainParams: chaincfg.TestNet3Params}
+				resp := bci.processTransaction(processTxData{
+					txMsg:     tx,
+					currency:  currency,
+					addresses: addressList{"2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Equal(t, []connector.Operation{{
+					TxId:      tx.TxHash().String(),
+					TxOut:     1,
+					ToAddress: "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT",
+					Amount:    decimal.New(7000, -8),
+				}}, resp.ops, "unexpected operations")
+			},
+		},
+		{
+			"Positive outputs parsed with the chain parser",
+			func(t *testing.T) {
+				bci := BtcBlockChainImporter{options: ImporterOptions{
+					ParseOutputs: func(txOuts []*wire.TxOut) ([]*connector.OutputParsed, error) {
+						return []*connector.OutputParsed{{Address: "cash:0", Value: big.NewInt(txOuts[0].Value), TxPos: 0}}, nil
+					},
+				}}
+				resp := bci.processTransaction(processTxData{
+					txMsg:     tx,
+					currency:  currency,
+					addresses: addressList{"cash:0": {}, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Len(t, resp.ops, 1, "unexpected operations")
+				assert.Equal(t, "cash:0", resp.ops[0].ToAddress, "unexpected address")
+			},
+		},
+		{
+			"Positive token amounts of the currency category",
+			func(t *testing.T) {
+				category := "7d2ee2a55f2ea35e0ae8e1c5e37c1bc0a5a0cbcc9a4b1b0df1e8a7a84d9db4f4"
+				bci := BtcBlockChainImporter{options: ImporterOptions{
+					ParseOutputs: func(txOuts []*wire.TxOut) ([]*connector.OutputParsed, error) {
+						return []*connector.OutputParsed{
+							{Address: "cash:0", Value: big.NewInt(1000), TxPos: 0,
+								Token: &connector.TokenParsed{Category: category, Amount: big.NewInt(250)}},
+							{Address: "cash:0", Value: big.NewInt(1000), TxPos: 1,
+								Token: &connector.TokenParsed{Category: category, Amount: new(big.Int), Capability: "none"}},
+							{Address: "cash:0", Value: big.NewInt(1000), TxPos: 2,
+								Token: &connector.TokenParsed{Category: "00" + category[2:], Amount: big.NewInt(7)}},
+							{Address: "cash:0", Value: big.NewInt(5000), TxPos: 3},
+						}, nil
+					},
+				}}
+				resp := bci.processTransaction(processTxData{
+					txMsg:     tx,
+					currency:  Currency{Code: "TKN", Precision: 2, TokenAddress: category},
+					addresses: addressList{"cash:0": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Equal(t, []connector.Operation{{
+					TxId:      tx.TxHash().String(),
+					TxOut:     0,
+					ToAddress: "cash:0",
+					Amount:    decimal.New(250, -2),
+				}}, resp.ops, "unexpected operations")
+
+				resp = bci.processTransaction(processTxData{
+					txMsg:     tx,
+					currency:  currency,
+					addresses: addressList{"cash:0": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Len(t, resp.ops, 4, "coin operations expected for all outputs")
+			},
+		},
+		{
+			"Positive omni simple send to the last output",
+			func(t *testing.T) {
+				nullData, _ := OmniSimpleSendScript(OmniSend{Property: 31, Amount: 150000000})
+				omniTx := wire.NewMsgTx(2)
+				omniTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 0), nil, nil))
+				omniTx.AddTxOut(wire.NewTxOut(7000, mustPkScript(t, "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT")))
+				omniTx.AddTxOut(wire.NewTxOut(0, nullData))
+				omniTx.AddTxOut(wire.NewTxOut(546, mustPkScript(t, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX")))
+				usdt := Currency{Code: "USDT", Precision: 8, TokenCode: 31}
+				omniTxGetter := func(txID string) (*OmniTransaction, error) {
+					assert.Equal(t, omniTx.TxHash().String(), txID, "unexpected txid")
+					return &OmniTransaction{TxID: txID, SendingAddress: "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT",
+						ReferenceAddress: "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", PropertyID: 31, Valid: true}, nil
+				}
+
+				bci := BtcBlockChainImporter{chainParams: chaincfg.TestNet3Params}
+				resp := bci.processTransaction(processTxData{
+					txMsg:     omniTx,
+					currency:  Currency{Code: "BTC", Precision: 8},
+					omni:      []connector.Currency{usdt, Currency{Code: "MAID", TokenCode: 3}},
+					omniTx:    omniTxGetter,
+					addresses: addressList{"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Equal(t, []connector.Operation{
+					{
+						TxId:         omniTx.TxHash().String(),
+						TxOut:        2,
+						ToAddress:    "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX",
+						CurrencyCode: "USDT",
+						Amount:       decimal.New(150000000, -8),
+					},
+					{
+						TxId:      omniTx.TxHash().String(),
+						TxOut:     2,
+						ToAddress: "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX",
+						Amount:    decimal.New(546, -8),
+					},
+				}, resp.ops, "unexpected operations")
+
+				// the recipient of the payload is not watched
+				resp = bci.processTransaction(processTxData{
+					txMsg:     omniTx,
+					omni:      []connector.Currency{usdt},
+					omniTx:    omniTxGetter,
+					addresses: addressList{"2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Empty(t, resp.ops, "unexpected operations")
+
+				// the send is not confirmed without Omni Core
+				resp = bci.processTransaction(processTxData{
+					txMsg:     omniTx,
+					omni:      []connector.Currency{usdt},
+					omniTx:    GetOmniTransaction(nil),
+					addresses: addressList{"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX": {}},
+				})
+				assert.NotNil(t, resp.err, "expected error")
+			},
+		},
+		{
+			"Negative forged omni simple send",
+			func(t *testing.T) {
+				// the sender does not hold the property, Omni Core marks the send invalid
+				nullData, _ := OmniSimpleSendScript(OmniSend{Property: 31, Amount: 100000000000})
+				forgedTx := wire.NewMsgTx(2)
+				forgedTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{3}, 0), nil, nil))
+				forgedTx.AddTxOut(wire.NewTxOut(0, nullData))
+				forgedTx.AddTxOut(wire.NewTxOut(546, mustPkScript(t, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX")))
+
+				bci := BtcBlockChainImporter{chainParams: chaincfg.TestNet3Params}
+				resp := bci.processTransaction(processTxData{
+					txMsg: forgedTx,
+					omni:  []connector.Currency{Currency{Code: "USDT", Precision: 8, TokenCode: 31}},
+					omniTx: func(txID string) (*OmniTransaction, error) {
+						return &OmniTransaction{TxID: txID, SendingAddress: "mp5odurSofzh9PZqpvtUen8827K9eYY797",
+							ReferenceAddress: "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", PropertyID: 31, Valid: false}, nil
+					},
+					addresses: addressList{"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Empty(t, resp.ops, "unexpected operations")
+			},
+		},
+		{
+			"Positive omni simple send with the change of the sender last",
+			func(t *testing.T) {
+				nullData, _ := OmniSimpleSendScript(OmniSend{Property: 31, Amount: 150000000})
+				omniTx := wire.NewMsgTx(2)
+				omniTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{4}, 0), nil, nil))
+				omniTx.AddTxOut(wire.NewTxOut(546, mustPkScript(t, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX")))
+				omniTx.AddTxOut(wire.NewTxOut(0, nullData))
+				omniTx.AddTxOut(wire.NewTxOut(7000, mustPkScript(t, "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT")))
+				usdt := Currency{Code: "USDT", Precision: 8, TokenCode: 31}
+				omniTxGetter := func(txID string) (*OmniTransaction, error) {
+					return &OmniTransaction{TxID: txID, SendingAddress: "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT",
+						ReferenceAddress: "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", PropertyID: 31, Valid: true}, nil
+				}
+
+				bci := BtcBlockChainImporter{chainParams: chaincfg.TestNet3Params}
+				resp := bci.processTransaction(processTxData{
+					txMsg:     omniTx,
+					omni:      []connector.Currency{usdt},
+					omniTx:    omniTxGetter,
+					addresses: addressList{"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX": {}, "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Equal(t, []connector.Operation{{
+					TxId:         omniTx.TxHash().String(),
+					TxOut:        0,
+					ToAddress:    "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX",
+					CurrencyCode: "USDT",
+					Amount:       decimal.New(150000000, -8),
+				}}, resp.ops, "unexpected operations")
+
+				// the change of the sender is not credited
+				resp = bci.processTransaction(processTxData{
+					txMsg:     omniTx,
+					omni:      []connector.Currency{usdt},
+					omniTx:    omniTxGetter,
+					addresses: addressList{"2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT": {}},
+				})
+				assert.Nil(t, resp.err, "unexpected error")
+				assert.Empty(t, resp.ops, "unexpected operations")
+			},
+		},
+		{
+			"Negative more than one coin currency",
+			func(t *testing.T) {
+				_, err := BtcBlockChainImporter{}.ProcessBlock(1, []connector.Currency{currency, currency}, addressList{})
+				assert.Equal(t, ErrBadCurrenciesCount, err, "unexpected error")
+				_, err = BtcBlockChainImporter{}.ProcessBlock(1, nil, addressList{})
+				assert.Equal(t, ErrBadCurrenciesCount, err, "unexpected error")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func mustPkScript(t *testing.T, address string) []byte {
+	addr, err := btcutil.DecodeAddress(address, &chaincfg.TestNet3Params)
+	assert.Nil(t, err, "unexpected error")
+	pkScript, err := PayToAddrScript(addr)
+	assert.Nil(t, err, "unexpected error")
+	return pkScript
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/stanche/crypto-interface/connector"
 	"github.com/stanche/crypto-interface/connector/btc_example/script"
+	"github.com/stanche/crypto-interface/netparams"
 
 	"github.com/wedancedalot/decimal"
 
@@ -30,6 +31,9 @@ const (
 	scriptVerifyFailureErr = "mandatory-script-verify-flag-failed"
 
 	defaultTimeoutSec = 30
+
+	// schnorrSignatureSize is the size of BCH Schnorr signature without the hash type.
+	schnorrSignatureSize = 64
 )
 
 type (
@@ -43,6 +47,14 @@ type (
 		CreateRawTransaction(inputs []btcjson.TransactionInput,
 			amounts map[btcutil.Address]btcutil.Amount) (*wire.MsgTx, error)
 		DecoderSet(decoder AddressDecoder)
+		// TxRebuildVerified works as TxRebuild but verifies the signatures against the spent outputs.
+		TxRebuildVerified(txHex string, signatures connector.TxSignatures, prevOuts []PrevOut) (string, error)
+		// RBFSet enables BIP125 replaceability of the transactions built with TxBuild.
+		RBFSet(enable bool)
+		// TxBump builds an unsigned replacement (RBF) or child (CPFP) transaction with a higher fee.
+		TxBump(walletData *connector.WalletSignStruct, txHex string, params TxBumpParams) (string, error)
+		// Utxos returns the unspent outputs of the addresses.
+		Utxos(currency connector.Currency, addresses ...string) ([]connector.UtxoStruct, error)
 	}
 
 	BtcChainConnector struct {
@@ -53,17 +65,29 @@ type (
 		Decoder     AddressDecoder
 		CoreClient  *Client
 		txBatchSize int
+		rbf         bool
+		decodeBlock BlockDecoder
+		omniBalance OmniBalanceGetter
 	}
 )
 
 func clientUrl(cfg connector.NodeParams) (string, error) {
-	if cfg.GetHost() != "" && cfg.GetPort() != 0 && cfg.GetUser() != "" && cfg.GetPassword() != "" {
+	if cfg != nil && cfg.GetHost() != "" && cfg.GetPort() != 0 && cfg.GetUser() != "" && cfg.GetPassword() != "" {
 		return fmt.Sprintf("http://%s:%s@%s:%d", cfg.GetUser(), cfg.GetPassword(), cfg.GetHost(), cfg.GetPort()), nil
 	}
 	return "", fmt.Errorf("invalid config")
 }
 
 func NewBtcChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (IBtcChainConnector, error) {
+	bcc, err := NewChainConnector(walletID, cfg, txBatchSize, &btcchaincfg.TestNet3Params)
+	if err != nil {
+		return nil, err
+	}
+	return bcc, nil
+}
+
+// NewChainConnector creates the connector of a BTC-like chain with the chain params.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int, chain *chaincfg.Params) (*BtcChainConnector, error) {
 	var err error
 	if cfg == nil || walletID <= 0 {
 		err = fmt.Errorf("Wallet configuration parameters absent")
@@ -79,7 +103,7 @@ func NewBtcChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchS
 			Currency:   cfg.Currency,
 			WalletType: cfg.Type,
 		},
-		chain:       &btcchaincfg.TestNet3Params,
+		chain:       chain,
 		txBatchSize: txBatchSize,
 	}
 	connector.DecoderSet(connector.DecodeAddress)
@@ -115,36 +139,45 @@ const btcPrecision = 8
 
 type coreBalance struct {
 	Balance int64 `json:"balance"`
+	// Immature is the part of the balance which is not spendable yet, reported by Dash-like address indexes.
+	Immature int64 `json:"balance_immature"`
 	// received ...
 }
 
-func (bcc *BtcChainConnector) balance(addr string) (int64, error) {
-
+// coreRequest sends the request of the address index to the core client.
+func (bcc *BtcChainConnector) coreRequest(method string, params interface{}, res interface{}) error {
 	if bcc.CoreClient == nil || bcc.CoreClient.URL == "" {
-		return 0, fmt.Errorf("coreClient not initialized")
+		return fmt.Errorf("coreClient not initialized")
 	}
-	data := fmt.Sprintf(`{"jsonrpc": "1.0", "id":"core", "method": "getaddressbalance", "params": ["%s"] }`, addr)
-	resp, err := bcc.CoreClient.send(data)
+	paramsData, err := json.Marshal(params)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	var res coreBalance
-	err = json.Unmarshal([]byte(resp), &res)
+	data := fmt.Sprintf(`{"jsonrpc": "1.0", "id":"core", "method": "%s", "params": %s }`, method, paramsData)
+	resp, err := bcc.CoreClient.send(data)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	return res.Balance, nil
+	return json.Unmarshal([]byte(resp), res)
+}
+
+func (bcc *BtcChainConnector) balance(addr string) (coreBalance, error) {
+	var res coreBalance
+	err := bcc.coreRequest("getaddressbalance", []string{addr}, &res)
+	return res, err
 }
 
+// BalanceGet returns the total balance of the addresses from the address index of the core client.
+// The unconfirmed amount is the sum of the mempool changes, it's negative if the coins are being spent.
 func (bcc *BtcChainConnector) BalanceGet(currency connector.Currency, addresses ...string) (b connector.AddressBalance, err error) {
 
 	if len(addresses) == 0 {
 		// log.Errorf("btcChainConnector does not support BalanceGet with empty addresses list")
 		return b, fmt.Errorf("unsupported params: BalanceGet.addresses are empty")
 	}
-	total := big.NewInt(0)
 	var valid bool
-	var balance int64
+	var confirmed, unconfirmed, unmatured int64
+	var validAddresses []string
 	for _, addr := range addresses {
 		valid, err = bcc.ValidateAddress(addr)
 		if err != nil {
@@ -154,16 +187,26 @@ func (bcc *BtcChainConnector) BalanceGet(currency connector.Currency, addresses
 		if !valid {
 			continue
 		}
-		balance, err = bcc.balance(addr)
+		balance, err := bcc.balance(addr)
 		if err != nil {
 			// log.Errorf("balance(%s): %s", addr, err.Error())
 			return b, err
 		}
-		//total = total.Add(total, balance)
-		total = total.Add(total, big.NewInt(balance))
+		confirmed += balance.Balance - balance.Immature
+		unmatured += balance.Immature
+		validAddresses = append(validAddresses, addr)
 	}
+	if len(validAddresses) > 0 {
+		unconfirmed, err = bcc.mempoolBalance(validAddresses)
+		if err != nil {
+			return b, err
+		}
+	}
+	precision := -int32(currency.GetPrecision())
 	return connector.AddressBalance{
-		Confirmed: decimal.NewFromBigInt(total, 0).Div(decimal.New(1, int32(currency.GetPrecision()))),
+		Confirmed:   decimal.New(confirmed, precision),
+		Unconfirmed: decimal.New(unconfirmed, precision),
+		Unmatured:   decimal.New(unmatured, precision),
 	}, nil
 
 }
@@ -185,6 +228,11 @@ func (bcc *BtcChainConnector) DecoderSet(decoder AddressDecoder) {
 	bcc.Decoder = decoder
 }
 
+// standard returns the standardness policy of the connector chain.
+func (bcc *BtcChainConnector) standard() netparams.StandardPolicy {
+	return netparams.Standard(bcc.chain)
+}
+
 func (bcc *BtcChainConnector) ParseOutputs(txOuts []*wire.TxOut) ([]*connector.OutputParsed, error) {
 	var outputs []*connector.OutputParsed
 	for i, txOut := range txOuts {
@@ -213,17 +261,15 @@ func (bcc *BtcChainConnector) CreateRawTransaction(inputs []btcjson.TransactionI
 }
 
 func (bcc *BtcChainConnector) GetBlockByNumber(number uint64) (*wire.MsgBlock, error) {
-	blockHash, err := bcc.Client.GetBlockHash(int64(number))
-	if blockHash == nil || err != nil {
-		return nil, connector.ErrNotFound
-	}
+	_, block, err := getBlock(bcc.Client, number, bcc.decodeBlock)
+	return block, err
+}
 
-	block, err := bcc.Client.GetBlock(blockHash)
-	if block == nil || err != nil {
-		return nil, connector.ErrNotFound
-	}
-	return block, nil
+// BlockDecoderSet sets the decoder of the blocks which differ from BTC format.
+func (bcc *BtcChainConnector) BlockDecoderSet(decoder BlockDecoder) {
+	bcc.decodeBlock = decoder
 }
+
 func (bcc *BtcChainConnector) GetTransactionByHash(hash chainhash.Hash) (*btcjson.TxRawResult, bool, error) {
 	tx, err := bcc.Client.GetRawTransactionVerbose(&hash)
 
@@ -263,77 +309,22 @@ func (bcc *BtcChainConnector) TxStatus(txID string, blockNo uint64) (*connector.
 	return &status, nil
 }
 
+// TxBuild builds the unsigned multisig transaction without the node.
 func (bcc *BtcChainConnector) TxBuild(walletData *connector.WalletSignStruct,
 	utxosIn interface{}, output []connector.OutStruct) (string, error) {
 
-	n := len(walletData.XPubs)
-	if n < 2 || n > 15 {
-		return "", fmt.Errorf("invalid signers quantity")
-	}
-	m := int(walletData.Signers)
-	if m > n || m < 1 || m > 15 {
-		return "", fmt.Errorf("invalid signers required number")
-	}
-
-	utxos, ok := utxosIn.([]connector.UtxStruct)
-	if !ok {
-		return "", fmt.Errorf("unexpected type of utxo input for BTC wallet: expected []connector.UtxStruct got: %+v", utxosIn)
-	}
-
-	inputs := make([]btcjson.TransactionInput, len(utxos))
-	for i := range utxos {
-		inputs[i] = btcjson.TransactionInput{
-			Txid: utxos[i].TxHash,
-			Vout: uint32(utxos[i].TxPos),
-		}
-	}
-
-	values := make(map[string]decimal.Decimal)
-	var val, value decimal.Decimal
-	for i := range output {
-		value = output[i].Amount.Abs() // just to copy value
-		val, ok = values[output[i].Address]
-		if ok {
-			value = value.Add(val)
-		}
-		values[output[i].Address] = value
-	}
-
-	amounts := make(map[btcutil.Address]btcutil.Amount)
-	var address btcutil.Address
-	var err error
-	for addr, amt := range values {
-		address, err = bcc.Decoder(addr)
-		if err != nil {
-			return "", err
-		}
-		amounts[address] = btcutil.Amount(amt.Mul(decimal.New(1, int32(btcPrecision))).IntPart())
-	}
-
-	msg, err := bcc.CreateRawTransaction(inputs, amounts)
-	if err != nil {
-		return "", err
-	}
-
-	for inputNo := range msg.TxIn {
-		err = ScriptBuild(msg.TxIn[inputNo], utxos[inputNo].Index, int(walletData.Signers), walletData.XPubs, nil)
-		if err != nil {
-			return "", err
-		}
-	}
-
-	var b bytes.Buffer
-	b.Grow(msg.SerializeSize())
-	err = msg.Serialize(&b)
-	if err != nil {
-		return "", err
-	}
-	//fmt.Printf("hexTx: %s", hex.EncodeToString(b.Bytes()))
-	return hex.EncodeToString(b.Bytes()), nil
+	return TxBuildBtc(walletData, utxosIn, output, bcc.Decoder, bcc.rbf, bcc.standard(), bcc.omniBalanceGetter())
 }
 
 func ScriptBuild(txIn *wire.TxIn, index uint32,
 	signaturesRequired int, xpubs []string, signatures []string) error {
+	return scriptBuild(txIn, index, signaturesRequired, xpubs, signatures, nil)
+}
+
+// scriptBuild assembles the input script. A non-nil dummy replaces OP_0 consumed by OP_CHECKMULTISIG,
+// BCH uses it as the checkbits of the Schnorr multisig.
+func scriptBuild(txIn *wire.TxIn, index uint32,
+	signaturesRequired int, xpubs []string, signatures []string, dummy []byte) error {
 
 	const xpubSize = 1 + 4 + 1 + 4 + 4 + 32 + 1 + 32 + 4 + 4
 	// ff 0488b21e 00 00000000 00000000
@@ -394,7 +385,11 @@ func ScriptBuild(txIn *wire.TxIn, index uint32,
 	}
 
 	scriptBuilder := txscript.NewScriptBuilder()
-	scriptBuilder.AddOp(txscript.OP_0)
+	if dummy == nil {
+		scriptBuilder.AddOp(txscript.OP_0)
+	} else {
+		scriptBuilder.AddData(dummy)
+	}
 	// fill signatures
 	for j := 0; j < walletM; j++ {
 		var signature []byte
@@ -453,10 +448,45 @@ func (bcc *BtcChainConnector) TxBroadcast(txHex string) (string, error) {
 
 // TxRebuild - combine parsed hex Tx with the signatures
 func (bcc *BtcChainConnector) TxRebuild(txHex string, signatures connector.TxSignatures) (string, error) {
-	return TxRebuildBtc(txHex, signatures)
+	return TxRebuildBtc(txHex, signatures, bcc.standard())
 }
 
-func TxRebuildBtc(txHex string, signatures connector.TxSignatures) (string, error) {
+// TxRebuildBtc combines the tx with the signatures and checks the result against the standardness policy of the chain.
+func TxRebuildBtc(txHex string, signatures connector.TxSignatures, policy netparams.StandardPolicy) (string, error) {
+	return txRebuild(txHex, signatures, nil, policy)
+}
+
+// TxRebuildSchnorr combines the tx with the BCH Schnorr signatures. cosigners are the indexes of the signing keys
+// in the sorted multisig keys (the "i" field of the signer output) in the order of the signatures,
+// they are encoded into the checkbits dummy of OP_CHECKMULTISIG.
+func TxRebuildSchnorr(txHex string, signatures connector.TxSignatures, cosigners [][]int, policy netparams.StandardPolicy) (string, error) {
+	if len(cosigners) != len(signatures) {
+		return "", fmt.Errorf("inconsistent signatures and cosigners quantity: %d ~ %d", len(signatures), len(cosigners))
+	}
+	return txRebuild(txHex, signatures, cosigners, policy)
+}
+
+// schnorrCheckbits returns the little-endian bitfield of n bits with the cosigners bits set.
+func schnorrCheckbits(n int, cosigners []int, signatures []string) ([]byte, error) {
+	if len(cosigners) != len(signatures) {
+		return nil, fmt.Errorf("inconsistent signatures and cosigners quantity: %d ~ %d", len(signatures), len(cosigners))
+	}
+	checkbits := make([]byte, (n+7)/8)
+	last := -1
+	for j, cosigner := range cosigners {
+		if cosigner <= last || cosigner >= n {
+			return nil, fmt.Errorf("invalid cosigner index %d of signature %d", cosigner, j)
+		}
+		last = cosigner
+		if len(signatures[j]) != 2*(schnorrSignatureSize+1) {
+			return nil, fmt.Errorf("signature %d is not a schnorr signature", j)
+		}
+		checkbits[cosigner/8] |= 1 << uint(cosigner%8)
+	}
+	return checkbits, nil
+}
+
+func txRebuild(txHex string, signatures connector.TxSignatures, cosigners [][]int, policy netparams.StandardPolicy) (string, error) {
 
 	txData, err := hex.DecodeString(txHex)
 	if err != nil {
@@ -505,11 +535,22 @@ func TxRebuildBtc(txHex string, signatures connector.TxSignatures) (string, erro
 			pubs[i] = hex.EncodeToString(pubkeys[i].SerializeCompressed())
 		}
 		sort.Strings(pubs)
-		err = ScriptBuild(msgTx.TxIn[indexTxIn], xpath[1], int(m), pubs, signatures[indexTxIn])
+		var dummy []byte
+		if cosigners != nil {
+			dummy, err = schnorrCheckbits(len(pubs), cosigners[indexTxIn], signatures[indexTxIn])
+			if err != nil {
+				return "", fmt.Errorf("input %d: %s", indexTxIn, err.Error())
+			}
+		}
+		err = scriptBuild(msgTx.TxIn[indexTxIn], xpath[1], int(m), pubs, signatures[indexTxIn], dummy)
 		if err != nil {
 			return "", err
 		}
 	}
+	err = CheckStandard(msgTx, policy)
+	if err != nil {
+		return "", err
+	}
 
 	var b bytes.Buffer
 	b.Grow(msgTx.SerializeSize())
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/rpcclient"
@@ -462,3 +463,69 @@ func TestBtcChainConnector_balance(t *testing.T) {
 		assert.Containsf(t, err.Error(), "coreClient not initialized", "should contain error message about core client")
 	})
 }
+
+func TestTxRebuildSchnorr(t *testing.T) {
+	txHex := "0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000"
+	signatureA := "42cc995ad72865a45439e69db0aa72e24178be2960f936c22f8a2b3e0f95573bd442127baa962de059919fbc2c14a6c1c199b98170bc501068e60162764017ba41"
+	signatureC := "012092d3454536bccd676bd25da09b04f44aac786a35811ac5c71b5a8364c5fdbd5235e253302e6bc15084a582e5b24cca7dca41b132e3eede3fa13e82172c7841"
+
+	tests := []struct {
+		name       string
+		signatures connector.TxSignatures
+		cosigners  [][]int
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "multisig 2 of 3 with checkbits 0b011",
+			signatures: connector.TxSignatures{{signatureA, signatureC}},
+			cosigners:  [][]int{{0, 1}},
+			// OP_3 dummy, two 65-byte signatures, redeem script
+			want: "0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000f0534142cc995ad72865a45439e69db0aa72e24178be2960f936c22f8a2b3e0f95573bd442127baa962de059919fbc2c14a6c1c199b98170bc501068e60162764017ba4141012092d3454536bccd676bd25da09b04f44aac786a35811ac5c71b5a8364c5fdbd5235e253302e6bc15084a582e5b24cca7dca41b132e3eede3fa13e82172c78414c69522102e9686c62273b60cdf58ee4c8bda595780bcdf5b441161b7301dad939dbe83ec42103297c46de43997b7f6702d9c353ffb2566d818eed8c295b16ed46076f328474882103839cd7a8f5fe9ef2528325425761d9c78bb162f7a70b8f4d88235cf763ee13ca53aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000",
+		},
+		{
+			name:       "cosigners out of order",
+			signatures: connector.TxSignatures{{signatureC, signatureA}},
+			cosigners:  [][]int{{1, 0}},
+			wantErr:    true,
+		},
+		{
+			name:       "cosigner index out of range",
+			signatures: connector.TxSignatures{{signatureA, signatureC}},
+			cosigners:  [][]int{{0, 3}},
+			wantErr:    true,
+		},
+		{
+			name: "ecdsa signature",
+			signatures: connector.TxSignatures{{
+				"30440220596c276e66186b98e1b190a626a94b30760718c99f2db32d2e165e7075c3f67302207fd6cd72995239952769b7ff2c61f4e952a05a3a9970f564f09f3efe51feded201",
+				signatureC,
+			}},
+			cosigners: [][]int{{0, 1}},
+			wantErr:   true,
+		},
+		{
+			name:       "inconsistent cosigners quantity",
+			signatures: connector.TxSignatures{{signatureA, signatureC}},
+			cosigners:  [][]int{{0}},
+			wantErr:    true,
+		},
+		{
+			name:       "missing cosigners of the input",
+			signatures: connector.TxSignatures{{signatureA, signatureC}},
+			cosigners:  nil,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TxRebuildSchnorr(txHex, tt.signatures, tt.cosigners, netparams.BtcStandardPolicy)
+			if tt.wantErr {
+				assert.NotNil(t, err, "expected error")
+				return
+			}
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.want, got, "unexpected tx")
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package btc_example
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// Omni Layer class C transactions carry the payload in the OP_RETURN output after the marker.
+// The simple send payload is: version uint16, type uint16, property uint32, amount int64, big-endian.
+const (
+	omniTypeSimpleSend = 0
+	omniSimpleSendSize = 16
+)
+
+var omniMarker = []byte("omni")
+
+// OmniBalanceGetter returns the balance of the property held by the address of the output script
+// in the indivisible units of the property.
+type OmniBalanceGetter func(pkScript []byte, property uint32) (int64, error)
+
+// OmniTransaction is the transaction as parsed by Omni Core, see omni_gettransaction.
+type OmniTransaction struct {
+	TxID             string `json:"txid"`
+	SendingAddress   string `json:"sendingaddress"`
+	ReferenceAddress string `json:"referenceaddress"`
+	PropertyID       uint32 `json:"propertyid"`
+	// Valid is set if the sender held the amount and Omni Core applied the send.
+	Valid bool `json:"valid"`
+}
+
+// OmniTransactionGetter returns the Omni transaction by the txid.
+type OmniTransactionGetter func(txID string) (*OmniTransaction, error)
+
+// OmniSend is the Omni Layer simple send of the property (token code of the currency).
+// Amount is in the indivisible units of the property.
+type OmniSend struct {
+	Property uint32
+	Amount   int64
+}
+
+// OmniSimpleSendScript returns the OP_RETURN output script of the simple send.
+func OmniSimpleSendScript(send OmniSend) ([]byte, error) {
+	if send.Amount <= 0 {
+		return nil, fmt.Errorf("invalid omni amount %d", send.Amount)
+	}
+	payload := make([]byte, len(omniMarker)+omniSimpleSendSize)
+	n := copy(payload, omniMarker)
+	binary.BigEndian.PutUint16(payload[n+2:], omniTypeSimpleSend)
+	binary.BigEndian.PutUint32(payload[n+4:], send.Property)
+	binary.BigEndian.PutUint64(payload[n+8:], uint64(send.Amount))
+	return txscript.NullDataScript(payload)
+}
+
+// ParseOmniSimpleSend returns the simple send of the OP_RETURN output script, ok is false for other scripts.
+func ParseOmniSimpleSend(pkScript []byte) (send OmniSend, ok bool) {
+	if txscript.GetScriptClass(pkScript) != txscript.NullDataTy {
+		return send, false
+	}
+	pushes, err := txscript.PushedData(pkScript)
+	if err != nil {
+		return send, false
+	}
+	payload := bytes.Join(pushes, nil)
+	if !bytes.HasPrefix(payload, omniMarker) || len(payload) < len(omniMarker)+omniSimpleSendSize {
+		return send, false
+	}
+	payload = payload[len(omniMarker):]
+	if binary.BigEndian.Uint16(payload) != 0 || binary.BigEndian.Uint16(payload[2:]) != omniTypeSimpleSend {
+		return send, false
+	}
+	send.Property = binary.BigEndian.Uint32(payload[4:])
+	amount := binary.BigEndian.Uint64(payload[8:])
+	if amount == 0 || amount > math.MaxInt64 {
+		return send, false
+	}
+	send.Amount = int64(amount)
+	return send, true
+}
+
+// isOmni reports whether the currency is the Omni Layer property.
+func isOmni(currency connector.Currency) bool {
+	return currency != nil && currency.GetTokenCode() != 0
+}
+
+// splitOmniOutputs separates the Omni output, i.e. the one of the currency with the token code.
+// Omni parses one payload per tx, so there is one Omni output at most.
+func splitOmniOutputs(output []connector.OutStruct) ([]connector.OutStruct, *connector.OutStruct, error) {
+	var coins []connector.OutStruct
+	var omni *connector.OutStruct
+	for i := range output {
+		if !isOmni(output[i].Currency) {
+			coins = append(coins, output[i])
+			continue
+		}
+		if omni != nil {
+			return nil, nil, fmt.Errorf("only one omni output per tx is supported")
+		}
+		omni = &output[i]
+	}
+	return coins, omni, nil
+}
+
+// addOmniSend appends the OP_RETURN with the simple send and the reference output of the recipient.
+// The reference output is the last one and carries the dust threshold value. The input scripts shall be built,
+// the inputs shall be of one address, the sender, which holds the amount of the property.
+func addOmniSend(msg *wire.MsgTx, output connector.OutStruct, decoder AddressDecoder, policy netparams.StandardPolicy,
+	balance OmniBalanceGetter) error {
+	currency := output.Currency
+	if currency.GetTokenCode() < 0 || currency.GetTokenCode() > math.MaxUint32 {
+		return fmt.Errorf("invalid omni property %d", currency.GetTokenCode())
+	}
+	units := output.Amount.Mul(decimal.New(1, int32(currency.GetPrecision())))
+	if !units.Equal(decimal.New(units.IntPart(), 0)) {
+		return fmt.Errorf("invalid %s amount %s", currency.GetCode(), output.Amount.String())
+	}
+	send := OmniSend{Property: uint32(currency.GetTokenCode()), Amount: units.IntPart()}
+	nullData, err := OmniSimpleSendScript(send)
+	if err != nil {
+		return err
+	}
+	if err = checkOmniSender(msg, send, balance); err != nil {
+		return err
+	}
+	address, err := decoder(output.Address)
+	if err != nil {
+		return err
+	}
+	pkScript, err := PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+	msg.AddTxOut(wire.NewTxOut(0, nullData))
+	msg.AddTxOut(wire.NewTxOut(DustThreshold(pkScript, policy), pkScript))
+	return nil
+}
+
+// checkOmniSender checks the sender of the simple send holds the amount. Omni Core takes the sender
+// from the inputs, so they shall be of one address.
+func checkOmniSender(msg *wire.MsgTx, send OmniSend, balance OmniBalanceGetter) error {
+	if balance == nil {
+		return fmt.Errorf("omni balance of the sender is unknown")
+	}
+	if len(msg.TxIn) == 0 {
+		return fmt.Errorf("omni send without inputs")
+	}
+	sender, err := InputPkScript(msg, 0)
+	if err != nil {
+		return err
+	}
+	for idx := 1; idx < len(msg.TxIn); idx++ {
+		pkScript, err := InputPkScript(msg, idx)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(pkScript, sender) {
+			return fmt.Errorf("omni send inputs shall be of one address, input %d differs", idx)
+		}
+	}
+	amount, err := balance(sender, send.Property)
+	if err != nil {
+		return fmt.Errorf("omni balance: %s", err.Error())
+	}
+	if amount < send.Amount {
+		return fmt.Errorf("insufficient omni property %d balance of the sender: %d < %d", send.Property, amount, send.Amount)
+	}
+	return nil
+}
+
+// omniOperations returns the operations of the simple send of the currencies to the addresses.
+// The send is credited once Omni Core applied it, the recipient is the last output which is not of the sender.
+func omniOperations(d processTxData, outputs []outputParsed) ([]connector.Operation, error) {
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+	for _, txOut := range d.txMsg.TxOut {
+		send, ok := ParseOmniSimpleSend(txOut.PkScript)
+		if ok {
+			// Omni Core parses the first payload only
+			return omniSendOperations(d, outputs, send)
+		}
+	}
+	return nil, nil
+}
+
+func omniSendOperations(d processTxData, outputs []outputParsed, send OmniSend) ([]connector.Operation, error) {
+	var currency connector.Currency
+	for _, omni := range d.omni {
+		if omni.GetTokenCode() == int64(send.Property) {
+			currency = omni
+			break
+		}
+	}
+	// the sender is known from Omni Core only, so it is asked if any output is watched
+	watched := false
+	for _, output := range outputs {
+		watched = watched || d.addresses.HasAddress(output.Address, "")
+	}
+	if currency == nil || !watched {
+		return nil, nil
+	}
+	if d.omniTx == nil {
+		return nil, fmt.Errorf("omni transaction getter is not set")
+	}
+	txID := d.txMsg.TxHash().String()
+	omniTx, err := d.omniTx(txID)
+	if err != nil {
+		return nil, fmt.Errorf("omni transaction %s: %s", txID, err.Error())
+	}
+	if !omniTx.Valid || omniTx.PropertyID != send.Property {
+		return nil, nil
+	}
+	reference := referenceOutput(outputs, omniTx.SendingAddress)
+	if reference.Address != omniTx.ReferenceAddress || !d.addresses.HasAddress(reference.Address, "") {
+		return nil, nil
+	}
+	return []connector.Operation{{
+		TxId:         txID,
+		TxOut:        reference.TxPos,
+		ToAddress:    reference.Address,
+		CurrencyCode: currency.GetCode(),
+		Amount:       decimal.New(send.Amount, -int32(currency.GetPrecision())),
+	}}, nil
+}
+
+// referenceOutput returns the last output which is not of the sender, the last one if all of them are.
+func referenceOutput(outputs []outputParsed, sender string) outputParsed {
+	var reference *outputParsed
+	for i := range outputs {
+		if outputs[i].Address == sender {
+			continue
+		}
+		if reference == nil || outputs[i].TxPos > reference.TxPos {
+			reference = &outputs[i]
+		}
+	}
+	if reference != nil {
+		return *reference
+	}
+	last := outputs[0]
+	for _, output := range outputs[1:] {
+		if output.TxPos > last.TxPos {
+			last = output
+		}
+	}
+	return last
+}
+
+// GetOmniTransaction returns the getter of the Omni transactions with omni_gettransaction of Omni Core.
+func GetOmniTransaction(client *rpcclient.Client) OmniTransactionGetter {
+	return func(txID string) (*OmniTransaction, error) {
+		if client == nil {
+			return nil, connector.ErrClientNil
+		}
+		param, err := json.Marshal(txID)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.RawRequest("omni_gettransaction", []json.RawMessage{param})
+		if err != nil {
+			return nil, fmt.Errorf("omni_gettransaction: %s", err.Error())
+		}
+		var omniTx OmniTransaction
+		if err = json.Unmarshal(resp, &omniTx); err != nil {
+			return nil, fmt.Errorf("omni_gettransaction: %s", err.Error())
+		}
+		return &omniTx, nil
+	}
+}
+
+// omniBalance is the result of omni_getbalance, the amounts of the divisible properties have 8 decimals.
+type omniBalance struct {
+	Balance string `json:"balance"`
+}
+
+// getOmniBalance returns the balance of the property with omni_getbalance of Omni Core.
+func (bcc *BtcChainConnector) getOmniBalance(pkScript []byte, property uint32) (int64, error) {
+	if bcc.Client == nil {
+		return 0, connector.ErrClientNil
+	}
+	_, addresses, _, err := txscript.ExtractPkScriptAddrs(pkScript, bcc.chain)
+	if err != nil || len(addresses) != 1 {
+		return 0, fmt.Errorf("unexpected script of the omni sender %x", pkScript)
+	}
+	params := make([]json.RawMessage, 2)
+	if params[0], err = json.Marshal(addresses[0].EncodeAddress()); err != nil {
+		return 0, err
+	}
+	if params[1], err = json.Marshal(property); err != nil {
+		return 0, err
+	}
+	resp, err := bcc.Client.RawRequest("omni_getbalance", params)
+	if err != nil {
+		return 0, fmt.Errorf("omni_getbalance: %s", err.Error())
+	}
+	var balance omniBalance
+	if err = json.Unmarshal(resp, &balance); err != nil {
+		return 0, fmt.Errorf("omni_getbalance: %s", err.Error())
+	}
+	// the units of the property are the digits of the balance
+	units, err := strconv.ParseInt(strings.Replace(balance.Balance, ".", "", 1), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("omni_getbalance: invalid balance %s", balance.Balance)
+	}
+	return units, nil
+}
+
+// OmniBalanceSet replaces omni_getbalance of the node checking the sender of the Omni sends.
+func (bcc *BtcChainConnector) OmniBalanceSet(balance OmniBalanceGetter) {
+	bcc.omniBalance = balance
+}
+
+// omniBalanceGetter returns the getter set with OmniBalanceSet or the node one.
+func (bcc *BtcChainConnector) omniBalanceGetter() OmniBalanceGetter {
+	if bcc.omniBalance != nil {
+		return bcc.omniBalance
+	}
+	return bcc.getOmniBalance
+}
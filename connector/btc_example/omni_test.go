@@ -0,0 +1,35 @@
+package btc_example
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOmniSimpleSend(t *testing.T) {
+	// 8 USDT, property 31
+	script, err := OmniSimpleSendScript(OmniSend{Property: 31, Amount: 800000000})
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "6a146f6d6e69000000000000001f000000002faf0800", hex.EncodeToString(script), "unexpected script")
+
+	send, ok := ParseOmniSimpleSend(script)
+	assert.True(t, ok, "simple send expected")
+	assert.Equal(t, OmniSend{Property: 31, Amount: 800000000}, send, "unexpected send")
+
+	_, err = OmniSimpleSendScript(OmniSend{Property: 31})
+	assert.NotNil(t, err, "expected error for zero amount")
+
+	for name, scriptHex := range map[string]string{
+		"not null data":     "76a914b9e6fa37edaf12df0a0036257e7e89a9abb42fae88ac",
+		"other marker":      "6a146f6d6e68000000000000001f000000002faf0800",
+		"send to owners":    "6a146f6d6e69000000030000001f000000002faf0800",
+		"version 1":         "6a146f6d6e69000100000000001f000000002faf0800",
+		"truncated payload": "6a106f6d6e69000000000000001f00000000",
+		"zero amount":       "6a146f6d6e69000000000000001f0000000000000000",
+	} {
+		script, _ := hex.DecodeString(scriptHex)
+		_, ok := ParseOmniSimpleSend(script)
+		assert.False(t, ok, "unexpected simple send for %s", name)
+	}
+}
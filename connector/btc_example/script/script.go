@@ -379,9 +379,11 @@ func MultisigScriptFromPubkeys(m byte, pubkeys []*btcec.PublicKey, index int) (s
 	for i := range pubkeys {
 		pubkeysCompressed[i] = pubkeys[i].SerializeCompressed()
 	}
+	// SortedPubkeys sorts in place
+	searchingPubkey := pubkeysCompressed[index][:]
 	sortedPubkeysCompressed := SortedPubkeys(pubkeysCompressed)
 	for i := range pubkeys {
-		if bytes.Compare(pubkeysCompressed[index], sortedPubkeysCompressed[i]) == 0 {
+		if bytes.Compare(searchingPubkey, sortedPubkeysCompressed[i]) == 0 {
 			indexSorted = i
 			break
 		}
@@ -0,0 +1,202 @@
+package btc_example
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/stanche/crypto-interface/netparams"
+	"github.com/stanche/crypto-interface/txsize"
+)
+
+// Standardness limits of Bitcoin Core relay policy shared by the chains, the others are in netparams.StandardPolicy.
+const (
+	MaxStandardTxWeight       = 400000
+	MaxStandardScriptSigSize  = 1650
+	MaxStandardTxSigOps       = 4000 // MAX_STANDARD_TX_SIGOPS_COST / WITNESS_SCALE_FACTOR
+	MaxP2SHSigOps             = 15
+	MaxStandardBareMultisig   = 3
+	witnessScaleFactor        = 4
+	sizeSpendingInputEstimate = 32 + 4 + 1 + 107 + 4
+)
+
+// Errors of the standardness checks, the messages are the reject reasons of Bitcoin Core.
+var (
+	ErrTxVersion            = fmt.Errorf("version")
+	ErrTxSize               = fmt.Errorf("tx-size")
+	ErrScriptSigSize        = fmt.Errorf("scriptsig-size")
+	ErrScriptSigNotPushOnly = fmt.Errorf("scriptsig-not-pushonly")
+	ErrScriptPubKey         = fmt.Errorf("scriptpubkey")
+	ErrDust                 = fmt.Errorf("dust")
+	ErrMultiOpReturn        = fmt.Errorf("multi-op-return")
+	ErrSigOps               = fmt.Errorf("bad-txns-too-many-sigops")
+	ErrP2SHSigOps           = fmt.Errorf("bad-txns-nonstandard-inputs")
+)
+
+// StandardError describes a violation of the standardness rules.
+// Input and Output are the indexes of the violating input or output, -1 if not applicable.
+type StandardError struct {
+	Input  int
+	Output int
+	Err    error
+}
+
+func (e *StandardError) Error() string {
+	switch {
+	case e.Input >= 0:
+		return fmt.Sprintf("input %d: %s", e.Input, e.Err.Error())
+	case e.Output >= 0:
+		return fmt.Sprintf("output %d: %s", e.Output, e.Err.Error())
+	}
+	return e.Err.Error()
+}
+
+// IsDust reports whether the output is below the dust threshold of the relay policy.
+func IsDust(txOut *wire.TxOut, policy netparams.StandardPolicy) bool {
+	if isNullData(txOut.PkScript) {
+		return false
+	}
+	return txOut.Value < DustThreshold(txOut.PkScript, policy)
+}
+
+// lockingScript returns the output script without the prefix defined by the policy.
+func lockingScript(pkScript []byte, policy netparams.StandardPolicy) ([]byte, error) {
+	if policy.LockingScript == nil {
+		return pkScript, nil
+	}
+	return policy.LockingScript(pkScript)
+}
+
+// isNullData reports whether the script is OP_RETURN followed by the pushes of any size,
+// txscript limits the null data to 80 bytes while the size is the policy of the chain.
+func isNullData(pkScript []byte) bool {
+	return len(pkScript) > 0 && pkScript[0] == txscript.OP_RETURN && txscript.IsPushOnlyScript(pkScript[1:])
+}
+
+// DustThreshold returns the minimal value of a non-dust output with the script.
+func DustThreshold(pkScript []byte, policy netparams.StandardPolicy) int64 {
+	if policy.DustLimit > 0 {
+		return policy.DustLimit
+	}
+	size := wire.NewTxOut(0, pkScript).SerializeSize() + sizeSpendingInputEstimate
+	return int64(size) * policy.DustRelayFeeRate / 1000
+}
+
+// CheckStandard checks the signed transaction against the standardness rules of the chain.
+func CheckStandard(msgTx *wire.MsgTx, policy netparams.StandardPolicy) error {
+	if err := checkStandardTx(msgTx, msgTx.SerializeSize(), policy); err != nil {
+		return err
+	}
+	sigOps := 0
+	for i, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) > MaxStandardScriptSigSize {
+			return &StandardError{Input: i, Output: -1, Err: ErrScriptSigSize}
+		}
+		if !txscript.IsPushOnlyScript(txIn.SignatureScript) {
+			return &StandardError{Input: i, Output: -1, Err: ErrScriptSigNotPushOnly}
+		}
+		sigOps += txscript.GetSigOpCount(txIn.SignatureScript)
+
+		// the redeem script of P2SH input is the last push
+		pushes, err := txscript.PushedData(txIn.SignatureScript)
+		if err != nil || len(pushes) == 0 {
+			continue
+		}
+		redeemScript := pushes[len(pushes)-1]
+		if txscript.GetScriptClass(redeemScript) == txscript.NonStandardTy {
+			continue
+		}
+		p2shSigOps := txscript.GetPreciseSigOpCount(nil, redeemScript, false)
+		if p2shSigOps > MaxP2SHSigOps {
+			return &StandardError{Input: i, Output: -1, Err: ErrP2SHSigOps}
+		}
+		sigOps += p2shSigOps
+	}
+	return checkSigOps(msgTx, sigOps, policy)
+}
+
+// CheckStandardUnsigned checks the unsigned transaction built with TxBuild, the input scripts
+// are estimated as signed by the required number of cosigners.
+func CheckStandardUnsigned(msgTx *wire.MsgTx, policy netparams.StandardPolicy) error {
+	size, err := signedSize(msgTx)
+	if err != nil {
+		return err
+	}
+	if err = checkStandardTx(msgTx, size, policy); err != nil {
+		return err
+	}
+	sigOps := 0
+	for i := range msgTx.TxIn {
+		m, pubkeys, err := inputPubkeys(msgTx, i)
+		if err != nil {
+			return err
+		}
+		if txsize.ScriptSigSize(int(m), len(pubkeys)) > MaxStandardScriptSigSize {
+			return &StandardError{Input: i, Output: -1, Err: ErrScriptSigSize}
+		}
+		// OP_CHECKMULTISIG of P2SH is counted by the number of keys
+		if len(pubkeys) > MaxP2SHSigOps {
+			return &StandardError{Input: i, Output: -1, Err: ErrP2SHSigOps}
+		}
+		sigOps += len(pubkeys)
+	}
+	return checkSigOps(msgTx, sigOps, policy)
+}
+
+// checkStandardTx checks the version, the size and the outputs.
+func checkStandardTx(msgTx *wire.MsgTx, size int, policy netparams.StandardPolicy) error {
+	if msgTx.Version < 1 || msgTx.Version > policy.MaxVersion {
+		return &StandardError{Input: -1, Output: -1, Err: ErrTxVersion}
+	}
+	if size*witnessScaleFactor > MaxStandardTxWeight {
+		return &StandardError{Input: -1, Output: -1, Err: ErrTxSize}
+	}
+	nullData := 0
+	for i, txOut := range msgTx.TxOut {
+		pkScript, err := lockingScript(txOut.PkScript, policy)
+		if err != nil {
+			return &StandardError{Input: -1, Output: i, Err: ErrScriptPubKey}
+		}
+		if isNullData(pkScript) {
+			if policy.MaxOpReturnRelay > 0 && len(pkScript) > policy.MaxOpReturnRelay {
+				return &StandardError{Input: -1, Output: i, Err: ErrScriptPubKey}
+			}
+			nullData++
+			if nullData > 1 {
+				return &StandardError{Input: -1, Output: i, Err: ErrMultiOpReturn}
+			}
+			continue
+		}
+		switch txscript.GetScriptClass(pkScript) {
+		case txscript.NonStandardTy:
+			return &StandardError{Input: -1, Output: i, Err: ErrScriptPubKey}
+		case txscript.ScriptHashTy:
+			if policy.NoScriptHash {
+				return &StandardError{Input: -1, Output: i, Err: ErrScriptPubKey}
+			}
+		case txscript.MultiSigTy:
+			pubKeys, _, err := txscript.CalcMultiSigStats(pkScript)
+			if err != nil || pubKeys > MaxStandardBareMultisig {
+				return &StandardError{Input: -1, Output: i, Err: ErrScriptPubKey}
+			}
+		}
+		if IsDust(txOut, policy) {
+			return &StandardError{Input: -1, Output: i, Err: ErrDust}
+		}
+	}
+	return nil
+}
+
+// checkSigOps adds the output sigops to the inputs ones and checks the limit.
+func checkSigOps(msgTx *wire.MsgTx, sigOps int, policy netparams.StandardPolicy) error {
+	for _, txOut := range msgTx.TxOut {
+		// the scripts are checked by checkStandardTx
+		pkScript, _ := lockingScript(txOut.PkScript, policy)
+		sigOps += txscript.GetSigOpCount(pkScript)
+	}
+	if sigOps > MaxStandardTxSigOps {
+		return &StandardError{Input: -1, Output: -1, Err: ErrSigOps}
+	}
+	return nil
+}
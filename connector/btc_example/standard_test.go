@@ -0,0 +1,212 @@
+package btc_example
+
+import (
+	"testing"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+)
+
+func TestCheckStandard(t *testing.T) {
+	p2pkh, _ := btcutil.DecodeAddress("mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", &chaincfg.TestNet3Params)
+	p2pkhScript, _ := txscript.PayToAddrScript(p2pkh)
+	p2sh, _ := btcutil.DecodeAddress("2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT", &chaincfg.TestNet3Params)
+	p2shScript, _ := txscript.PayToAddrScript(p2sh)
+	opReturn, _ := txscript.NullDataScript([]byte("memo"))
+	opReturn200, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_RETURN).AddData(make([]byte, 200)).Script()
+
+	signedHex, _ := TxRebuildBtc(verifyTxHex, connector.TxSignatures{{verifySignatureA, verifySignatureB}}, netparams.BtcStandardPolicy)
+	signed, _ := decodeTx(signedHex)
+	unsigned, _ := decodeTx(verifyTxHex)
+
+	cases := []struct {
+		name       string
+		modify     func(msgTx *wire.MsgTx)
+		policy     *netparams.StandardPolicy
+		unsigned   bool
+		wantErr    error
+		wantInput  int
+		wantOutput int
+	}{
+		{
+			name:   "standard signed tx",
+			modify: func(msgTx *wire.MsgTx) {},
+		},
+		{
+			name:     "standard unsigned tx",
+			modify:   func(msgTx *wire.MsgTx) {},
+			unsigned: true,
+		},
+		{
+			name: "dust output",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.AddTxOut(wire.NewTxOut(545, p2pkhScript))
+			},
+			unsigned:   true,
+			wantErr:    ErrDust,
+			wantInput:  -1,
+			wantOutput: 1,
+		},
+		{
+			name: "two OP_RETURN outputs",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.AddTxOut(wire.NewTxOut(0, opReturn))
+				msgTx.AddTxOut(wire.NewTxOut(0, opReturn))
+			},
+			wantErr:    ErrMultiOpReturn,
+			wantInput:  -1,
+			wantOutput: 2,
+		},
+		{
+			name: "non-standard output",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.TxOut[0].PkScript = []byte{txscript.OP_TRUE}
+			},
+			wantErr:    ErrScriptPubKey,
+			wantInput:  -1,
+			wantOutput: 0,
+		},
+		{
+			name: "scriptSig is not push only",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.TxIn[0].SignatureScript = append(msgTx.TxIn[0].SignatureScript, txscript.OP_DROP)
+			},
+			wantErr:    ErrScriptSigNotPushOnly,
+			wantInput:  0,
+			wantOutput: -1,
+		},
+		{
+			name: "version 3",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.Version = 3
+			},
+			wantErr:    ErrTxVersion,
+			wantInput:  -1,
+			wantOutput: -1,
+		},
+		{
+			name:   "dash version 3",
+			policy: &netparams.DashStandardPolicy,
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.Version = 3
+			},
+		},
+		{
+			name: "doge dust output",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.AddTxOut(wire.NewTxOut(999999, p2pkhScript))
+			},
+			policy:     &netparams.DogeStandardPolicy,
+			unsigned:   true,
+			wantErr:    ErrDust,
+			wantInput:  -1,
+			wantOutput: 1,
+		},
+		{
+			name: "doge output at dust limit",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.AddTxOut(wire.NewTxOut(1000000, p2pkhScript))
+			},
+			policy:   &netparams.DogeStandardPolicy,
+			unsigned: true,
+		},
+		{
+			name: "large OP_RETURN",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.AddTxOut(wire.NewTxOut(0, opReturn200))
+			},
+			wantErr:    ErrScriptPubKey,
+			wantInput:  -1,
+			wantOutput: 1,
+		},
+		{
+			name: "bch large OP_RETURN",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.AddTxOut(wire.NewTxOut(0, opReturn200))
+			},
+			policy: &netparams.BchStandardPolicy,
+		},
+		{
+			name: "bsv 1 satoshi output",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.TxOut[0].PkScript = p2pkhScript
+				msgTx.AddTxOut(wire.NewTxOut(1, p2pkhScript))
+			},
+			policy:   &netparams.BsvStandardPolicy,
+			unsigned: true,
+		},
+		{
+			name: "bsv P2SH output",
+			modify: func(msgTx *wire.MsgTx) {
+				msgTx.TxOut[0].PkScript = p2pkhScript
+				msgTx.AddTxOut(wire.NewTxOut(1000, p2shScript))
+			},
+			policy:     &netparams.BsvStandardPolicy,
+			unsigned:   true,
+			wantErr:    ErrScriptPubKey,
+			wantInput:  -1,
+			wantOutput: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := netparams.BtcStandardPolicy
+			if tc.policy != nil {
+				policy = *tc.policy
+			}
+			var err error
+			if tc.unsigned {
+				msgTx := unsigned.Copy()
+				tc.modify(msgTx)
+				err = CheckStandardUnsigned(msgTx, policy)
+			} else {
+				msgTx := signed.Copy()
+				tc.modify(msgTx)
+				err = CheckStandard(msgTx, policy)
+			}
+			if tc.wantErr == nil {
+				assert.Nil(t, err, "unexpected error")
+				return
+			}
+			stdErr, ok := err.(*StandardError)
+			if assert.True(t, ok, "unexpected error type: %v", err) {
+				assert.Equal(t, tc.wantErr, stdErr.Err, "unexpected error")
+				assert.Equal(t, tc.wantInput, stdErr.Input, "unexpected input")
+				assert.Equal(t, tc.wantOutput, stdErr.Output, "unexpected output")
+			}
+		})
+	}
+
+	assert.Equal(t, int64(546), DustThreshold(p2pkhScript, netparams.BtcStandardPolicy), "unexpected P2PKH dust threshold")
+	assert.Equal(t, int64(540), DustThreshold(p2shScript, netparams.BtcStandardPolicy), "unexpected P2SH dust threshold")
+	assert.Equal(t, int64(5460), DustThreshold(p2pkhScript, netparams.LtcStandardPolicy), "unexpected LTC dust threshold")
+	assert.Equal(t, int64(1000000), DustThreshold(p2pkhScript, netparams.DogeStandardPolicy), "unexpected DOGE dust threshold")
+
+	// TxBuild rejects the dust
+	wallet := &connector.WalletSignStruct{
+		Signers: 2,
+		XPubs: []string{
+			"xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+			"xpub661MyMwAqRbcGgsQadngKDqjvQDC299XoG8SjbpfZhKUofdVVCqehG2TCsTXNudCFyTmNL72gGmNBNbtu75Tkzz2jJMqBak8Ab71MQYs2UQ",
+			"xpub661MyMwAqRbcFTni57UXBzWmbN3JtuoqdLivkjzkbkiPB46gDU6pYYQeE2BKRyhD1h6wXHx5jRWZh78NS45EoZPwVezgKkLjf4TTXPWh8Wv",
+		},
+	}
+	_, err := TxBuildBtc(wallet,
+		[]connector.UtxStruct{{TxHash: "b5fbac128e00fd45968468b90fde985b5132d458a66292a39bc4ad4639d0a56b"}},
+		[]connector.OutStruct{{Address: "mxTv18muiJu6qUNS2vdh6SRUWKfPYpYHFk", Amount: decimal.New(500, -8)}},
+		func(addr string) (btcutil.Address, error) {
+			return btcutil.DecodeAddress(addr, &chaincfg.TestNet3Params)
+		},
+		false, netparams.BtcStandardPolicy, nil)
+	stdErr, ok := err.(*StandardError)
+	if assert.True(t, ok, "unexpected error type: %v", err) {
+		assert.Equal(t, ErrDust, stdErr.Err, "unexpected error")
+	}
+}
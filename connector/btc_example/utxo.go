@@ -0,0 +1,87 @@
+package btc_example
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+type (
+	// coreAddresses is the parameter of the address index requests for several addresses.
+	coreAddresses struct {
+		Addresses []string `json:"addresses"`
+	}
+
+	coreMempoolDelta struct {
+		Address  string `json:"address"`
+		Satoshis int64  `json:"satoshis"`
+	}
+
+	coreUtxo struct {
+		Address     string `json:"address"`
+		TxID        string `json:"txid"`
+		OutputIndex int    `json:"outputIndex"`
+		Satoshis    int64  `json:"satoshis"`
+		Height      int    `json:"height"`
+	}
+)
+
+// mempoolBalance returns the sum of the mempool changes of the addresses.
+func (bcc *BtcChainConnector) mempoolBalance(addresses []string) (int64, error) {
+	var deltas []coreMempoolDelta
+	err := bcc.coreRequest("getaddressmempool", []coreAddresses{{Addresses: addresses}}, &deltas)
+	if err != nil {
+		return 0, fmt.Errorf("getaddressmempool: %s", err.Error())
+	}
+	var total int64
+	for _, delta := range deltas {
+		total += delta.Satoshis
+	}
+	return total, nil
+}
+
+// Utxos returns the confirmed unspent outputs of the addresses from the address index of the core client,
+// ordered by height. The invalid addresses are skipped.
+func (bcc *BtcChainConnector) Utxos(currency connector.Currency, addresses ...string) ([]connector.UtxoStruct, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("unsupported params: Utxos.addresses are empty")
+	}
+	var validAddresses []string
+	for _, addr := range addresses {
+		valid, err := bcc.ValidateAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			validAddresses = append(validAddresses, addr)
+		}
+	}
+	if len(validAddresses) == 0 {
+		return nil, nil
+	}
+
+	var res []coreUtxo
+	err := bcc.coreRequest("getaddressutxos", []coreAddresses{{Addresses: validAddresses}}, &res)
+	if err != nil {
+		return nil, fmt.Errorf("getaddressutxos: %s", err.Error())
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Height < res[j].Height
+	})
+
+	precision := -int32(currency.GetPrecision())
+	utxos := make([]connector.UtxoStruct, len(res))
+	for i := range res {
+		utxos[i] = connector.UtxoStruct{
+			TxHash:  res[i].TxID,
+			Height:  res[i].Height,
+			TxPos:   res[i].OutputIndex,
+			Value:   decimal.New(res[i].Satoshis, precision),
+			Address: res[i].Address,
+		}
+	}
+	return utxos, nil
+}
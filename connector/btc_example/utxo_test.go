@@ -0,0 +1,75 @@
+package btc_example
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+// coreServer fakes the address index of the core client.
+func coreServer(t *testing.T, results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req), "unexpected request")
+		result, ok := results[req.Method+string(req.Params)]
+		if !ok {
+			fmt.Fprintf(w, `{"result":null,"error":{"code":-32601,"message":"unexpected %s %s"}}`, req.Method, req.Params)
+			return
+		}
+		fmt.Fprintf(w, `{"result":%s,"error":null}`, result)
+	}))
+}
+
+func TestBtcChainConnector_BalanceGet(t *testing.T) {
+	server := coreServer(t, map[string]string{
+		`getaddressbalance["mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX"]`:                                                       `{"balance":150000000,"balance_immature":50000000,"received":200000000}`,
+		`getaddressbalance["2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT"]`:                                                      `{"balance":2000,"received":2000}`,
+		`getaddressmempool[{"addresses":["mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX","2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT"]}]`: `[{"address":"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX","satoshis":-1000},{"address":"2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT","satoshis":3500}]`,
+	})
+	defer server.Close()
+	bcc := &BtcChainConnector{chain: &chaincfg.TestNet3Params, CoreClient: NewClient(server.URL, 5)}
+	currency := Currency{Code: "BTC", Precision: 8}
+
+	balance, err := bcc.BalanceGet(currency, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "2N9EsHgmGFqSUsGvBKcRqsmnWMg7dVVBYVT")
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "1.00002", balance.Confirmed.String(), "unexpected confirmed")
+	assert.Equal(t, "0.000025", balance.Unconfirmed.String(), "unexpected unconfirmed")
+	assert.Equal(t, "0.5", balance.Unmatured.String(), "unexpected unmatured")
+
+	// the addresses of another net are skipped
+	balance, err = bcc.BalanceGet(currency, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Nil(t, err, "unexpected error")
+	assert.True(t, balance.Confirmed.Equal(decimal.Zero), "unexpected confirmed")
+}
+
+func TestBtcChainConnector_Utxos(t *testing.T) {
+	server := coreServer(t, map[string]string{
+		`getaddressutxos[{"addresses":["mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX"]}]`: `[
+			{"address":"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX","txid":"bb","outputIndex":1,"script":"76a9","satoshis":120000000,"height":200},
+			{"address":"mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX","txid":"aa","outputIndex":0,"script":"76a9","satoshis":5000,"height":100}]`,
+	})
+	defer server.Close()
+	bcc := &BtcChainConnector{chain: &chaincfg.TestNet3Params, CoreClient: NewClient(server.URL, 5)}
+	currency := Currency{Code: "BTC", Precision: 8}
+
+	utxos, err := bcc.Utxos(currency, "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, []connector.UtxoStruct{
+		{TxHash: "aa", Height: 100, TxPos: 0, Value: decimal.New(5000, -8), Address: "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX"},
+		{TxHash: "bb", Height: 200, TxPos: 1, Value: decimal.New(120000000, -8), Address: "mqpaRTpgKSnbeaqWmS9cwEobjtFVHsmjuX"},
+	}, utxos, "unexpected utxos")
+
+	_, err = bcc.Utxos(currency)
+	assert.NotNil(t, err, "error expected for empty addresses")
+}
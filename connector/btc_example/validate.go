@@ -0,0 +1,111 @@
+package btc_example
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+type mempoolAcceptResult struct {
+	TxID         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	VSize        int64  `json:"vsize"`
+	RejectReason string `json:"reject-reason"`
+	Fees         *struct {
+		Base decimal.Decimal `json:"base"`
+	} `json:"fees"`
+}
+
+// rejectReasons maps the reject reasons of the node to the typed errors.
+var rejectReasons = []struct {
+	reason string
+	err    error
+}{
+	{scriptVerifyFailureErr, connector.TxPermanentFailure},
+	{"missing-inputs", connector.ErrTxInputsMissing},
+	{"bad-txns-inputs-missingorspent", connector.ErrTxInputsMissing},
+	{"txn-mempool-conflict", connector.ErrTxConflict},
+	{"txn-already-known", connector.ErrTxAlreadyKnown},
+	{"txn-already-in-mempool", connector.ErrTxAlreadyKnown},
+	{"min relay fee not met", connector.ErrTxFeeTooLow},
+	{"mempool min fee not met", connector.ErrTxFeeTooLow},
+	{"insufficient fee", connector.ErrTxFeeTooLow},
+	{"max-fee-exceeded", connector.ErrTxFeeTooHigh},
+	{"absurdly-high-fee", connector.ErrTxFeeTooHigh},
+	{"bad-txns-too-many-sigops", &StandardError{Input: -1, Output: -1, Err: ErrSigOps}},
+	{"multi-op-return", &StandardError{Input: -1, Output: -1, Err: ErrMultiOpReturn}},
+	{"scriptsig-not-pushonly", &StandardError{Input: -1, Output: -1, Err: ErrScriptSigNotPushOnly}},
+	{"scriptsig-size", &StandardError{Input: -1, Output: -1, Err: ErrScriptSigSize}},
+	{"scriptpubkey", &StandardError{Input: -1, Output: -1, Err: ErrScriptPubKey}},
+	{"tx-size", &StandardError{Input: -1, Output: -1, Err: ErrTxSize}},
+	{"dust", &StandardError{Input: -1, Output: -1, Err: ErrDust}},
+	{"version", &StandardError{Input: -1, Output: -1, Err: ErrTxVersion}},
+}
+
+// TxValidate checks the signed tx with testmempoolaccept of the node.
+func (bcc *BtcChainConnector) TxValidate(txHex string) (*connector.TxValidation, error) {
+	if bcc.Client == nil {
+		return nil, connector.ErrClientNil
+	}
+	param, err := json.Marshal([]string{txHex})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bcc.Client.RawRequest("testmempoolaccept", []json.RawMessage{param})
+	if err != nil {
+		return nil, fmt.Errorf("testmempoolaccept: %s", err.Error())
+	}
+	var results []mempoolAcceptResult
+	err = json.Unmarshal(resp, &results)
+	if err != nil {
+		return nil, fmt.Errorf("testmempoolaccept: %s", err.Error())
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("testmempoolaccept: unexpected results quantity %d", len(results))
+	}
+
+	result := results[0]
+	validation := &connector.TxValidation{
+		TxID:         result.TxID,
+		Allowed:      result.Allowed,
+		VSize:        result.VSize,
+		RejectReason: result.RejectReason,
+	}
+	if result.Fees != nil {
+		validation.Fee = big.NewInt(result.Fees.Base.Mul(decimal.New(1, int32(btcPrecision))).IntPart())
+	}
+	if !result.Allowed {
+		validation.Err = RejectError(result.RejectReason)
+	}
+	return validation, nil
+}
+
+// RejectError maps the reject reason of the node to the typed error, connector.ErrTxRejected is returned for unknown reasons.
+func RejectError(reason string) error {
+	token := rejectToken(reason)
+	for _, r := range rejectReasons {
+		if token == r.reason {
+			return r.err
+		}
+	}
+	return connector.ErrTxRejected
+}
+
+// rejectToken returns the reject reason without the code of the old nodes ("16: ")
+// and the details following ": ", " (" or ", ".
+func rejectToken(reason string) string {
+	if i := strings.Index(reason, ": "); i > 0 && strings.Trim(reason[:i], "0123456789") == "" {
+		reason = reason[i+2:]
+	}
+	for _, sep := range []string{": ", " (", ", "} {
+		if i := strings.Index(reason, sep); i >= 0 {
+			reason = reason[:i]
+		}
+	}
+	return strings.TrimSpace(reason)
+}
@@ -0,0 +1,123 @@
+package btc_example
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stanche/crypto-interface/connector"
+
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBtcChainConnector_TxValidate(t *testing.T) {
+	var result string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     json.RawMessage   `json:"id"`
+		}
+		_ = json.Unmarshal(body, &req)
+		if req.Method != "testmempoolaccept" || len(req.Params) != 1 || string(req.Params[0]) != `["0200"]` {
+			http.Error(w, "unexpected request: "+string(body), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"result":%s,"error":null,"id":%s}`, result, req.ID)
+	}))
+	defer server.Close()
+
+	client, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		HTTPPostMode: true,
+		DisableTLS:   true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcc := &BtcChainConnector{Client: client}
+
+	tests := []struct {
+		name   string
+		result string
+		want   *connector.TxValidation
+	}{
+		{
+			name:   "allowed",
+			result: `[{"txid":"ab","wtxid":"ab","allowed":true,"vsize":141,"fees":{"base":0.0000141}}]`,
+			want:   &connector.TxValidation{TxID: "ab", Allowed: true, VSize: 141, Fee: big.NewInt(1410)},
+		},
+		{
+			name:   "dust",
+			result: `[{"txid":"ab","allowed":false,"reject-reason":"dust"}]`,
+			want: &connector.TxValidation{TxID: "ab", RejectReason: "dust",
+				Err: &StandardError{Input: -1, Output: -1, Err: ErrDust}},
+		},
+		{
+			name:   "old node reason with code",
+			result: `[{"txid":"ab","allowed":false,"reject-reason":"16: mandatory-script-verify-flag-failed (Signature must be zero for failed CHECK(MULTI)SIG operation)"}]`,
+			want: &connector.TxValidation{TxID: "ab",
+				RejectReason: "16: mandatory-script-verify-flag-failed (Signature must be zero for failed CHECK(MULTI)SIG operation)",
+				Err:          connector.TxPermanentFailure},
+		},
+		{
+			name:   "missing inputs",
+			result: `[{"txid":"ab","allowed":false,"reject-reason":"missing-inputs"}]`,
+			want:   &connector.TxValidation{TxID: "ab", RejectReason: "missing-inputs", Err: connector.ErrTxInputsMissing},
+		},
+		{
+			name:   "unknown reason",
+			result: `[{"txid":"ab","allowed":false,"reject-reason":"something-new"}]`,
+			want:   &connector.TxValidation{TxID: "ab", RejectReason: "something-new", Err: connector.ErrTxRejected},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result = tt.result
+			got, err := bcc.TxValidate("0200")
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.want, got, "unexpected validation")
+		})
+	}
+
+	result = `[]`
+	_, err = bcc.TxValidate("0200")
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestRejectError(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   error
+	}{
+		{"dust", &StandardError{Input: -1, Output: -1, Err: ErrDust}},
+		{"64: dust", &StandardError{Input: -1, Output: -1, Err: ErrDust}},
+		{"16: mandatory-script-verify-flag-failed (Signature must be zero for failed CHECK(MULTI)SIG operation)",
+			connector.TxPermanentFailure},
+		{"min relay fee not met, 100 < 141", connector.ErrTxFeeTooLow},
+		{"66: min relay fee not met", connector.ErrTxFeeTooLow},
+		{"insufficient fee, rejecting replacement 1234; new feerate 0.00001000 BTC/kvB <= old feerate 0.00001000 BTC/kvB",
+			connector.ErrTxFeeTooLow},
+		{"absurdly-high-fee, 1000000 > 100000", connector.ErrTxFeeTooHigh},
+		{"tx-size", &StandardError{Input: -1, Output: -1, Err: ErrTxSize}},
+		// the reasons containing the known ones are not matched
+		{"tx-size-small", connector.ErrTxRejected},
+		{"non-mandatory-script-verify-flag (Using OP_CODESEPARATOR in non-witness script)", connector.ErrTxRejected},
+		{"bad-txns-nonstandard-inputs", connector.ErrTxRejected},
+		{"bad-version", connector.ErrTxRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			assert.Equal(t, tt.want, RejectError(tt.reason), "unexpected error")
+		})
+	}
+}
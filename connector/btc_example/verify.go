@@ -0,0 +1,226 @@
+package btc_example
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example/script"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+type (
+	// PrevOut describes the output spent by a transaction input.
+	PrevOut struct {
+		PkScript []byte
+		Amount   int64
+	}
+
+	// SigHashFunc calculates the digest signed by the cosigners for the input idx.
+	SigHashFunc func(subScript []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amount int64) ([]byte, error)
+
+	// SignatureError describes a signature which failed verification.
+	// Cosigner is the index of the cosigner xpub in the input script or -1 if the signature
+	// can not be attributed to any cosigner.
+	SignatureError struct {
+		Input    int
+		Cosigner int
+		Err      error
+	}
+)
+
+// ErrSignatureInvalid is returned when a signature does not match the cosigner key.
+var ErrSignatureInvalid = fmt.Errorf("signature verification failed")
+
+func (e *SignatureError) Error() string {
+	if e.Cosigner < 0 {
+		return fmt.Sprintf("input %d: %s", e.Input, e.Err.Error())
+	}
+	return fmt.Sprintf("input %d, cosigner %d: %s", e.Input, e.Cosigner, e.Err.Error())
+}
+
+// BtcSigHash calculates the legacy BTC signature hash. The amount is not used.
+func BtcSigHash(subScript []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, _ int64) ([]byte, error) {
+	return txscript.CalcSignatureHash(subScript, hashType, tx, idx)
+}
+
+// VerifySignature checks a single cosigner signature (as returned by the signer, with the hash type appended)
+// against the unsigned tx built with TxBuild. cosigner is the index of the xpub in the input script.
+func VerifySignature(txHex string, input, cosigner int, signature []byte, amount int64, sigHash SigHashFunc) error {
+	msgTx, err := decodeTx(txHex)
+	if err != nil {
+		return err
+	}
+	if input < 0 || input >= len(msgTx.TxIn) {
+		return fmt.Errorf("invalid input index %d", input)
+	}
+	m, pubkeys, err := inputPubkeys(msgTx, input)
+	if err != nil {
+		return err
+	}
+	_, err = verifyCosigner(msgTx, input, m, pubkeys, cosigner, signature, amount, sigHash)
+	if err != nil {
+		return &SignatureError{Input: input, Cosigner: cosigner, Err: err}
+	}
+	return nil
+}
+
+// VerifySignatures checks the signatures to be combined by TxRebuild with the unsigned tx.
+// Every signature shall match a distinct cosigner and follow the order of the sorted multisig keys.
+// amounts are the values of the spent outputs; they may be nil for the legacy BTC signature hash.
+func VerifySignatures(txHex string, signatures connector.TxSignatures, amounts []int64, sigHash SigHashFunc) error {
+	msgTx, err := decodeTx(txHex)
+	if err != nil {
+		return err
+	}
+	countTxIn := len(msgTx.TxIn)
+	if countTxIn != len(signatures) {
+		return fmt.Errorf("inconsistent tx inputs and signatures quantity: %d ~ %d", countTxIn, len(signatures))
+	}
+	if amounts != nil && len(amounts) != countTxIn {
+		return fmt.Errorf("inconsistent tx inputs and amounts quantity: %d ~ %d", countTxIn, len(amounts))
+	}
+	for indexTxIn := 0; indexTxIn < countTxIn; indexTxIn++ {
+		m, pubkeys, err := inputPubkeys(msgTx, indexTxIn)
+		if err != nil {
+			return err
+		}
+		if len(signatures[indexTxIn]) != int(m) {
+			return fmt.Errorf("inconsistent signatures (%d, expected %d) for input %d",
+				len(signatures[indexTxIn]), m, indexTxIn)
+		}
+		var amount int64
+		if amounts != nil {
+			amount = amounts[indexTxIn]
+		}
+
+		lastSorted := -1
+		for j, sigHex := range signatures[indexTxIn] {
+			sig, err := hex.DecodeString(sigHex)
+			if err != nil {
+				return &SignatureError{Input: indexTxIn, Cosigner: -1, Err: fmt.Errorf("signature %d: %s", j, err.Error())}
+			}
+			cosigner, indexSorted := -1, -1
+			for k := range pubkeys {
+				indexSorted, err = verifyCosigner(msgTx, indexTxIn, m, pubkeys, k, sig, amount, sigHash)
+				if err == nil {
+					cosigner = k
+					break
+				}
+			}
+			if cosigner < 0 {
+				return &SignatureError{Input: indexTxIn, Cosigner: -1,
+					Err: fmt.Errorf("signature %d: %s", j, ErrSignatureInvalid.Error())}
+			}
+			// OP_CHECKMULTISIG expects signatures in the order of the sorted keys
+			if indexSorted <= lastSorted {
+				return &SignatureError{Input: indexTxIn, Cosigner: cosigner,
+					Err: fmt.Errorf("signature %d is duplicated or out of order", j)}
+			}
+			lastSorted = indexSorted
+		}
+	}
+	return nil
+}
+
+// TxVerify runs the script engine over every input of the signed tx.
+// It supports the BTC signature hash only, as btcd engine is not aware of SIGHASH_FORKID.
+func TxVerify(txHex string, prevOuts []PrevOut) error {
+	msgTx, err := decodeTx(txHex)
+	if err != nil {
+		return err
+	}
+	if len(prevOuts) != len(msgTx.TxIn) {
+		return fmt.Errorf("inconsistent tx inputs and prevouts quantity: %d ~ %d", len(msgTx.TxIn), len(prevOuts))
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx)
+	for indexTxIn := range msgTx.TxIn {
+		vm, err := txscript.NewEngine(prevOuts[indexTxIn].PkScript, msgTx, indexTxIn,
+			txscript.StandardVerifyFlags, nil, sigHashes, prevOuts[indexTxIn].Amount)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return &SignatureError{Input: indexTxIn, Cosigner: -1, Err: err}
+		}
+	}
+	return nil
+}
+
+// TxRebuildVerified checks the signatures, combines them with the tx and runs the script engine over the result.
+func (bcc *BtcChainConnector) TxRebuildVerified(txHex string, signatures connector.TxSignatures, prevOuts []PrevOut) (string, error) {
+	err := VerifySignatures(txHex, signatures, nil, BtcSigHash)
+	if err != nil {
+		return "", err
+	}
+	signedHex, err := TxRebuildBtc(txHex, signatures, bcc.standard())
+	if err != nil {
+		return "", err
+	}
+	err = TxVerify(signedHex, prevOuts)
+	if err != nil {
+		return "", err
+	}
+	return signedHex, nil
+}
+
+func decodeTx(txHex string) (*wire.MsgTx, error) {
+	txData, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := btcutil.NewTxFromBytes(txData)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("decoded tx is nil")
+	}
+	return tx.MsgTx(), nil
+}
+
+// inputPubkeys returns the cosigners keys of the unsigned input in the order of xpubs in the script.
+func inputPubkeys(msgTx *wire.MsgTx, idx int) (byte, []*btcec.PublicKey, error) {
+	redeemScript, err := script.RedeemScriptFromTxin(msgTx.TxIn[idx])
+	if err != nil {
+		return 0, nil, err
+	}
+	m, pubkeys, _, _, err := script.PubkeysIndexPathFromScript(redeemScript, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	return m, pubkeys, nil
+}
+
+// verifyCosigner checks the signature against the key of the cosigner and returns the key index in the sorted list.
+func verifyCosigner(msgTx *wire.MsgTx, idx int, m byte, pubkeys []*btcec.PublicKey,
+	cosigner int, signature []byte, amount int64, sigHash SigHashFunc) (int, error) {
+
+	if cosigner < 0 || cosigner >= len(pubkeys) {
+		return -1, fmt.Errorf("invalid cosigner index '%d'", cosigner)
+	}
+	if len(signature) < 2 {
+		return -1, fmt.Errorf("signature is too short")
+	}
+	msScript, indexSorted, err := script.MultisigScriptFromPubkeys(m, pubkeys, cosigner)
+	if err != nil {
+		return -1, err
+	}
+	hashType := txscript.SigHashType(signature[len(signature)-1])
+	sig, err := btcec.ParseDERSignature(signature[:len(signature)-1], btcec.S256())
+	if err != nil {
+		return -1, err
+	}
+	hash, err := sigHash(msScript, hashType, msgTx, idx, amount)
+	if err != nil {
+		return -1, err
+	}
+	if !sig.Verify(hash, pubkeys[cosigner]) {
+		return -1, ErrSignatureInvalid
+	}
+	return indexSorted, nil
+}
@@ -0,0 +1,105 @@
+package btc_example
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/netparams"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	verifyTxHex      = "0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000"
+	verifySignatureA = "30440220596c276e66186b98e1b190a626a94b30760718c99f2db32d2e165e7075c3f67302207fd6cd72995239952769b7ff2c61f4e952a05a3a9970f564f09f3efe51feded201"
+	verifySignatureB = "3045022100cc08a8be0f1021f9029b0fd428a0d1575c39e215ee396672eb70dd350f5b17d30220075c8eaf70f6d0dfcdf379c4a7f98ae7eb64ef765ac8d7be1b30f6e7e4c4181301"
+)
+
+func TestVerifySignatures(t *testing.T) {
+	cases := []struct {
+		name         string
+		signatures   connector.TxSignatures
+		wantErr      bool
+		wantCosigner int
+	}{
+		{
+			name:       "valid signatures",
+			signatures: connector.TxSignatures{{verifySignatureA, verifySignatureB}},
+		},
+		{
+			name:         "signatures out of order",
+			signatures:   connector.TxSignatures{{verifySignatureB, verifySignatureA}},
+			wantErr:      true,
+			wantCosigner: 0,
+		},
+		{
+			name:         "duplicated signature",
+			signatures:   connector.TxSignatures{{verifySignatureA, verifySignatureA}},
+			wantErr:      true,
+			wantCosigner: 0,
+		},
+		{
+			name:         "foreign signature",
+			signatures:   connector.TxSignatures{{verifySignatureA, verifySignatureA[:20] + "00" + verifySignatureA[22:]}},
+			wantErr:      true,
+			wantCosigner: -1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifySignatures(verifyTxHex, tc.signatures, nil, BtcSigHash)
+			if !tc.wantErr {
+				assert.Nil(t, err, "unexpected error")
+				return
+			}
+			sigErr, ok := err.(*SignatureError)
+			if assert.True(t, ok, "unexpected error type: %v", err) {
+				assert.Equal(t, 0, sigErr.Input, "unexpected input")
+				assert.Equal(t, tc.wantCosigner, sigErr.Cosigner, "unexpected cosigner")
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	sigA, _ := hex.DecodeString(verifySignatureA)
+	sigB, _ := hex.DecodeString(verifySignatureB)
+
+	assert.Nil(t, VerifySignature(verifyTxHex, 0, 0, sigA, 0, BtcSigHash), "unexpected error for signer-a")
+	assert.Nil(t, VerifySignature(verifyTxHex, 0, 1, sigB, 0, BtcSigHash), "unexpected error for signer-b")
+
+	err := VerifySignature(verifyTxHex, 0, 2, sigA, 0, BtcSigHash)
+	assert.Equal(t, &SignatureError{Input: 0, Cosigner: 2, Err: ErrSignatureInvalid}, err, "unexpected error")
+}
+
+func TestTxVerify(t *testing.T) {
+	signedHex, err := TxRebuildBtc(verifyTxHex, connector.TxSignatures{{verifySignatureA, verifySignatureB}}, netparams.BtcStandardPolicy)
+	assert.Nil(t, err, "unexpected rebuild error")
+
+	msgTx, err := decodeTx(signedHex)
+	assert.Nil(t, err, "unexpected decode error")
+	pushes, err := txscript.PushedData(msgTx.TxIn[0].SignatureScript)
+	assert.Nil(t, err, "unexpected script error")
+	p2sh, err := btcutil.NewAddressScriptHash(pushes[len(pushes)-1], &chaincfg.TestNet3Params)
+	assert.Nil(t, err, "unexpected address error")
+	pkScript, err := txscript.PayToAddrScript(p2sh)
+	assert.Nil(t, err, "unexpected pkScript error")
+
+	assert.Nil(t, TxVerify(signedHex, []PrevOut{{PkScript: pkScript}}), "unexpected verification error")
+
+	nc := &BtcChainConnector{}
+	got, err := nc.TxRebuildVerified(verifyTxHex, connector.TxSignatures{{verifySignatureA, verifySignatureB}},
+		[]PrevOut{{PkScript: pkScript}})
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, signedHex, got, "unexpected tx")
+
+	otherScript := append([]byte{}, pkScript...)
+	otherScript[2] ^= 0xff
+	err = TxVerify(signedHex, []PrevOut{{PkScript: otherScript}})
+	_, ok := err.(*SignatureError)
+	assert.True(t, ok, "unexpected error: %v", err)
+}
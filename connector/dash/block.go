@@ -0,0 +1,60 @@
+package dash
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// specialTxVersion is the minimal version of DIP2 special transactions.
+const specialTxVersion = 3
+
+// DecodeBlock decodes the Dash block. The DIP2 special transactions (the coinbase, the masternode and quorum ones)
+// have the extra payload which is not supported by BTC format, they are dropped as they carry no payments
+// to the wallet addresses.
+func DecodeBlock(data []byte) (*wire.MsgBlock, error) {
+	r := bytes.NewReader(data)
+	var block wire.MsgBlock
+	if err := block.Header.Deserialize(r); err != nil {
+		return nil, err
+	}
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(r.Len()) {
+		return nil, fmt.Errorf("too many transactions %d", count)
+	}
+	block.Transactions = make([]*wire.MsgTx, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var tx wire.MsgTx
+		// Dash has no segwit, special transactions may have no inputs
+		if err = tx.DeserializeNoWitness(r); err != nil {
+			return nil, fmt.Errorf("tx %d: %s", i, err.Error())
+		}
+		if !isSpecialTx(&tx) {
+			block.Transactions = append(block.Transactions, &tx)
+			continue
+		}
+		size, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d payload: %s", i, err.Error())
+		}
+		if size > uint64(r.Len()) {
+			return nil, fmt.Errorf("tx %d: invalid payload size %d", i, size)
+		}
+		if _, err = io.CopyN(ioutil.Discard, r, int64(size)); err != nil {
+			return nil, fmt.Errorf("tx %d payload: %s", i, err.Error())
+		}
+	}
+	return &block, nil
+}
+
+// isSpecialTx checks the type in the upper half of the version.
+func isSpecialTx(tx *wire.MsgTx) bool {
+	version := uint32(tx.Version)
+	return version&0xffff >= specialTxVersion && version>>16 != 0
+}
@@ -0,0 +1,40 @@
+package dash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeBlock(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), []byte{0x51}, nil))
+	tx.AddTxOut(wire.NewTxOut(100000000, []byte{0x76, 0xa9}))
+
+	// the coinbase (type 5) and the quorum commitment (type 6) without inputs and outputs
+	cbTx := wire.NewMsgTx(3 | 5<<16)
+	cbTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), []byte{0x01, 0x02}, nil))
+	cbTx.AddTxOut(wire.NewTxOut(5000, []byte{0x76, 0xa9}))
+	qcTx := wire.NewMsgTx(3 | 6<<16)
+
+	var b bytes.Buffer
+	assert.Nil(t, (&wire.BlockHeader{Version: 0x20000000, PrevBlock: chainhash.Hash{7}}).Serialize(&b), "unexpected error")
+	b.Write([]byte{0x03})
+	assert.Nil(t, cbTx.SerializeNoWitness(&b), "unexpected error")
+	b.Write([]byte{0x03, 0x01, 0x02, 0x03})
+	assert.Nil(t, tx.SerializeNoWitness(&b), "unexpected error")
+	assert.Nil(t, qcTx.SerializeNoWitness(&b), "unexpected error")
+	b.Write([]byte{0x01, 0xff})
+
+	block, err := DecodeBlock(b.Bytes())
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, chainhash.Hash{7}, block.Header.PrevBlock, "unexpected previous block")
+	assert.Len(t, block.Transactions, 1, "special transactions shall be dropped")
+	assert.Equal(t, tx.TxHash(), block.Transactions[0].TxHash(), "unexpected tx")
+
+	_, err = DecodeBlock(b.Bytes()[:b.Len()-1])
+	assert.NotNil(t, err, "error expected for truncated payload")
+}
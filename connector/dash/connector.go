@@ -0,0 +1,56 @@
+package dash
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+type (
+	dashChainConnector struct {
+		*btc_example.BtcChainConnector
+	}
+)
+
+// Params returns the chain params for the ChainConfig of the wallet, "testnet" and "regtest" use the test network.
+func Params(chainConfig string) *chaincfg.Params {
+	switch chainConfig {
+	case "testnet", "regtest":
+		return &netparams.DashTestNetParams
+	}
+	return &netparams.DashMainNetParams
+}
+
+// NewChainConnector returns the Dash connector on btc_example core, the special transactions are dropped from the blocks.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (btc_example.IBtcChainConnector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	bcc, err := btc_example.NewChainConnector(walletID, cfg, txBatchSize, Params(cfg.ChainConfig))
+	if err != nil {
+		return nil, err
+	}
+	bcc.BlockDecoderSet(DecodeBlock)
+	return &dashChainConnector{BtcChainConnector: bcc}, nil
+}
+
+// NewBlockChainImporter creates the importer of Dash blocks.
+func NewBlockChainImporter(node connector.NodeParams, chainConfig string, txBatchSize int) (connector.BlockChainImporter, error) {
+	return btc_example.NewBlockChainImporterWithOptions(node, *Params(chainConfig), txBatchSize,
+		btc_example.ImporterOptions{DecodeBlock: DecodeBlock})
+}
+
+// RBFSet does nothing, Dash does not support replace-by-fee.
+func (c *dashChainConnector) RBFSet(bool) {}
+
+// TxBump builds a CPFP child, Dash does not support replace-by-fee.
+func (c *dashChainConnector) TxBump(walletData *connector.WalletSignStruct, txHex string, params btc_example.TxBumpParams) (string, error) {
+	if params.Method != btc_example.BumpCPFP {
+		return "", fmt.Errorf("unsupported bump method %d: DASH supports CPFP only", params.Method)
+	}
+	return c.BtcChainConnector.TxBump(walletData, txHex, params)
+}
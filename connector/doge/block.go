@@ -0,0 +1,75 @@
+package doge
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// auxPowVersion is the version bit of the merge-mined blocks having the AuxPoW after the header.
+const auxPowVersion = 1 << 8
+
+// DecodeBlock decodes the Dogecoin block dropping the AuxPoW of the merge-mined ones.
+func DecodeBlock(data []byte) (*wire.MsgBlock, error) {
+	r := bytes.NewReader(data)
+	var block wire.MsgBlock
+	if err := block.Header.Deserialize(r); err != nil {
+		return nil, err
+	}
+	if block.Header.Version&auxPowVersion != 0 {
+		if err := skipAuxPow(r); err != nil {
+			return nil, fmt.Errorf("auxpow: %s", err.Error())
+		}
+	}
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(r.Len()) {
+		return nil, fmt.Errorf("too many transactions %d", count)
+	}
+	block.Transactions = make([]*wire.MsgTx, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var tx wire.MsgTx
+		// Dogecoin has no segwit
+		if err = tx.DeserializeNoWitness(r); err != nil {
+			return nil, fmt.Errorf("tx %d: %s", i, err.Error())
+		}
+		block.Transactions = append(block.Transactions, &tx)
+	}
+	return &block, nil
+}
+
+// skipAuxPow reads the AuxPoW: the parent coinbase tx, the parent block hash,
+// the coinbase and the chain merkle branches with their indexes and the parent block header.
+func skipAuxPow(r *bytes.Reader) error {
+	var coinbase wire.MsgTx
+	if err := coinbase.DeserializeNoWitness(r); err != nil {
+		return err
+	}
+	if err := skip(r, chainhash.HashSize); err != nil {
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		n, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return err
+		}
+		if n > uint64(r.Len())/chainhash.HashSize {
+			return fmt.Errorf("invalid merkle branch length %d", n)
+		}
+		if err = skip(r, int64(n)*chainhash.HashSize+4); err != nil {
+			return err
+		}
+	}
+	return skip(r, wire.MaxBlockHeaderPayload)
+}
+
+func skip(r io.Reader, n int64) error {
+	_, err := io.CopyN(ioutil.Discard, r, n)
+	return err
+}
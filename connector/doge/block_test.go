@@ -0,0 +1,74 @@
+package doge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTx(value int64) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), []byte{0x51}, nil))
+	tx.AddTxOut(wire.NewTxOut(value, []byte{0x76, 0xa9}))
+	return tx
+}
+
+func serializeBlock(t *testing.T, header wire.BlockHeader, auxPow []byte, txs ...*wire.MsgTx) []byte {
+	var b bytes.Buffer
+	assert.Nil(t, header.Serialize(&b), "unexpected error")
+	b.Write(auxPow)
+	assert.Nil(t, wire.WriteVarInt(&b, 0, uint64(len(txs))), "unexpected error")
+	for _, tx := range txs {
+		assert.Nil(t, tx.SerializeNoWitness(&b), "unexpected error")
+	}
+	return b.Bytes()
+}
+
+func TestDecodeBlock(t *testing.T) {
+	tx := testTx(100000000)
+
+	// parent coinbase, parent block hash, coinbase branch of 2 hashes, empty chain branch, parent header
+	var auxPow bytes.Buffer
+	assert.Nil(t, testTx(5000).SerializeNoWitness(&auxPow), "unexpected error")
+	auxPow.Write(bytes.Repeat([]byte{0xaa}, 32))
+	auxPow.Write([]byte{0x02})
+	auxPow.Write(bytes.Repeat([]byte{0xbb}, 2*32+4))
+	auxPow.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x00})
+	auxPow.Write(bytes.Repeat([]byte{0xcc}, 80))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{
+			name: "merge-mined block",
+			data: serializeBlock(t, wire.BlockHeader{Version: 0x00620104, PrevBlock: chainhash.Hash{7}}, auxPow.Bytes(), tx),
+		},
+		{
+			name: "block without auxpow",
+			data: serializeBlock(t, wire.BlockHeader{Version: 0x00620004, PrevBlock: chainhash.Hash{7}}, nil, tx),
+		},
+		{
+			name:    "truncated auxpow",
+			data:    serializeBlock(t, wire.BlockHeader{Version: 0x00620104}, auxPow.Bytes()[:100]),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := DecodeBlock(tt.data)
+			if tt.wantErr {
+				assert.NotNil(t, err, "error expected")
+				return
+			}
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, chainhash.Hash{7}, block.Header.PrevBlock, "unexpected previous block")
+			assert.Len(t, block.Transactions, 1, "unexpected transactions")
+			assert.Equal(t, tx.TxHash(), block.Transactions[0].TxHash(), "unexpected tx")
+		})
+	}
+}
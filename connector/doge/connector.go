@@ -0,0 +1,38 @@
+package doge
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// Params returns the chain params for the ChainConfig of the wallet, "testnet" uses the test network.
+func Params(chainConfig string) *chaincfg.Params {
+	if chainConfig == "testnet" {
+		return &netparams.DogeTestNetParams
+	}
+	return &netparams.DogeMainNetParams
+}
+
+// NewChainConnector returns the Dogecoin connector on btc_example core, the blocks are decoded without AuxPoW.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (btc_example.IBtcChainConnector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	bcc, err := btc_example.NewChainConnector(walletID, cfg, txBatchSize, Params(cfg.ChainConfig))
+	if err != nil {
+		return nil, err
+	}
+	bcc.BlockDecoderSet(DecodeBlock)
+	return bcc, nil
+}
+
+// NewBlockChainImporter creates the importer of Dogecoin blocks.
+func NewBlockChainImporter(node connector.NodeParams, chainConfig string, txBatchSize int) (connector.BlockChainImporter, error) {
+	return btc_example.NewBlockChainImporterWithOptions(node, *Params(chainConfig), txBatchSize,
+		btc_example.ImporterOptions{DecodeBlock: DecodeBlock})
+}
@@ -0,0 +1,120 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Client is the JSON-RPC client of the Ethereum node.
+	Client struct {
+		URL        string
+		HTTPClient *http.Client
+		id         uint64
+	}
+
+	rpcRequest struct {
+		JSONRPC string        `json:"jsonrpc"`
+		ID      uint64        `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+	}
+
+	rpcResponse struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+
+	// RPCError is the error reported by the node.
+	RPCError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// hexBig is the quantity of JSON-RPC: 0x prefixed hex without leading zeroes.
+	hexBig big.Int
+)
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// NewClient creates new Client instance
+func NewClient(rpcURL string, timeout int) *Client {
+	return &Client{
+		URL:        rpcURL,
+		HTTPClient: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}
+}
+
+// Call calls the method and decodes the result into res. A null result leaves res unchanged.
+func (c *Client) Call(res interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: atomic.AddUint64(&c.id, 1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Post(c.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%s: %s", method, err.Error())
+	}
+	defer resp.Body.Close()
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: %s", method, err.Error())
+	}
+	var rpcResp rpcResponse
+	if err = json.Unmarshal(respBytes, &rpcResp); err != nil {
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("%s: http status: %s (%d)", method, resp.Status, resp.StatusCode)
+		}
+		return fmt.Errorf("%s: %s", method, err.Error())
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if len(rpcResp.Result) == 0 || string(rpcResp.Result) == "null" {
+		return nil
+	}
+	if err = json.Unmarshal(rpcResp.Result, res); err != nil {
+		return fmt.Errorf("%s: %s", method, err.Error())
+	}
+	return nil
+}
+
+// MarshalJSON encodes the quantity.
+func (b *hexBig) MarshalJSON() ([]byte, error) {
+	return json.Marshal("0x" + (*big.Int)(b).Text(16))
+}
+
+// UnmarshalJSON decodes the quantity.
+func (b *hexBig) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "0x") || len(s) < 3 {
+		return fmt.Errorf("invalid quantity %s", s)
+	}
+	if _, ok := (*big.Int)(b).SetString(s[2:], 16); !ok {
+		return fmt.Errorf("invalid quantity %s", s)
+	}
+	return nil
+}
+
+// toBig returns the quantity as big.Int, zero for nil.
+func (b *hexBig) toBig() *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set((*big.Int)(b))
+}
@@ -0,0 +1,499 @@
+package eth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/wedancedalot/decimal"
+
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/connector"
+)
+
+const (
+	ethPrecision      = 18
+	defaultTimeoutSec = 30
+	gwei              = 1000000000
+)
+
+type (
+	ethChainConnector struct {
+		connector.Connector
+		client *Client
+		// feeMax limits the gas price (fee cap) in wei, nil if not limited.
+		feeMax *big.Int
+	}
+
+	// Account is the utxos argument of TxBuild: the address sending the tx.
+	// Nonce replaces the pending nonce of the account if set, i.e. to replace the pending tx.
+	Account struct {
+		Address string
+		Nonce   *uint64
+	}
+
+	// callMsg is the tx of eth_call and eth_estimateGas.
+	callMsg struct {
+		From  string  `json:"from,omitempty"`
+		To    string  `json:"to"`
+		Value *hexBig `json:"value,omitempty"`
+		Data  string  `json:"data,omitempty"`
+	}
+
+	blockHeader struct {
+		Number        *hexBig `json:"number"`
+		BaseFeePerGas *hexBig `json:"baseFeePerGas"`
+	}
+
+	txReceipt struct {
+		TransactionHash   string   `json:"transactionHash"`
+		BlockNumber       *hexBig  `json:"blockNumber"`
+		Status            *hexBig  `json:"status"`
+		GasUsed           *hexBig  `json:"gasUsed"`
+		EffectiveGasPrice *hexBig  `json:"effectiveGasPrice"`
+		Logs              []*TxLog `json:"logs"`
+	}
+
+	// TxLog is the event log of the tx receipt.
+	TxLog struct {
+		Address  string   `json:"address"`
+		Topics   []string `json:"topics"`
+		Data     string   `json:"data"`
+		LogIndex *hexBig  `json:"logIndex"`
+		Removed  bool     `json:"removed"`
+	}
+
+	txInfo struct {
+		Hash        string  `json:"hash"`
+		BlockNumber *hexBig `json:"blockNumber"`
+		GasPrice    *hexBig `json:"gasPrice"`
+	}
+)
+
+// rejectReasons maps the errors of the node (geth) to the typed rejections.
+var rejectReasons = []struct {
+	reason string
+	err    error
+}{
+	{"already known", connector.ErrTxAlreadyKnown},
+	{"nonce too low", connector.ErrTxConflict},
+	{"replacement transaction underpriced", connector.ErrTxConflict},
+	{"insufficient funds", connector.ErrTxInputsMissing},
+	{"fee cap less than block base fee", connector.ErrTxFeeTooLow},
+	{"max fee per gas less than block base fee", connector.ErrTxFeeTooLow},
+	{"transaction underpriced", connector.ErrTxFeeTooLow},
+	{"exceeds the configured cap", connector.ErrTxFeeTooHigh},
+}
+
+// NewChainConnector creates the connector of Ethereum node. cfg.FeeMax limits the gas price in gwei.
+// The wallets of WalletTypeSafe spend from Safe multisig contracts.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams) (connector.IConnector, error) {
+	if cfg == nil || walletID <= 0 {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	c := &ethChainConnector{
+		Connector: connector.Connector{
+			WalletId:   walletID,
+			Currency:   cfg.Currency,
+			WalletType: cfg.Type,
+		},
+	}
+	if cfg.Active {
+		nodeURL, err := clientURL(cfg.Node)
+		if err != nil {
+			return nil, err
+		}
+		timeout := defaultTimeoutSec
+		if cfg.NodeTimeoutSec > 0 {
+			timeout = cfg.NodeTimeoutSec
+		}
+		c.client = NewClient(nodeURL, timeout)
+		if cfg.FeeMax > 0 {
+			c.feeMax = new(big.Int).Mul(big.NewInt(int64(cfg.FeeMax)), big.NewInt(gwei))
+		}
+	}
+	if cfg.Type == WalletTypeSafe {
+		return &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}, nil
+	}
+	return c, nil
+}
+
+// clientURL returns the node URL, the credentials are optional.
+func clientURL(cfg connector.NodeParams) (string, error) {
+	if cfg == nil || cfg.GetHost() == "" || cfg.GetPort() == 0 {
+		return "", fmt.Errorf("invalid config")
+	}
+	if cfg.GetUser() != "" {
+		return fmt.Sprintf("http://%s:%s@%s:%d", cfg.GetUser(), cfg.GetPassword(), cfg.GetHost(), cfg.GetPort()), nil
+	}
+	return fmt.Sprintf("http://%s:%d", cfg.GetHost(), cfg.GetPort()), nil
+}
+
+// call checks the client and calls the node method.
+func (c *ethChainConnector) call(res interface{}, method string, params ...interface{}) error {
+	if c.client == nil {
+		return connector.ErrClientNil
+	}
+	return c.client.Call(res, method, params...)
+}
+
+// ValidateAddress accepts the hex addresses, the mixed-case ones shall have the valid EIP-55 checksum.
+func (c *ethChainConnector) ValidateAddress(address string) (bool, error) {
+	return addreth.ValidateAddress(address), nil
+}
+
+// BalanceGet returns the total balance of the addresses. The unconfirmed amount is the change of the pending state,
+// it's negative if the coins are being spent. The invalid addresses are skipped.
+// The balance of ERC-20 token is returned if the currency has the token address.
+func (c *ethChainConnector) BalanceGet(currency connector.Currency, addresses ...string) (b connector.AddressBalance, err error) {
+	if len(addresses) == 0 {
+		return b, fmt.Errorf("unsupported params: BalanceGet.addresses are empty")
+	}
+	var token []byte
+	if isToken(currency) {
+		if token, err = addreth.ParseAddress(currency.GetTokenAddress()); err != nil {
+			return b, fmt.Errorf("invalid token address: %s", err.Error())
+		}
+	}
+	confirmed, pending := new(big.Int), new(big.Int)
+	for _, address := range addresses {
+		owner, err := addreth.ParseAddress(address)
+		if err != nil {
+			continue
+		}
+		latestBalance, err := c.balance(token, owner, "latest")
+		if err != nil {
+			return b, err
+		}
+		pendingBalance, err := c.balance(token, owner, "pending")
+		if err != nil {
+			return b, err
+		}
+		confirmed.Add(confirmed, latestBalance)
+		pending.Add(pending, pendingBalance)
+	}
+	exp := -int32(currency.GetPrecision())
+	b.Confirmed = decimal.NewFromBigInt(confirmed, exp)
+	b.Unconfirmed = decimal.NewFromBigInt(pending.Sub(pending, confirmed), exp)
+	return b, nil
+}
+
+// balance returns the balance of the owner in the block state, the token balance if token is set.
+func (c *ethChainConnector) balance(token, owner []byte, block string) (*big.Int, error) {
+	if token != nil {
+		return c.tokenBalance(token, owner, block)
+	}
+	var balance hexBig
+	if err := c.call(&balance, "eth_getBalance", addreth.ChecksumAddress(owner), block); err != nil {
+		return nil, err
+	}
+	return balance.toBig(), nil
+}
+
+// TxStatus returns the status of the tx by its hash. The status of the reverted tx is returned
+// with connector.TxPermanentFailure, the fee is paid for it anyway.
+func (c *ethChainConnector) TxStatus(txID string, blockNo uint64) (*connector.TxStatusStruct, error) {
+	var receipt *txReceipt
+	if err := c.call(&receipt, "eth_getTransactionReceipt", txID); err != nil {
+		return nil, err
+	}
+	if receipt == nil || receipt.BlockNumber == nil {
+		var tx *txInfo
+		if err := c.call(&tx, "eth_getTransactionByHash", txID); err != nil {
+			return nil, err
+		}
+		if tx == nil {
+			return nil, nil
+		}
+		// pending
+		return &connector.TxStatusStruct{}, nil
+	}
+	var latest hexBig
+	if err := c.call(&latest, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+	height := receipt.BlockNumber.toBig().Int64()
+	status := connector.NewTxStatusWithNonNeg(height, latest.toBig().Int64()-height+1)
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		// the receipts before London have no effective gas price, the legacy tx pays its gas price
+		var tx *txInfo
+		if err := c.call(&tx, "eth_getTransactionByHash", txID); err != nil {
+			return nil, err
+		}
+		if tx == nil || tx.GasPrice == nil {
+			return nil, fmt.Errorf("gas price of tx %s is unknown", txID)
+		}
+		gasPrice = tx.GasPrice
+	}
+	status.Fee = new(big.Int).Mul(receipt.GasUsed.toBig(), gasPrice.toBig())
+	if reverted(receipt) {
+		return &status, connector.TxPermanentFailure
+	}
+	return &status, nil
+}
+
+// reverted returns true for the failed tx. The receipts before Byzantium have no status.
+func reverted(receipt *txReceipt) bool {
+	return receipt.Status != nil && receipt.Status.toBig().Sign() == 0
+}
+
+// TxBuild builds the unsigned tx paying the single output from the Account passed as utxos.
+// The result is the signing payload: EIP-1559 tx if the chain has the base fee, EIP-155 legacy tx otherwise.
+// The fee is subtracted from the amount if the output has SubtractFeeFromAmount.
+// If the output currency has the token address, the tx calls ERC-20 transfer of the token contract,
+// the fee is paid in ETH and cannot be subtracted.
+func (c *ethChainConnector) TxBuild(walletData *connector.WalletSignStruct, utxos interface{}, output []connector.OutStruct) (string, error) {
+	if walletData != nil && len(walletData.XPubs) > 1 {
+		return "", fmt.Errorf("multisig is not supported by ETH accounts, use %s wallet type", WalletTypeSafe)
+	}
+	account, ok := utxos.(Account)
+	if !ok {
+		return "", fmt.Errorf("unexpected type of utxo input for ETH wallet: expected eth.Account got: %+v", utxos)
+	}
+	if len(output) != 1 {
+		return "", fmt.Errorf("one output expected, got %d", len(output))
+	}
+	if output[0].SubtractFeeFromAmount && isToken(output[0].Currency) {
+		return "", fmt.Errorf("fee cannot be subtracted from the token amount")
+	}
+	to, value, data, err := transferCall(output[0])
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := c.buildTx(account, to, value, data)
+	if err != nil {
+		return "", err
+	}
+	if output[0].SubtractFeeFromAmount {
+		tx.Value.Sub(tx.Value, tx.MaxFee())
+		if tx.Value.Sign() <= 0 {
+			return "", fmt.Errorf("amount %s does not cover the fee", output[0].Amount.String())
+		}
+	}
+	return hex.EncodeToString(tx.SigningPayload()), nil
+}
+
+// transferCall returns the call paying the output: the transfer to the recipient
+// or ERC-20 transfer of the token contract.
+func transferCall(output connector.OutStruct) (to []byte, value *big.Int, data []byte, err error) {
+	recipient, err := addreth.ParseAddress(output.Address)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	amount, err := amountUnits(output.Amount, output.Currency, ethPrecision)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !isToken(output.Currency) {
+		return recipient, amount, nil, nil
+	}
+	token, err := addreth.ParseAddress(output.Currency.GetTokenAddress())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid token address: %s", err.Error())
+	}
+	return token, new(big.Int), TransferCalldata(recipient, amount), nil
+}
+
+// amountUnits converts the amount into the indivisible units with the precision of the currency,
+// defaultPrecision is used if the currency is not set.
+func amountUnits(amount decimal.Decimal, currency connector.Currency, defaultPrecision uint8) (*big.Int, error) {
+	precision := defaultPrecision
+	if currency != nil {
+		precision = currency.GetPrecision()
+	}
+	units := amount.Mul(decimal.New(1, int32(precision)))
+	value, ok := new(big.Int).SetString(units.String(), 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %s", amount.String())
+	}
+	return value, nil
+}
+
+// buildTx estimates the gas of the tx from the account and fills the chain id, the nonce and the fees.
+func (c *ethChainConnector) buildTx(account Account, to []byte, value *big.Int, data []byte) (*Tx, error) {
+	from, err := addreth.ParseAddress(account.Address)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{To: to, Value: value, Data: data}
+	if tx.Gas, err = c.estimateGas(from, to, value, data); err != nil {
+		return nil, err
+	}
+	if err = c.prepareTx(account, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// estimateGas returns the gas of the call.
+func (c *ethChainConnector) estimateGas(from, to []byte, value *big.Int, data []byte) (uint64, error) {
+	msg := callMsg{From: addreth.ChecksumAddress(from), To: addreth.ChecksumAddress(to), Value: (*hexBig)(value)}
+	if len(data) > 0 {
+		msg.Data = "0x" + hex.EncodeToString(data)
+	}
+	var gas hexBig
+	if err := c.call(&gas, "eth_estimateGas", msg); err != nil {
+		return 0, fmt.Errorf("eth_estimateGas: %s", err.Error())
+	}
+	return gas.toBig().Uint64(), nil
+}
+
+// prepareTx fills the chain id, the nonce of the account and the fees of the tx.
+func (c *ethChainConnector) prepareTx(account Account, tx *Tx) error {
+	var chainID hexBig
+	if err := c.call(&chainID, "eth_chainId"); err != nil {
+		return err
+	}
+	tx.ChainID = chainID.toBig()
+
+	if account.Nonce != nil {
+		tx.Nonce = *account.Nonce
+	} else {
+		from, err := addreth.ParseAddress(account.Address)
+		if err != nil {
+			return err
+		}
+		var nonce hexBig
+		if err = c.call(&nonce, "eth_getTransactionCount", addreth.ChecksumAddress(from), "pending"); err != nil {
+			return err
+		}
+		tx.Nonce = nonce.toBig().Uint64()
+	}
+	return c.setFees(tx)
+}
+
+// setFees sets EIP-1559 fees: fee cap is twice the base fee plus the tip, so the tx stays valid
+// for several blocks with the growing base fee. The chains without the base fee get the legacy gas price.
+func (c *ethChainConnector) setFees(tx *Tx) error {
+	var latest blockHeader
+	if err := c.call(&latest, "eth_getBlockByNumber", "latest", false); err != nil {
+		return err
+	}
+	if latest.BaseFeePerGas == nil {
+		var gasPrice hexBig
+		if err := c.call(&gasPrice, "eth_gasPrice"); err != nil {
+			return err
+		}
+		tx.Type, tx.GasPrice = TxTypeLegacy, gasPrice.toBig()
+	} else {
+		var tip hexBig
+		if err := c.call(&tip, "eth_maxPriorityFeePerGas"); err != nil {
+			return err
+		}
+		tx.Type, tx.GasTipCap = TxTypeDynamicFee, tip.toBig()
+		tx.GasFeeCap = new(big.Int).Lsh(latest.BaseFeePerGas.toBig(), 1)
+		tx.GasFeeCap.Add(tx.GasFeeCap, tx.GasTipCap)
+	}
+	if c.feeMax != nil && tx.FeeCap().Cmp(c.feeMax) > 0 {
+		return fmt.Errorf("gas price %s exceeds the maximum %s", tx.FeeCap().String(), c.feeMax.String())
+	}
+	return nil
+}
+
+// TxRebuild combines the unsigned tx with the signature of the sender: r || s || recovery id, hex encoded.
+func (c *ethChainConnector) TxRebuild(txHex string, signatures connector.TxSignatures) (string, error) {
+	payload, err := hex.DecodeString(txHex)
+	if err != nil {
+		return "", err
+	}
+	tx, err := DecodeUnsignedTx(payload)
+	if err != nil {
+		return "", err
+	}
+	if len(signatures) != 1 || len(signatures[0]) != 1 {
+		return "", fmt.Errorf("one signature expected")
+	}
+	sig, err := hex.DecodeString(signatures[0][0])
+	if err != nil {
+		return "", err
+	}
+	if err = tx.SetSignature(sig); err != nil {
+		return "", err
+	}
+	raw, err := tx.Encode()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// TxBroadcast sends the signed tx, the hash is 0x prefixed as reported by the node.
+func (c *ethChainConnector) TxBroadcast(txHex string) (string, error) {
+	var hash string
+	if err := c.call(&hash, "eth_sendRawTransaction", "0x"+strings.TrimPrefix(txHex, "0x")); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// TxValidate checks the signed tx against the pending state of the node: the nonce, the fee cap and
+// the execution with eth_estimateGas. Fee is the maximal fee of the tx.
+func (c *ethChainConnector) TxValidate(txHex string) (*connector.TxValidation, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(txHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	tx, err := DecodeTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	from, err := tx.Sender()
+	if err != nil {
+		return nil, err
+	}
+	validation := &connector.TxValidation{
+		TxID: "0x" + hex.EncodeToString(addreth.Keccak256(raw)),
+		Fee:  tx.MaxFee(),
+	}
+	reject := func(reason string) (*connector.TxValidation, error) {
+		validation.RejectReason, validation.Err = reason, RejectError(reason)
+		return validation, nil
+	}
+
+	var nonce hexBig
+	if err = c.call(&nonce, "eth_getTransactionCount", addreth.ChecksumAddress(from), "latest"); err != nil {
+		return nil, err
+	}
+	if tx.Nonce < nonce.toBig().Uint64() {
+		return reject("nonce too low")
+	}
+	var latest blockHeader
+	if err = c.call(&latest, "eth_getBlockByNumber", "latest", false); err != nil {
+		return nil, err
+	}
+	if latest.BaseFeePerGas != nil && tx.FeeCap().Cmp(latest.BaseFeePerGas.toBig()) < 0 {
+		return reject("max fee per gas less than block base fee")
+	}
+	msg := callMsg{From: addreth.ChecksumAddress(from), Value: (*hexBig)(tx.Value)}
+	if len(tx.To) > 0 {
+		msg.To = addreth.ChecksumAddress(tx.To)
+	}
+	if len(tx.Data) > 0 {
+		msg.Data = "0x" + hex.EncodeToString(tx.Data)
+	}
+	var gas hexBig
+	if err = c.call(&gas, "eth_estimateGas", msg); err != nil {
+		if rpcErr, ok := err.(*RPCError); ok {
+			return reject(rpcErr.Message)
+		}
+		return nil, err
+	}
+	if gas.toBig().Uint64() > tx.Gas {
+		return reject("intrinsic gas too low")
+	}
+	validation.Allowed = true
+	return validation, nil
+}
+
+// RejectError maps the error of the node to the typed error, connector.ErrTxRejected is returned for unknown reasons.
+func RejectError(reason string) error {
+	for _, r := range rejectReasons {
+		if strings.Contains(reason, r.reason) {
+			return r.err
+		}
+	}
+	return connector.ErrTxRejected
+}
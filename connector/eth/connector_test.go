@@ -0,0 +1,398 @@
+package eth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/connector"
+	signers "github.com/stanche/crypto-interface/signer"
+)
+
+// fakeNode serves JSON-RPC with the handlers by method, a handler returns the JSON result or the node error.
+type fakeNode map[string]func(params []json.RawMessage) (string, *RPCError)
+
+func (n fakeNode) connector(t *testing.T) (*ethChainConnector, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     json.RawMessage   `json:"id"`
+		}
+		_ = json.Unmarshal(body, &req)
+		handler, ok := n[req.Method]
+		if !ok {
+			t.Errorf("unexpected request: %s", body)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		result, rpcErr := handler(req.Params)
+		if rpcErr != nil {
+			errJSON, _ := json.Marshal(rpcErr)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":%s}`, req.ID, errJSON)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	return &ethChainConnector{client: NewClient(server.URL, 1)}, server.Close
+}
+
+func result(res string) func([]json.RawMessage) (string, *RPCError) {
+	return func([]json.RawMessage) (string, *RPCError) { return res, nil }
+}
+
+type ethCurrency struct{}
+
+func (ethCurrency) GetCode() string         { return "ETH" }
+func (ethCurrency) GetPrecision() uint8     { return 18 }
+func (ethCurrency) GetTokenAddress() string { return "" }
+func (ethCurrency) GetTokenCode() int64     { return 0 }
+
+func TestEthChainConnector_TxBuild(t *testing.T) {
+	seed, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	kp := signers.New(seed)
+	pub, _ := kp.DerivedPubkey([]uint32{0, 5})
+	from := addreth.ChecksumAddress(addreth.PubkeyToAddress(pub))
+	to := "0x3535353535353535353535353535353535353535"
+
+	node := fakeNode{
+		"eth_chainId": result(`"0x1"`),
+		"eth_getTransactionCount": func(params []json.RawMessage) (string, *RPCError) {
+			if string(params[0]) != `"`+from+`"` || string(params[1]) != `"pending"` {
+				return "", &RPCError{Code: -32602, Message: "unexpected params"}
+			}
+			return `"0x9"`, nil
+		},
+		"eth_estimateGas":          result(`"0x5208"`),
+		"eth_getBlockByNumber":     result(`{"number":"0x10","baseFeePerGas":"0x3b9aca00"}`),
+		"eth_maxPriorityFeePerGas": result(`"0x77359400"`),
+		"eth_gasPrice":             result(`"0x4a817c800"`),
+	}
+	out := []connector.OutStruct{{Address: to, Amount: decimal.New(15, -1), Currency: ethCurrency{}}}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive building, signing and broadcasting EIP-1559 tx",
+			func(t *testing.T) {
+				var broadcast string
+				node["eth_sendRawTransaction"] = func(params []json.RawMessage) (string, *RPCError) {
+					_ = json.Unmarshal(params[0], &broadcast)
+					return `"0xab"`, nil
+				}
+				c, stop := node.connector(t)
+				defer stop()
+
+				txHex, err := c.TxBuild(nil, Account{Address: from}, out)
+				assert.Nil(t, err, "unexpected error")
+				payload, _ := hex.DecodeString(txHex)
+				tx, err := DecodeUnsignedTx(payload)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, &Tx{
+					Type:      TxTypeDynamicFee,
+					ChainID:   big.NewInt(1),
+					Nonce:     9,
+					GasTipCap: big.NewInt(2000000000),
+					GasFeeCap: big.NewInt(4000000000),
+					Gas:       21000,
+					To:        tx.To,
+					Value:     big.NewInt(1500000000000000000),
+					Data:      []byte{},
+				}, tx, "unexpected tx")
+				assert.Equal(t, to, addreth.ChecksumAddress(tx.To), "unexpected recipient")
+
+				signatures, err := signers.NewEthSigner("ETH", kp).Sign([]byte(txHex), []uint64{0, 5})
+				assert.Nil(t, err, "unexpected error")
+				signedHex, err := c.TxRebuild(txHex, connector.TxSignatures{signatures})
+				assert.Nil(t, err, "unexpected error")
+				raw, _ := hex.DecodeString(signedHex)
+				signed, err := DecodeTx(raw)
+				assert.Nil(t, err, "unexpected error")
+				sender, err := signed.Sender()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, from, addreth.ChecksumAddress(sender), "unexpected sender")
+
+				hash, err := c.TxBroadcast(signedHex)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, "0xab", hash, "unexpected hash")
+				assert.Equal(t, "0x"+signedHex, broadcast, "unexpected broadcast tx")
+			},
+		},
+		{
+			"Positive building legacy tx with the fee subtracted",
+			func(t *testing.T) {
+				node["eth_getBlockByNumber"] = result(`{"number":"0x10"}`)
+				defer func() { node["eth_getBlockByNumber"] = result(`{"number":"0x10","baseFeePerGas":"0x3b9aca00"}`) }()
+				c, stop := node.connector(t)
+				defer stop()
+
+				nonce := uint64(7)
+				output := []connector.OutStruct{out[0]}
+				output[0].SubtractFeeFromAmount = true
+				txHex, err := c.TxBuild(nil, Account{Address: from, Nonce: &nonce}, output)
+				assert.Nil(t, err, "unexpected error")
+				payload, _ := hex.DecodeString(txHex)
+				tx, err := DecodeUnsignedTx(payload)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, uint8(TxTypeLegacy), tx.Type, "unexpected type")
+				assert.Equal(t, uint64(7), tx.Nonce, "unexpected nonce")
+				assert.Equal(t, "20000000000", tx.GasPrice.String(), "unexpected gas price")
+				assert.Equal(t, "1499580000000000000", tx.Value.String(), "unexpected value")
+			},
+		},
+		{
+			"Positive building ERC-20 transfer",
+			func(t *testing.T) {
+				const usdt = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+				node["eth_estimateGas"] = func(params []json.RawMessage) (string, *RPCError) {
+					var msg callMsg
+					_ = json.Unmarshal(params[0], &msg)
+					if msg.To != usdt || msg.Data == "" {
+						return "", &RPCError{Code: -32000, Message: "execution reverted"}
+					}
+					return `"0xea60"`, nil
+				}
+				defer func() { node["eth_estimateGas"] = result(`"0x5208"`) }()
+				c, stop := node.connector(t)
+				defer stop()
+
+				output := []connector.OutStruct{{Address: to, Amount: decimal.New(25, -1), Currency: tokenCurrency{"USDT", usdt}}}
+				txHex, err := c.TxBuild(nil, Account{Address: from}, output)
+				assert.Nil(t, err, "unexpected error")
+				payload, _ := hex.DecodeString(txHex)
+				tx, err := DecodeUnsignedTx(payload)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, usdt, addreth.ChecksumAddress(tx.To), "unexpected contract")
+				assert.Equal(t, int64(0), tx.Value.Int64(), "unexpected value")
+				assert.Equal(t, uint64(60000), tx.Gas, "unexpected gas")
+				recipient, amount, ok := ParseTransferCalldata(tx.Data)
+				assert.True(t, ok, "transfer expected")
+				assert.Equal(t, to, addreth.ChecksumAddress(recipient), "unexpected recipient")
+				assert.Equal(t, "2500000", amount.String(), "unexpected amount")
+
+				output[0].SubtractFeeFromAmount = true
+				_, err = c.TxBuild(nil, Account{Address: from}, output)
+				assert.NotNil(t, err, "expected error for fee subtraction")
+			},
+		},
+		{
+			"Negative building with the fee over the maximum",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+				c.feeMax = big.NewInt(3 * gwei)
+
+				_, err := c.TxBuild(nil, Account{Address: from}, out)
+				assert.NotNil(t, err, "expected error")
+			},
+		},
+		{
+			"Negative building with invalid arguments",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+
+				_, err := c.TxBuild(nil, from, out)
+				assert.NotNil(t, err, "expected error for utxos")
+				_, err = c.TxBuild(nil, Account{Address: from}, append(out, out...))
+				assert.NotNil(t, err, "expected error for several outputs")
+				_, err = c.TxBuild(nil, Account{Address: from}, []connector.OutStruct{{Address: "0x35", Amount: decimal.New(1, 0)}})
+				assert.NotNil(t, err, "expected error for address")
+				_, err = c.TxBuild(nil, Account{Address: from}, []connector.OutStruct{{Address: to, Amount: decimal.New(1, -19)}})
+				assert.NotNil(t, err, "expected error for amount")
+				_, err = c.TxRebuild("00", connector.TxSignatures{{"00"}})
+				assert.NotNil(t, err, "expected error for tx")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, c.exec)
+	}
+}
+
+// signTx signs the tx with the key, the signature is r || s || recovery id.
+func signTx(t *testing.T, tx *Tx, key *btcec.PrivateKey) {
+	compact, err := btcec.SignCompact(btcec.S256(), key, tx.SigningHash(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.SetSignature(append(compact[1:], compact[0]-27)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEthChainConnector_TxValidate(t *testing.T) {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), []byte{1})
+	tx := &Tx{
+		Type:      TxTypeDynamicFee,
+		ChainID:   big.NewInt(1),
+		Nonce:     9,
+		GasTipCap: big.NewInt(2000000000),
+		GasFeeCap: big.NewInt(4000000000),
+		Gas:       21000,
+		To:        make([]byte, 20),
+		Value:     big.NewInt(1),
+	}
+	signTx(t, tx, key)
+	raw, _ := tx.Encode()
+
+	tests := []struct {
+		name     string
+		nonce    string
+		baseFee  string
+		estimate func([]json.RawMessage) (string, *RPCError)
+		want     *connector.TxValidation
+	}{
+		{
+			name: "allowed", nonce: `"0x9"`, baseFee: `"0x3b9aca00"`, estimate: result(`"0x5208"`),
+			want: &connector.TxValidation{Allowed: true},
+		},
+		{
+			name: "nonce too low", nonce: `"0xa"`, baseFee: `"0x3b9aca00"`, estimate: result(`"0x5208"`),
+			want: &connector.TxValidation{RejectReason: "nonce too low", Err: connector.ErrTxConflict},
+		},
+		{
+			name: "base fee", nonce: `"0x9"`, baseFee: `"0x12a05f200"`, estimate: result(`"0x5208"`),
+			want: &connector.TxValidation{RejectReason: "max fee per gas less than block base fee", Err: connector.ErrTxFeeTooLow},
+		},
+		{
+			name: "insufficient funds", nonce: `"0x9"`, baseFee: `"0x3b9aca00"`,
+			estimate: func([]json.RawMessage) (string, *RPCError) {
+				return "", &RPCError{Code: -32000, Message: "insufficient funds for transfer"}
+			},
+			want: &connector.TxValidation{RejectReason: "insufficient funds for transfer", Err: connector.ErrTxInputsMissing},
+		},
+		{
+			name: "gas", nonce: `"0x9"`, baseFee: `"0x3b9aca00"`, estimate: result(`"0x5209"`),
+			want: &connector.TxValidation{RejectReason: "intrinsic gas too low", Err: connector.ErrTxRejected},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, stop := fakeNode{
+				"eth_getTransactionCount": result(tt.nonce),
+				"eth_getBlockByNumber":    result(`{"number":"0x10","baseFeePerGas":` + tt.baseFee + `}`),
+				"eth_estimateGas":         tt.estimate,
+			}.connector(t)
+			defer stop()
+
+			got, err := c.TxValidate(hex.EncodeToString(raw))
+			assert.Nil(t, err, "unexpected error")
+			tt.want.TxID = "0x" + hex.EncodeToString(addreth.Keccak256(raw))
+			tt.want.Fee = big.NewInt(84000000000000)
+			assert.Equal(t, tt.want, got, "unexpected validation")
+		})
+	}
+}
+
+func TestEthChainConnector_TxStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		receipt string
+		tx      string
+		want    *connector.TxStatusStruct
+		wantErr error
+	}{
+		{
+			name:    "confirmed",
+			receipt: `{"blockNumber":"0xe","status":"0x1","gasUsed":"0x5208","effectiveGasPrice":"0x3b9aca00"}`,
+			want:    &connector.TxStatusStruct{Height: 14, Conf: 3, Fee: big.NewInt(21000000000000)},
+		},
+		{
+			name:    "reverted",
+			receipt: `{"blockNumber":"0x10","status":"0x0","gasUsed":"0x5208","effectiveGasPrice":"0x3b9aca00"}`,
+			want:    &connector.TxStatusStruct{Height: 16, Conf: 1, Fee: big.NewInt(21000000000000)},
+			wantErr: connector.TxPermanentFailure,
+		},
+		{
+			name:    "confirmed before London",
+			receipt: `{"blockNumber":"0xe","status":"0x1","gasUsed":"0x5208"}`,
+			tx:      `{"hash":"0xab","blockNumber":"0xe","gasPrice":"0x77359400"}`,
+			want:    &connector.TxStatusStruct{Height: 14, Conf: 3, Fee: big.NewInt(42000000000000)},
+		},
+		{
+			name:    "confirmed before Byzantium with unknown tx",
+			receipt: `{"blockNumber":"0xe","gasUsed":"0x5208"}`,
+			tx:      `null`,
+			wantErr: fmt.Errorf("gas price of tx 0xab is unknown"),
+		},
+		{
+			name:    "pending",
+			receipt: `null`,
+			tx:      `{"hash":"0xab","blockNumber":null}`,
+			want:    &connector.TxStatusStruct{},
+		},
+		{
+			name:    "unknown",
+			receipt: `null`,
+			tx:      `null`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, stop := fakeNode{
+				"eth_getTransactionReceipt": result(tt.receipt),
+				"eth_getTransactionByHash":  result(tt.tx),
+				"eth_blockNumber":           result(`"0x10"`),
+			}.connector(t)
+			defer stop()
+
+			got, err := c.TxStatus("0xab", 0)
+			assert.Equal(t, tt.wantErr, err, "unexpected error")
+			assert.Equal(t, tt.want, got, "unexpected status")
+		})
+	}
+}
+
+func TestEthChainConnector_BalanceGet(t *testing.T) {
+	balances := map[string]string{
+		`"latest"`:  `"0xde0b6b3a7640000"`,
+		`"pending"`: `"0x6f05b59d3b20000"`,
+	}
+	c, stop := fakeNode{
+		"eth_getBalance": func(params []json.RawMessage) (string, *RPCError) {
+			return balances[string(params[1])], nil
+		},
+	}.connector(t)
+	defer stop()
+
+	b, err := c.BalanceGet(ethCurrency{}, "0x3535353535353535353535353535353535353535", "invalid")
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "1", b.Confirmed.String(), "unexpected confirmed balance")
+	assert.Equal(t, "-0.5", b.Unconfirmed.String(), "unexpected unconfirmed balance")
+
+	token := tokenCurrency{"USDT", "0xdAC17F958D2ee523a2206206994597C13D831ec7"}
+	c, stop = fakeNode{
+		"eth_call": func(params []json.RawMessage) (string, *RPCError) {
+			var msg callMsg
+			_ = json.Unmarshal(params[0], &msg)
+			if msg.To != token.address || msg.Data != "0x70a082310000000000000000000000003535353535353535353535353535353535353535" {
+				return "", &RPCError{Code: -32000, Message: "execution reverted"}
+			}
+			return `"0x00000000000000000000000000000000000000000000000000000000002625a0"`, nil
+		},
+	}.connector(t)
+	defer stop()
+
+	b, err = c.BalanceGet(token, "0x3535353535353535353535353535353535353535")
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "2.5", b.Confirmed.String(), "unexpected token balance")
+	assert.Equal(t, "0", b.Unconfirmed.String(), "unexpected unconfirmed token balance")
+
+	_, err = (&ethChainConnector{}).BalanceGet(ethCurrency{}, "0x3535353535353535353535353535353535353535")
+	assert.Equal(t, connector.ErrClientNil, err, "unexpected error")
+}
@@ -0,0 +1,131 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/stanche/crypto-interface/abi"
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/connector"
+)
+
+// ERC-20 method selectors and the Transfer event topic.
+var (
+	TransferSelector  = addreth.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	BalanceOfSelector = addreth.Keccak256([]byte("balanceOf(address)"))[:4]
+	TransferTopic     = "0x" + hex.EncodeToString(addreth.Keccak256([]byte("Transfer(address,address,uint256)")))
+)
+
+// TransferLog is the decoded ERC-20 Transfer event.
+type TransferLog struct {
+	Token  []byte
+	From   []byte
+	To     []byte
+	Amount *big.Int
+}
+
+// isToken returns true for the currency which is ERC-20 token, i.e. it has the contract address.
+func isToken(currency connector.Currency) bool {
+	return currency != nil && currency.GetTokenAddress() != ""
+}
+
+// TransferCalldata returns the calldata of ERC-20 transfer(to, amount).
+func TransferCalldata(to []byte, amount *big.Int) []byte {
+	data := make([]byte, 4+2*abi.WordSize)
+	copy(data, TransferSelector)
+	copy(data[4+abi.WordSize-len(to):4+abi.WordSize], to)
+	abi.PutUint(data[4+abi.WordSize:], amount)
+	return data
+}
+
+// ParseTransferCalldata decodes the calldata of ERC-20 transfer, ok is false for the other calls.
+func ParseTransferCalldata(data []byte) (to []byte, amount *big.Int, ok bool) {
+	if len(data) != 4+2*abi.WordSize || !bytes.Equal(data[:4], TransferSelector) {
+		return nil, nil, false
+	}
+	to, ok = wordAddress(data[4 : 4+abi.WordSize])
+	if !ok {
+		return nil, nil, false
+	}
+	return to, new(big.Int).SetBytes(data[4+abi.WordSize:]), true
+}
+
+// balanceOfCalldata returns the calldata of ERC-20 balanceOf(owner).
+func balanceOfCalldata(owner []byte) []byte {
+	data := make([]byte, 4+abi.WordSize)
+	copy(data, BalanceOfSelector)
+	copy(data[4+abi.WordSize-len(owner):], owner)
+	return data
+}
+
+// ParseTransferLog decodes the Transfer event of ERC-20 token. ERC-721 transfers have the token id
+// as the third topic and are rejected.
+func ParseTransferLog(log *TxLog) (*TransferLog, error) {
+	if len(log.Topics) != 3 || !strings.EqualFold(log.Topics[0], TransferTopic) {
+		return nil, fmt.Errorf("not ERC-20 Transfer event")
+	}
+	token, err := addreth.ParseAddress(log.Address)
+	if err != nil {
+		return nil, err
+	}
+	var words [2][]byte
+	for i := range words {
+		if words[i], err = decodeHex(log.Topics[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	from, okFrom := wordAddress(words[0])
+	to, okTo := wordAddress(words[1])
+	if !okFrom || !okTo {
+		return nil, fmt.Errorf("invalid Transfer event addresses")
+	}
+	data, err := decodeHex(log.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != abi.WordSize {
+		return nil, fmt.Errorf("invalid Transfer event data size %d", len(data))
+	}
+	return &TransferLog{Token: token, From: from, To: to, Amount: new(big.Int).SetBytes(data)}, nil
+}
+
+// wordAddress returns the address of ABI encoded word, the word shall be zero padded.
+func wordAddress(word []byte) ([]byte, bool) {
+	if len(word) != abi.WordSize {
+		return nil, false
+	}
+	for _, b := range word[:abi.WordSize-addreth.AddressSize] {
+		if b != 0 {
+			return nil, false
+		}
+	}
+	return word[abi.WordSize-addreth.AddressSize:], true
+}
+
+// decodeHex decodes 0x prefixed hex data.
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("invalid hex %s", s)
+	}
+	return hex.DecodeString(s[2:])
+}
+
+// tokenBalance returns the token balance of the owner in the block state: latest or pending.
+func (c *ethChainConnector) tokenBalance(token, owner []byte, block string) (*big.Int, error) {
+	var res string
+	msg := callMsg{To: addreth.ChecksumAddress(token), Data: "0x" + hex.EncodeToString(balanceOfCalldata(owner))}
+	if err := c.call(&res, "eth_call", msg, block); err != nil {
+		return nil, err
+	}
+	data, err := decodeHex(res)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != abi.WordSize {
+		return nil, fmt.Errorf("invalid balanceOf result %s", res)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,38 @@
+package eth
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenCurrency struct {
+	code    string
+	address string
+}
+
+func (c tokenCurrency) GetCode() string         { return c.code }
+func (tokenCurrency) GetPrecision() uint8       { return 6 }
+func (c tokenCurrency) GetTokenAddress() string { return c.address }
+func (tokenCurrency) GetTokenCode() int64       { return 0 }
+
+func TestParseTransferCalldata(t *testing.T) {
+	to, _ := hex.DecodeString("3535353535353535353535353535353535353535")
+	data := TransferCalldata(to, big.NewInt(1000000))
+	assert.Equal(t, "a9059cbb"+
+		"0000000000000000000000003535353535353535353535353535353535353535"+
+		"00000000000000000000000000000000000000000000000000000000000f4240", hex.EncodeToString(data), "unexpected calldata")
+
+	parsedTo, amount, ok := ParseTransferCalldata(data)
+	assert.True(t, ok, "transfer expected")
+	assert.Equal(t, to, parsedTo, "unexpected recipient")
+	assert.Equal(t, "1000000", amount.String(), "unexpected amount")
+
+	data[5] = 1
+	_, _, ok = ParseTransferCalldata(data)
+	assert.False(t, ok, "invalid address padding")
+	_, _, ok = ParseTransferCalldata(data[:40])
+	assert.False(t, ok, "short calldata")
+}
@@ -0,0 +1,228 @@
+package eth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Nargott/goutils"
+	"github.com/wedancedalot/decimal"
+
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/connector"
+)
+
+type (
+	// EthBlockChainImporter imports ETH transfers and ERC-20 Transfer events of the block.
+	EthBlockChainImporter struct {
+		client      *Client
+		txBatchSize int
+	}
+
+	block struct {
+		Hash         string     `json:"hash"`
+		ParentHash   string     `json:"parentHash"`
+		Transactions []*blockTx `json:"transactions"`
+	}
+
+	blockTx struct {
+		Hash  string  `json:"hash"`
+		To    string  `json:"to"`
+		Value *hexBig `json:"value"`
+	}
+
+	// processTxData is used for processTransaction func as param
+	processTxData struct {
+		tx        *blockTx
+		receipt   *txReceipt
+		coin      connector.Currency
+		tokens    []connector.Currency
+		addresses connector.AddressLister
+	}
+
+	// processTxResponse is used for processTransaction func as return
+	processTxResponse struct {
+		ops []connector.Operation
+		err error
+	}
+)
+
+var ErrBadCurrenciesCount = fmt.Errorf("bad currencies count provided: Ethereum import supports one coin currency and ERC-20 tokens")
+
+// NewBlockChainImporter creates new instance of importer.BlockChainImporter as EthBlockChainImporter.
+// txBatchSize limits the receipts requested concurrently.
+func NewBlockChainImporter(node connector.NodeParams, txBatchSize int) (connector.BlockChainImporter, error) {
+	nodeURL, err := clientURL(node)
+	if err != nil {
+		return nil, err
+	}
+	if txBatchSize <= 0 {
+		txBatchSize = 1
+	}
+	return EthBlockChainImporter{
+		client:      NewClient(nodeURL, defaultTimeoutSec),
+		txBatchSize: txBatchSize,
+	}, nil
+}
+
+// getBlockByNumber returns the block with the txs, connector.ErrNotFound if the block is not mined yet.
+func (bci EthBlockChainImporter) getBlockByNumber(number uint64) (*block, error) {
+	var b *block
+	if err := bci.client.Call(&b, "eth_getBlockByNumber", fmt.Sprintf("0x%x", number), true); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, connector.ErrNotFound
+	}
+	return b, nil
+}
+
+// GetBlockHashesByNumber returns block hash and previous block hash as strings
+func (bci EthBlockChainImporter) GetBlockHashesByNumber(number uint64) (hash, prevHash string, err error) {
+	b, err := bci.getBlockByNumber(number)
+	if err != nil {
+		return "", "", err
+	}
+	return b.Hash, b.ParentHash, nil
+}
+
+// ProcessBlock returns the operations to the given addresses in the block: ETH transfers of the txs (the internal
+// transfers of the contracts are not seen) and Transfer events of the tokens. The reverted txs are skipped.
+func (bci EthBlockChainImporter) ProcessBlock(blockNumber uint64, currencies []connector.Currency, addresses connector.AddressLister) (operations []connector.Operation, err error) {
+	// one coin currency at most, the others are tokens
+	if len(currencies) == 0 {
+		return operations, ErrBadCurrenciesCount
+	}
+	var coin connector.Currency
+	var tokens []connector.Currency
+	for _, currency := range currencies {
+		if isToken(currency) {
+			tokens = append(tokens, currency)
+			continue
+		}
+		if coin != nil {
+			return operations, ErrBadCurrenciesCount
+		}
+		coin = currency
+	}
+
+	b, err := bci.getBlockByNumber(blockNumber)
+	if err != nil {
+		return operations, err
+	}
+	// Scan all transactions inside a block
+	i := 0
+	txCount := len(b.Transactions)
+	var (
+		lastResp processTxResponse
+		errors   []error
+	)
+	for i < txCount {
+		batchSize := goutils.Min(bci.txBatchSize, txCount-i)
+		respCh := make([]chan processTxResponse, batchSize)
+		for n := range respCh {
+			respCh[n] = make(chan processTxResponse, 1)
+			tx := b.Transactions[i+n]
+			go func(ch chan processTxResponse) {
+				ch <- bci.processTransaction(processTxData{
+					tx:        tx,
+					coin:      coin,
+					tokens:    tokens,
+					addresses: addresses,
+				})
+			}(respCh[n])
+		}
+
+		// the operations keep the order of the txs
+		for n := range respCh {
+			lastResp = <-respCh[n]
+			if lastResp.err != nil {
+				//collect errors
+				errors = append(errors, fmt.Errorf("processTransaction [hash: %s] err: %s", b.Transactions[i+n].Hash, lastResp.err.Error()))
+			}
+			operations = append(operations, lastResp.ops...)
+		}
+
+		i += batchSize
+	}
+
+	if len(errors) > 0 { //return all collected errors at once
+		var sb strings.Builder
+		for _, err := range errors {
+			sb.WriteString(fmt.Sprintf("%#v \n", err))
+		}
+		return operations, fmt.Errorf("%s", sb.String())
+	}
+
+	return operations, nil
+}
+
+// processTransaction fetches the receipt of the tx and returns the operations to the given addresses.
+func (bci EthBlockChainImporter) processTransaction(d processTxData) processTxResponse {
+	if err := bci.client.Call(&d.receipt, "eth_getTransactionReceipt", d.tx.Hash); err != nil {
+		return processTxResponse{err: err}
+	}
+	if d.receipt == nil {
+		return processTxResponse{err: connector.ErrNotFound}
+	}
+	if reverted(d.receipt) {
+		return processTxResponse{}
+	}
+	return processTxResponse{ops: append(coinOperations(d), tokenOperations(d)...)}
+}
+
+// coinOperations returns the ETH transfer of the tx.
+func coinOperations(d processTxData) []connector.Operation {
+	if d.coin == nil || d.tx.To == "" || d.tx.Value.toBig().Sign() == 0 {
+		return nil
+	}
+	to, err := addreth.ParseAddress(d.tx.To)
+	if err != nil {
+		return nil
+	}
+	address := addreth.ChecksumAddress(to)
+	if !d.addresses.HasAddress(address, "") {
+		return nil
+	}
+	return []connector.Operation{{
+		TxId:         d.tx.Hash,
+		ToAddress:    address,
+		CurrencyCode: d.coin.GetCode(),
+		Amount:       decimal.NewFromBigInt(d.tx.Value.toBig(), -int32(d.coin.GetPrecision())),
+	}}
+}
+
+// tokenOperations returns the Transfer events of the tokens, TxOut is the index of the log in the block.
+func tokenOperations(d processTxData) []connector.Operation {
+	var operations []connector.Operation
+	for _, log := range d.receipt.Logs {
+		if log.Removed {
+			continue
+		}
+		var currency connector.Currency
+		for _, token := range d.tokens {
+			if strings.EqualFold(log.Address, token.GetTokenAddress()) {
+				currency = token
+				break
+			}
+		}
+		if currency == nil {
+			continue
+		}
+		transfer, err := ParseTransferLog(log)
+		if err != nil || transfer.Amount.Sign() == 0 {
+			continue
+		}
+		address := addreth.ChecksumAddress(transfer.To)
+		if !d.addresses.HasAddress(address, "") {
+			continue
+		}
+		operations = append(operations, connector.Operation{
+			TxId:         d.tx.Hash,
+			TxOut:        uint(log.LogIndex.toBig().Uint64()),
+			ToAddress:    address,
+			CurrencyCode: currency.GetCode(),
+			Amount:       decimal.NewFromBigInt(transfer.Amount, -int32(currency.GetPrecision())),
+		})
+	}
+	return operations
+}
@@ -0,0 +1,119 @@
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/connector"
+)
+
+type addressList map[string]struct{}
+
+func (l addressList) HasAddress(address, _ string) bool {
+	_, ok := l[address]
+	return ok
+}
+
+func TestEthBlockChainImporter_ProcessBlock(t *testing.T) {
+	const (
+		usdt    = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+		watched = "0x3535353535353535353535353535353535353535"
+		other   = "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F"
+	)
+	topic := func(address string) string {
+		return "0x000000000000000000000000" + address[2:]
+	}
+	transferLog := func(index int, token, to string, amount int64) string {
+		return fmt.Sprintf(`{"address":"%s","topics":["%s","%s","%s"],"data":"0x%064x","logIndex":"0x%x","removed":false}`,
+			token, TransferTopic, topic(other), topic(to), amount, index)
+	}
+	receipts := map[string]string{
+		// ETH transfer
+		`"0x01"`: `{"transactionHash":"0x01","status":"0x1","logs":[]}`,
+		// token transfers: watched, other, unknown token, ERC-721 (4 topics)
+		`"0x02"`: `{"transactionHash":"0x02","status":"0x1","logs":[` +
+			transferLog(0, usdt, watched, 2500000) + `,` +
+			transferLog(1, usdt, other, 1) + `,` +
+			transferLog(2, "0x1111111111111111111111111111111111111111", watched, 1) + `,` +
+			fmt.Sprintf(`{"address":"%s","topics":["%s","%s","%s","0x01"],"data":"0x","logIndex":"0x3"}`,
+				usdt, TransferTopic, topic(other), topic(watched)) + `]}`,
+		// reverted
+		`"0x03"`: `{"transactionHash":"0x03","status":"0x0","logs":[` + transferLog(4, usdt, watched, 1) + `]}`,
+	}
+	c, stop := fakeNode{
+		"eth_getBlockByNumber": func(params []json.RawMessage) (string, *RPCError) {
+			if string(params[0]) != `"0x10"` || string(params[1]) != `true` {
+				return `null`, nil
+			}
+			return `{"hash":"0xbb","parentHash":"0xaa","transactions":[` +
+				`{"hash":"0x01","to":"` + watched + `","value":"0x1bc16d674ec80000"},` +
+				`{"hash":"0x02","to":"` + usdt + `","value":"0x0"},` +
+				`{"hash":"0x03","to":"` + watched + `","value":"0x1"},` +
+				`{"hash":"0x04","to":null,"value":"0x0"}]}`, nil
+		},
+		"eth_getTransactionReceipt": func(params []json.RawMessage) (string, *RPCError) {
+			if receipt, ok := receipts[string(params[0])]; ok {
+				return receipt, nil
+			}
+			return `{"transactionHash":"0x04","status":"0x1","logs":[]}`, nil
+		},
+	}.connector(t)
+	defer stop()
+	bci := EthBlockChainImporter{client: c.client, txBatchSize: 3}
+	addresses := addressList{watched: {}}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive getting block hashes",
+			func(t *testing.T) {
+				hash, prevHash, err := bci.GetBlockHashesByNumber(16)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, "0xbb", hash, "unexpected hash")
+				assert.Equal(t, "0xaa", prevHash, "unexpected previous hash")
+
+				_, _, err = bci.GetBlockHashesByNumber(17)
+				assert.Equal(t, connector.ErrNotFound, err, "unexpected error")
+			},
+		},
+		{
+			"Positive importing ETH and token transfers",
+			func(t *testing.T) {
+				ops, err := bci.ProcessBlock(16, []connector.Currency{ethCurrency{}, tokenCurrency{"USDT", usdt}}, addresses)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, []connector.Operation{
+					{TxId: "0x01", ToAddress: watched, CurrencyCode: "ETH", Amount: decimal.NewFromBigInt(big.NewInt(2000000000000000000), -18)},
+					{TxId: "0x02", TxOut: 0, ToAddress: watched, CurrencyCode: "USDT", Amount: decimal.NewFromBigInt(big.NewInt(2500000), -6)},
+				}, ops, "unexpected operations")
+			},
+		},
+		{
+			"Positive importing tokens only",
+			func(t *testing.T) {
+				ops, err := bci.ProcessBlock(16, []connector.Currency{tokenCurrency{"USDT", "0xdac17f958d2ee523a2206206994597c13d831ec7"}}, addresses)
+				assert.Nil(t, err, "unexpected error")
+				assert.Len(t, ops, 1, "unexpected operations")
+			},
+		},
+		{
+			"Negative importing with several coins",
+			func(t *testing.T) {
+				_, err := bci.ProcessBlock(16, []connector.Currency{ethCurrency{}, ethCurrency{}}, addresses)
+				assert.Equal(t, ErrBadCurrenciesCount, err, "unexpected error")
+				_, err = bci.ProcessBlock(16, nil, addresses)
+				assert.Equal(t, ErrBadCurrenciesCount, err, "unexpected error")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, c.exec)
+	}
+}
@@ -0,0 +1,300 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/stanche/crypto-interface/abi"
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/eth/safe"
+	"github.com/stanche/crypto-interface/safetx"
+)
+
+// WalletTypeSafe is the type of the wallets spending from Safe multisig contracts.
+const WalletTypeSafe = "safe"
+
+// Gas of execTransaction added to the gas of the Safe call: the checks and the refund of the Safe
+// and the verification of every signature.
+const (
+	SafeExecGas      = 50000
+	SafeSignatureGas = 8000
+)
+
+type (
+	// safeChainConnector spends from the Safe: m-of-n WalletSignStruct maps to the owners and the threshold.
+	// params are the Safe contracts deploying the wallets.
+	safeChainConnector struct {
+		*ethChainConnector
+		params addreth.SafeParams
+	}
+
+	// SafeAccount is the utxos argument of TxBuild of the Safe wallet: the Safe, the address index of the wallet
+	// and the executor account which sends execTransaction and pays the fee.
+	// The owners of the Safe are the keys xpub/0/Index of the signers.
+	SafeAccount struct {
+		Safe     string
+		Index    uint32
+		Executor Account
+	}
+)
+
+// TxBuild builds the Safe tx paying the single output and the unsigned tx of the executor.
+// The result is the Safe payload: the owners sign EIP-712 hash of the Safe tx, TxRebuild assembles
+// execTransaction with the signatures into the tx of the executor.
+func (c *safeChainConnector) TxBuild(walletData *connector.WalletSignStruct, utxos interface{}, output []connector.OutStruct) (string, error) {
+	if walletData == nil || walletData.Signers == 0 || int(walletData.Signers) > len(walletData.XPubs) {
+		return "", fmt.Errorf("invalid Safe wallet data: %+v", walletData)
+	}
+	account, ok := utxos.(SafeAccount)
+	if !ok {
+		return "", fmt.Errorf("unexpected type of utxo input for Safe wallet: expected eth.SafeAccount got: %+v", utxos)
+	}
+	if len(output) != 1 {
+		return "", fmt.Errorf("one output expected, got %d", len(output))
+	}
+	if output[0].SubtractFeeFromAmount {
+		return "", fmt.Errorf("fee is paid by the executor and cannot be subtracted from the amount")
+	}
+	safeAddress, err := addreth.ParseAddress(account.Safe)
+	if err != nil {
+		return "", err
+	}
+	to, value, data, err := transferCall(output[0])
+	if err != nil {
+		return "", err
+	}
+
+	var code string
+	if err = c.call(&code, "eth_getCode", addreth.ChecksumAddress(safeAddress), "latest"); err != nil {
+		return "", err
+	}
+	if code == "" || code == "0x" {
+		return "", fmt.Errorf("Safe %s is not deployed, deploy it with TxBuildDeploy", account.Safe)
+	}
+	threshold, err := c.safeCall(safeAddress, safe.ThresholdSelector)
+	if err != nil {
+		return "", err
+	}
+	if threshold.Cmp(big.NewInt(int64(walletData.Signers))) != 0 {
+		return "", fmt.Errorf("Safe threshold %s differs from the signers required %d", threshold.String(), walletData.Signers)
+	}
+	owners, err := c.walletOwners(walletData, account.Index)
+	if err != nil {
+		return "", err
+	}
+	safeOwners, err := c.safeOwners(safeAddress)
+	if err != nil {
+		return "", err
+	}
+	if !sameOwners(owners, safeOwners) {
+		return "", fmt.Errorf("Safe %s owners differ from the wallet signers at index %d", account.Safe, account.Index)
+	}
+	nonce, err := c.safeCall(safeAddress, safe.NonceSelector)
+	if err != nil {
+		return "", err
+	}
+	gas, err := c.estimateGas(safeAddress, to, value, data)
+	if err != nil {
+		return "", err
+	}
+
+	exec := &Tx{
+		To:    safeAddress,
+		Value: new(big.Int),
+		Gas:   gas + SafeExecGas + uint64(walletData.Signers)*SafeSignatureGas,
+	}
+	if err = c.prepareTx(account.Executor, exec); err != nil {
+		return "", err
+	}
+	payload := safe.Payload{
+		Tx: &safe.Tx{
+			ChainID:   exec.ChainID,
+			Safe:      safeAddress,
+			To:        to,
+			Value:     value,
+			Data:      data,
+			Operation: safe.Call,
+			Nonce:     nonce,
+		},
+		Threshold: walletData.Signers,
+		Owners:    owners,
+		Exec:      exec.SigningPayload(),
+	}
+	return payload.Encode()
+}
+
+// TxBuildDeploy builds the unsigned tx of the executor deploying the Safe of the wallet at the address index
+// with createProxyWithNonce of the factory. The executor signs it as the tx of the account, TxRebuild
+// with the signature returns the signed tx. The Safe shall be deployed before the first TxBuild.
+func (c *safeChainConnector) TxBuildDeploy(walletData *connector.WalletSignStruct, account SafeAccount) (string, error) {
+	owners, err := c.walletOwners(walletData, account.Index)
+	if err != nil {
+		return "", err
+	}
+	safeAddress, err := addreth.ParseAddress(account.Safe)
+	if err != nil {
+		return "", err
+	}
+	saltNonce := uint64(account.Index)
+	if !bytes.Equal(safeAddress, addreth.SafeAddress(c.params, owners, walletData.Signers, saltNonce)) {
+		return "", fmt.Errorf("Safe %s is not the address of the wallet at index %d", account.Safe, account.Index)
+	}
+	var code string
+	if err = c.call(&code, "eth_getCode", addreth.ChecksumAddress(safeAddress), "latest"); err != nil {
+		return "", err
+	}
+	if code != "" && code != "0x" {
+		return "", fmt.Errorf("Safe %s is already deployed", account.Safe)
+	}
+	executor, err := addreth.ParseAddress(account.Executor.Address)
+	if err != nil {
+		return "", err
+	}
+	data := addreth.SafeCreateProxyCalldata(c.params, owners, walletData.Signers, saltNonce)
+	gas, err := c.estimateGas(executor, c.params.ProxyFactory, new(big.Int), data)
+	if err != nil {
+		return "", err
+	}
+
+	tx := &Tx{
+		To:    c.params.ProxyFactory,
+		Value: new(big.Int),
+		Data:  data,
+		Gas:   gas,
+	}
+	if err = c.prepareTx(account.Executor, tx); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tx.SigningPayload()), nil
+}
+
+// walletOwners returns the owners of the Safe of the wallet at the address index.
+func (c *safeChainConnector) walletOwners(walletData *connector.WalletSignStruct, index uint32) ([][]byte, error) {
+	if walletData == nil {
+		return nil, fmt.Errorf("invalid Safe wallet data")
+	}
+	return addreth.NewSafe(c.params).Owners(hd.GeneratorParameters{
+		SignersXpubs:    walletData.XPubs,
+		SignersRequired: walletData.Signers,
+		PathIndex:       index,
+	})
+}
+
+// safeOwners returns the owners of the deployed Safe, the result of getOwners.
+func (c *safeChainConnector) safeOwners(safeAddress []byte) ([][]byte, error) {
+	var res string
+	msg := callMsg{To: addreth.ChecksumAddress(safeAddress), Data: "0x" + hex.EncodeToString(safe.OwnersSelector)}
+	if err := c.call(&res, "eth_call", msg, "latest"); err != nil {
+		return nil, err
+	}
+	data, err := decodeHex(res)
+	if err != nil {
+		return nil, err
+	}
+	// the offset of the array, the length and the addresses
+	if len(data) < 2*abi.WordSize || new(big.Int).SetBytes(data[:abi.WordSize]).Cmp(big.NewInt(abi.WordSize)) != 0 {
+		return nil, fmt.Errorf("invalid Safe owners %s", res)
+	}
+	count := new(big.Int).SetBytes(data[abi.WordSize : 2*abi.WordSize])
+	if !count.IsInt64() || count.Int64() != int64(len(data)/abi.WordSize-2) || len(data)%abi.WordSize != 0 {
+		return nil, fmt.Errorf("invalid Safe owners %s", res)
+	}
+	owners := make([][]byte, 0, count.Int64())
+	for i := 2 * abi.WordSize; i < len(data); i += abi.WordSize {
+		owner, ok := wordAddress(data[i : i+abi.WordSize])
+		if !ok {
+			return nil, fmt.Errorf("invalid Safe owner %s", hex.EncodeToString(data[i:i+abi.WordSize]))
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+// sameOwners returns true if the owners are the same regardless of the order.
+func sameOwners(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sorted := func(owners [][]byte) [][]byte {
+		s := append([][]byte{}, owners...)
+		sort.Slice(s, func(i, j int) bool { return bytes.Compare(s[i], s[j]) < 0 })
+		return s
+	}
+	a, b = sorted(a), sorted(b)
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// safeCall calls the Safe getter returning the number.
+func (c *safeChainConnector) safeCall(safeAddress, selector []byte) (*big.Int, error) {
+	var res string
+	msg := callMsg{To: addreth.ChecksumAddress(safeAddress), Data: "0x" + hex.EncodeToString(selector)}
+	if err := c.call(&res, "eth_call", msg, "latest"); err != nil {
+		return nil, err
+	}
+	data, err := decodeHex(res)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != abi.WordSize {
+		return nil, fmt.Errorf("invalid Safe call result %s", res)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// TxRebuild combines the Safe payload with the signatures of the owners and returns the unsigned tx
+// of the executor calling execTransaction. The executor signs it as the tx of the account and
+// the second TxRebuild with the executor signature returns the signed tx.
+func (c *safeChainConnector) TxRebuild(txHex string, signatures connector.TxSignatures) (string, error) {
+	if !safetx.IsPayload(txHex) {
+		return c.ethChainConnector.TxRebuild(txHex, signatures)
+	}
+	payload, err := safetx.DecodePayload(txHex)
+	if err != nil {
+		return "", err
+	}
+	var sigs [][]byte
+	for _, signerSigs := range signatures {
+		for _, s := range signerSigs {
+			if s == "" {
+				continue
+			}
+			sig, err := hex.DecodeString(s)
+			if err != nil {
+				return "", err
+			}
+			sigs = append(sigs, sig)
+		}
+	}
+	encoded, owners, err := safe.EncodeSignatures(payload.Tx.Hash(), sigs)
+	if err != nil {
+		return "", err
+	}
+	for _, owner := range owners {
+		if !payload.IsOwner(owner) {
+			return "", fmt.Errorf("signer %s is not the owner of the Safe", addreth.ChecksumAddress(owner))
+		}
+	}
+	if len(owners) < int(payload.Threshold) {
+		return "", fmt.Errorf("not enough signatures: %d of %d", len(owners), payload.Threshold)
+	}
+
+	exec, err := DecodeUnsignedTx(payload.Exec)
+	if err != nil {
+		return "", err
+	}
+	if len(exec.To) == 0 || !bytes.Equal(exec.To, payload.Tx.Safe) {
+		return "", fmt.Errorf("executor tx is not sent to the Safe")
+	}
+	exec.Data = safe.ExecTransactionCalldata(payload.Tx, encoded)
+	return hex.EncodeToString(exec.SigningPayload()), nil
+}
@@ -0,0 +1,104 @@
+// Package safe implements the transactions of Safe (Gnosis Safe v1.3) multisig wallets: the owner signatures
+// of EIP-712 hash of safetx.Tx and execTransaction call sent by the executor account.
+package safe
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/stanche/crypto-interface/abi"
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/safetx"
+)
+
+const (
+	// SignatureSize is the size of the owner signature: r || s || v, v is 27 or 28.
+	SignatureSize = 65
+)
+
+// Operations of the Safe tx.
+const (
+	Call         = safetx.Call
+	DelegateCall = safetx.DelegateCall
+)
+
+// Selectors of Safe methods.
+var (
+	ExecTransactionSelector = addreth.Keccak256([]byte("execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256," +
+		"address,address,bytes)"))[:4]
+	NonceSelector     = addreth.Keccak256([]byte("nonce()"))[:4]
+	ThresholdSelector = addreth.Keccak256([]byte("getThreshold()"))[:4]
+	OwnersSelector    = addreth.Keccak256([]byte("getOwners()"))[:4]
+)
+
+type (
+	// Tx is the transaction of the Safe.
+	Tx = safetx.Tx
+	// Payload is the unsigned tx of the Safe wallet.
+	Payload = safetx.Payload
+)
+
+// ExecTransactionCalldata returns the call of execTransaction of the tx with the encoded signatures.
+func ExecTransactionCalldata(tx *Tx, signatures []byte) []byte {
+	const headSize = 10 * abi.WordSize
+	data := append([]byte{}, ExecTransactionSelector...)
+	data = append(data, abi.AddressWord(tx.To)...)
+	data = append(data, abi.UintWord(tx.Value)...)
+	data = append(data, abi.UintWord(big.NewInt(headSize))...)
+	data = append(data, abi.UintWord(big.NewInt(int64(tx.Operation)))...)
+	data = append(data, abi.UintWord(tx.SafeTxGas)...)
+	data = append(data, abi.UintWord(tx.BaseGas)...)
+	data = append(data, abi.UintWord(tx.GasPrice)...)
+	data = append(data, abi.AddressWord(tx.GasToken)...)
+	data = append(data, abi.AddressWord(tx.RefundReceiver)...)
+	data = append(data, abi.UintWord(big.NewInt(int64(headSize+abi.WordSize+abi.PaddedSize(tx.Data))))...)
+	data = append(data, abi.BytesWords(tx.Data)...)
+	return append(data, abi.BytesWords(signatures)...)
+}
+
+// SignatureOwner recovers the owner of the signature of the hash.
+func SignatureOwner(hash, sig []byte) ([]byte, error) {
+	if len(sig) != SignatureSize {
+		return nil, fmt.Errorf("invalid signature size %d", len(sig))
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		return nil, fmt.Errorf("unsupported signature type %d", sig[64])
+	}
+	compact := append([]byte{sig[64]}, sig[:64]...)
+	pub, _, err := btcec.RecoverCompact(btcec.S256(), compact, hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot recover signature owner: %s", err.Error())
+	}
+	return addreth.PubkeyToAddress(pub.ToECDSA()), nil
+}
+
+// EncodeSignatures recovers the owners of the signatures and concatenates the signatures
+// in the ascending order of the owners as Safe requires. The repeated signatures of the owner are skipped.
+func EncodeSignatures(hash []byte, sigs [][]byte) (signatures []byte, owners [][]byte, err error) {
+	type ownerSig struct {
+		owner, sig []byte
+	}
+	var list []ownerSig
+	seen := map[string]bool{}
+	for _, sig := range sigs {
+		owner, err := SignatureOwner(hash, sig)
+		if err != nil {
+			return nil, nil, err
+		}
+		if seen[string(owner)] {
+			continue
+		}
+		seen[string(owner)] = true
+		list = append(list, ownerSig{owner: owner, sig: sig})
+	}
+	sort.Slice(list, func(i, j int) bool { return bytes.Compare(list[i].owner, list[j].owner) < 0 })
+	for _, s := range list {
+		signatures = append(signatures, s.sig...)
+		owners = append(owners, s.owner)
+	}
+	return signatures, owners, nil
+}
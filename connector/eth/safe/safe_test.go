@@ -0,0 +1,84 @@
+package safe
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/abi"
+	addreth "github.com/stanche/crypto-interface/address/eth"
+)
+
+func sign(t *testing.T, key *btcec.PrivateKey, hash []byte) []byte {
+	compact, err := btcec.SignCompact(btcec.S256(), key, hash, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(compact[1:], compact[0])
+}
+
+func TestSelectors(t *testing.T) {
+	assert.Equal(t, "6a761202", hex.EncodeToString(ExecTransactionSelector), "unexpected execTransaction selector")
+	assert.Equal(t, "affed0e0", hex.EncodeToString(NonceSelector), "unexpected nonce selector")
+	assert.Equal(t, "e75235b8", hex.EncodeToString(ThresholdSelector), "unexpected getThreshold selector")
+	assert.Equal(t, "a0e67e2b", hex.EncodeToString(OwnersSelector), "unexpected getOwners selector")
+}
+
+func TestEncodeSignatures(t *testing.T) {
+	hash := addreth.Keccak256([]byte("safe tx"))
+	var keys []*btcec.PrivateKey
+	var sigs [][]byte
+	for i := byte(1); i <= 3; i++ {
+		key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{i}, 32))
+		keys = append(keys, key)
+		sigs = append(sigs, sign(t, key, hash))
+	}
+
+	encoded, owners, err := EncodeSignatures(hash, append(sigs, sigs[0]))
+	assert.Nil(t, err, "unexpected error")
+	assert.Len(t, owners, 3, "the repeated signature shall be skipped")
+	assert.Len(t, encoded, 3*SignatureSize, "unexpected signatures size")
+	for i := range owners {
+		if i > 0 {
+			assert.True(t, bytes.Compare(owners[i-1], owners[i]) < 0, "owners shall be sorted")
+		}
+		owner, err := SignatureOwner(hash, encoded[i*SignatureSize:(i+1)*SignatureSize])
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, owners[i], owner, "unexpected signature order")
+	}
+
+	bad := append([]byte{}, sigs[0]...)
+	bad[64] = 1
+	_, _, err = EncodeSignatures(hash, [][]byte{bad})
+	assert.NotNil(t, err, "expected error for the signature type")
+	_, _, err = EncodeSignatures(hash, [][]byte{sigs[0][:64]})
+	assert.NotNil(t, err, "expected error for the signature size")
+}
+
+func TestExecTransactionCalldata(t *testing.T) {
+	tx := &Tx{
+		ChainID: big.NewInt(1),
+		Safe:    bytes.Repeat([]byte{0x5a}, 20),
+		To:      bytes.Repeat([]byte{0x35}, 20),
+		Value:   big.NewInt(0),
+		Data:    []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01},
+		Nonce:   big.NewInt(3),
+	}
+	signatures := bytes.Repeat([]byte{0x11}, 2*SignatureSize)
+	data := ExecTransactionCalldata(tx, signatures)
+
+	word := func(i int) []byte { return data[4+i*abi.WordSize : 4+(i+1)*abi.WordSize] }
+	assert.Equal(t, ExecTransactionSelector, data[:4], "unexpected selector")
+	assert.Equal(t, abi.AddressWord(tx.To), word(0), "unexpected to")
+	assert.Equal(t, abi.UintWord(big.NewInt(10*abi.WordSize)), word(2), "unexpected data offset")
+	assert.Equal(t, abi.UintWord(big.NewInt(12*abi.WordSize)), word(9), "unexpected signatures offset")
+	assert.Equal(t, abi.UintWord(big.NewInt(5)), word(10), "unexpected data size")
+	assert.Equal(t, tx.Data, word(11)[:5], "unexpected data")
+	assert.Equal(t, abi.UintWord(big.NewInt(2*SignatureSize)), word(12), "unexpected signatures size")
+	assert.Equal(t, signatures, data[4+13*abi.WordSize:4+13*abi.WordSize+2*SignatureSize], "unexpected signatures")
+	assert.Equal(t, 4+(13+5)*abi.WordSize, len(data), "unexpected padding")
+}
@@ -0,0 +1,284 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wedancedalot/decimal"
+
+	"github.com/stanche/crypto-interface/abi"
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/eth/safe"
+	"github.com/stanche/crypto-interface/safetx"
+	signers "github.com/stanche/crypto-interface/signer"
+)
+
+func TestSafeChainConnector_TxBuild(t *testing.T) {
+	const index = 3
+	var (
+		cosigners []*signers.EthSigner
+		xpubs     []string
+	)
+	for i := byte(1); i <= 3; i++ {
+		signer := signers.NewEthSigner("ETH", signers.New(bytes.Repeat([]byte{i}, 32)))
+		public, _ := signer.Public()
+		cosigners = append(cosigners, signer)
+		xpubs = append(xpubs, public.(signers.BtcPublicAttributes).XPub)
+	}
+	walletData := &connector.WalletSignStruct{Signers: 2, XPubs: xpubs}
+	g := addreth.NewSafe(addreth.Safe130Params)
+	gp := hd.GeneratorParameters{SignersXpubs: xpubs, SignersRequired: 2, PathIndex: index}
+	safeAddress, _ := g.AddressGenerate(gp)
+	owners, _ := g.Owners(gp)
+	ownersResult := func(owners [][]byte) string {
+		res := fmt.Sprintf("0x%064x%064x", abi.WordSize, len(owners))
+		for _, owner := range owners {
+			res += strings.Repeat("00", abi.WordSize-addreth.AddressSize) + hex.EncodeToString(owner)
+		}
+		return `"` + res + `"`
+	}
+	factory := addreth.ChecksumAddress(addreth.Safe130Params.ProxyFactory)
+
+	executorKey := signers.New(bytes.Repeat([]byte{9}, 32))
+	executorPub, _ := executorKey.DerivedPubkey([]uint32{0, 0})
+	executor := addreth.ChecksumAddress(addreth.PubkeyToAddress(executorPub))
+	to := "0x3535353535353535353535353535353535353535"
+
+	node := fakeNode{
+		"eth_chainId":              result(`"0x1"`),
+		"eth_getTransactionCount":  result(`"0x4"`),
+		"eth_getBlockByNumber":     result(`{"number":"0x10","baseFeePerGas":"0x3b9aca00"}`),
+		"eth_maxPriorityFeePerGas": result(`"0x77359400"`),
+		"eth_getCode":              result(`"0x6080"`),
+		"eth_estimateGas": func(params []json.RawMessage) (string, *RPCError) {
+			var msg callMsg
+			_ = json.Unmarshal(params[0], &msg)
+			switch {
+			case msg.From == safeAddress && msg.To == to:
+				return `"0x5208"`, nil
+			case msg.From == executor && msg.To == factory:
+				return `"0x3d090"`, nil
+			}
+			return "", &RPCError{Code: -32000, Message: "unexpected call"}
+		},
+		"eth_call": func(params []json.RawMessage) (string, *RPCError) {
+			var msg callMsg
+			_ = json.Unmarshal(params[0], &msg)
+			switch {
+			case msg.To != safeAddress:
+			case msg.Data == "0x"+hex.EncodeToString(safe.ThresholdSelector):
+				return `"0x0000000000000000000000000000000000000000000000000000000000000002"`, nil
+			case msg.Data == "0x"+hex.EncodeToString(safe.NonceSelector):
+				return `"0x000000000000000000000000000000000000000000000000000000000000000b"`, nil
+			case msg.Data == "0x"+hex.EncodeToString(safe.OwnersSelector):
+				// getOwners returns the owners in the reverse order of the setup
+				return ownersResult([][]byte{owners[2], owners[1], owners[0]}), nil
+			}
+			return "", &RPCError{Code: -32000, Message: "execution reverted"}
+		},
+	}
+	out := []connector.OutStruct{{Address: to, Amount: decimal.New(1, 0), Currency: ethCurrency{}}}
+	account := SafeAccount{Safe: safeAddress, Index: index, Executor: Account{Address: executor}}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive building, signing and executing Safe tx",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				txHex, err := sc.TxBuild(walletData, account, out)
+				assert.Nil(t, err, "unexpected error")
+				payload, err := safetx.DecodePayload(txHex)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, uint8(2), payload.Threshold, "unexpected threshold")
+				assert.Equal(t, int64(11), payload.Tx.Nonce.Int64(), "unexpected Safe nonce")
+				assert.Equal(t, "1000000000000000000", payload.Tx.Value.String(), "unexpected value")
+
+				// two of three owners sign
+				var signatures connector.TxSignatures
+				for _, signer := range cosigners[1:] {
+					sig, err := signer.Sign([]byte(txHex), []uint64{0, index})
+					assert.Nil(t, err, "unexpected error")
+					signatures = append(signatures, sig)
+				}
+				_, err = sc.TxRebuild(txHex, signatures[:1])
+				assert.NotNil(t, err, "expected error for not enough signatures")
+				assert.Equal(t, owners, payload.Owners, "unexpected owners")
+				foreign, err := signers.NewEthSigner("ETH", executorKey).Sign([]byte(txHex), []uint64{0, 0})
+				assert.Nil(t, err, "unexpected error")
+				_, err = sc.TxRebuild(txHex, append(signatures, foreign))
+				assert.NotNil(t, err, "expected error for the signature of not the owner")
+
+				execHex, err := sc.TxRebuild(txHex, signatures)
+				assert.Nil(t, err, "unexpected error")
+				execPayload, _ := hex.DecodeString(execHex)
+				exec, err := DecodeUnsignedTx(execPayload)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, safeAddress, addreth.ChecksumAddress(exec.To), "unexpected executor recipient")
+				assert.Equal(t, uint64(4), exec.Nonce, "unexpected executor nonce")
+				assert.Equal(t, uint64(21000+SafeExecGas+2*SafeSignatureGas), exec.Gas, "unexpected gas")
+				assert.Equal(t, safe.ExecTransactionSelector, exec.Data[:4], "unexpected call")
+
+				// the signatures are sorted by the owners
+				sigs := exec.Data[len(exec.Data)-abi.WordSize*5 : len(exec.Data)-abi.WordSize*5+2*safe.SignatureSize]
+				var signed [][]byte
+				for i := 0; i < 2; i++ {
+					owner, err := safe.SignatureOwner(payload.Tx.Hash(), sigs[i*safe.SignatureSize:(i+1)*safe.SignatureSize])
+					assert.Nil(t, err, "unexpected error")
+					signed = append(signed, owner)
+				}
+				assert.True(t, bytes.Compare(signed[0], signed[1]) < 0, "unexpected signatures order")
+				assert.ElementsMatch(t, owners[1:], signed, "unexpected signers")
+
+				// the executor signs the tx of the account
+				execSig, err := signers.NewEthSigner("ETH", executorKey).Sign([]byte(execHex), []uint64{0, 0})
+				assert.Nil(t, err, "unexpected error")
+				rawHex, err := sc.TxRebuild(execHex, connector.TxSignatures{execSig})
+				assert.Nil(t, err, "unexpected error")
+				raw, _ := hex.DecodeString(rawHex)
+				tx, err := DecodeTx(raw)
+				assert.Nil(t, err, "unexpected error")
+				sender, err := tx.Sender()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, executor, addreth.ChecksumAddress(sender), "unexpected sender")
+				assert.Equal(t, exec.Data, tx.Data, "unexpected data")
+			},
+		},
+		{
+			"Negative building for the other threshold",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				_, err := sc.TxBuild(&connector.WalletSignStruct{Signers: 3, XPubs: xpubs}, account, out)
+				assert.NotNil(t, err, "expected error")
+			},
+		},
+		{
+			"Negative building for the other owners",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				other := account
+				other.Index = index + 1
+				_, err := sc.TxBuild(walletData, other, out)
+				assert.NotNil(t, err, "expected error for the owners of the other index")
+
+				call := node["eth_call"]
+				defer func() { node["eth_call"] = call }()
+				node["eth_call"] = func(params []json.RawMessage) (string, *RPCError) {
+					var msg callMsg
+					_ = json.Unmarshal(params[0], &msg)
+					if msg.Data == "0x"+hex.EncodeToString(safe.OwnersSelector) {
+						return ownersResult([][]byte{owners[0], owners[1], addreth.PubkeyToAddress(executorPub)}), nil
+					}
+					return `"0x0000000000000000000000000000000000000000000000000000000000000002"`, nil
+				}
+				_, err = sc.TxBuild(walletData, account, out)
+				assert.NotNil(t, err, "expected error for the foreign owner")
+			},
+		},
+		{
+			"Positive deployment of the Safe",
+			func(t *testing.T) {
+				node["eth_getCode"] = result(`"0x"`)
+				defer func() { node["eth_getCode"] = result(`"0x6080"`) }()
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				_, err := sc.TxBuild(walletData, account, out)
+				assert.NotNil(t, err, "expected error for the Safe not deployed")
+
+				txHex, err := sc.TxBuildDeploy(walletData, account)
+				assert.Nil(t, err, "unexpected error")
+				payload, _ := hex.DecodeString(txHex)
+				tx, err := DecodeUnsignedTx(payload)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, factory, addreth.ChecksumAddress(tx.To), "unexpected factory")
+				assert.Equal(t, uint64(4), tx.Nonce, "unexpected executor nonce")
+				assert.Equal(t, uint64(250000), tx.Gas, "unexpected gas")
+				assert.Equal(t, addreth.SafeCreateProxyCalldata(addreth.Safe130Params, owners, 2, index), tx.Data,
+					"unexpected factory call")
+
+				other := account
+				other.Index = index + 1
+				_, err = sc.TxBuildDeploy(walletData, other)
+				assert.NotNil(t, err, "expected error for the address of the other index")
+			},
+		},
+		{
+			"Negative deployment of the deployed Safe",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				_, err := sc.TxBuildDeploy(walletData, account)
+				assert.NotNil(t, err, "expected error")
+			},
+		},
+		{
+			"Negative building for the Safe not deployed",
+			func(t *testing.T) {
+				node["eth_getCode"] = result(`"0x"`)
+				defer func() { node["eth_getCode"] = result(`"0x6080"`) }()
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				_, err := sc.TxBuild(walletData, account, out)
+				assert.NotNil(t, err, "expected error")
+			},
+		},
+		{
+			"Negative building with invalid arguments",
+			func(t *testing.T) {
+				c, stop := node.connector(t)
+				defer stop()
+				sc := &safeChainConnector{ethChainConnector: c, params: addreth.Safe130Params}
+
+				_, err := sc.TxBuild(nil, account, out)
+				assert.NotNil(t, err, "expected error for wallet data")
+				_, err = sc.TxBuild(walletData, account.Executor, out)
+				assert.NotNil(t, err, "expected error for utxos")
+				output := []connector.OutStruct{out[0]}
+				output[0].SubtractFeeFromAmount = true
+				_, err = sc.TxBuild(walletData, account, output)
+				assert.NotNil(t, err, "expected error for fee subtraction")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, c.exec)
+	}
+}
+
+func TestNewChainConnector_Safe(t *testing.T) {
+	c, err := NewChainConnector(1, &connector.WalletParams{Currency: "ETH", Type: WalletTypeSafe})
+	assert.Nil(t, err, "unexpected error")
+	_, ok := c.(*safeChainConnector)
+	assert.True(t, ok, "Safe connector expected")
+	assert.Equal(t, WalletTypeSafe, c.GetWalletType(), "unexpected wallet type")
+
+	c, err = NewChainConnector(1, &connector.WalletParams{Currency: "ETH"})
+	assert.Nil(t, err, "unexpected error")
+	_, ok = c.(*ethChainConnector)
+	assert.True(t, ok, "ETH connector expected")
+}
@@ -0,0 +1,27 @@
+package eth
+
+import (
+	"github.com/stanche/crypto-interface/ethtx"
+)
+
+// Transaction types.
+const (
+	TxTypeLegacy     = ethtx.TxTypeLegacy
+	TxTypeDynamicFee = ethtx.TxTypeDynamicFee
+)
+
+// SignatureSize is the size of the signature: r || s || recovery id.
+const SignatureSize = ethtx.SignatureSize
+
+// Tx is the Ethereum transaction built and decoded by the connector.
+type Tx = ethtx.Tx
+
+// DecodeUnsignedTx decodes the signing payload built with TxBuild.
+func DecodeUnsignedTx(payload []byte) (*Tx, error) {
+	return ethtx.DecodeUnsignedTx(payload)
+}
+
+// DecodeTx decodes the signed tx.
+func DecodeTx(raw []byte) (*Tx, error) {
+	return ethtx.DecodeTx(raw)
+}
@@ -0,0 +1,134 @@
+package ltc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	witnessFlag = 0x01
+	// mwebFlag marks the transactions with the MWEB part, the canonical ones (HogEx) have it empty.
+	mwebFlag = 0x08
+
+	maxScriptSize = wire.MaxMessagePayload
+)
+
+// DecodeBlock decodes the Litecoin block. The transactions with MWEB flag are decoded without the empty
+// MWEB part and the MWEB extension block following the transactions is ignored.
+func DecodeBlock(data []byte) (*wire.MsgBlock, error) {
+	r := bytes.NewReader(data)
+	var block wire.MsgBlock
+	if err := block.Header.Deserialize(r); err != nil {
+		return nil, err
+	}
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(r.Len()) {
+		return nil, fmt.Errorf("too many transactions %d", count)
+	}
+	block.Transactions = make([]*wire.MsgTx, 0, count)
+	for i := uint64(0); i < count; i++ {
+		tx, err := readTx(r, data[len(data)-r.Len():])
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %s", i, err.Error())
+		}
+		block.Transactions = append(block.Transactions, tx)
+	}
+	return &block, nil
+}
+
+// readTx reads the transaction starting with rest.
+func readTx(r *bytes.Reader, rest []byte) (*wire.MsgTx, error) {
+	// version, marker and flags
+	if len(rest) < 6 || rest[4] != 0 || rest[5]&mwebFlag == 0 {
+		var tx wire.MsgTx
+		return &tx, tx.Deserialize(r)
+	}
+	tx := wire.MsgTx{Version: int32(binary.LittleEndian.Uint32(rest))}
+	flags := rest[5]
+	if _, err := r.Seek(6, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+
+	count, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		txIn := wire.TxIn{}
+		if _, err = io.ReadFull(r, txIn.PreviousOutPoint.Hash[:]); err != nil {
+			return nil, err
+		}
+		if err = binary.Read(r, binary.LittleEndian, &txIn.PreviousOutPoint.Index); err != nil {
+			return nil, err
+		}
+		if txIn.SignatureScript, err = wire.ReadVarBytes(r, 0, maxScriptSize, "signature script"); err != nil {
+			return nil, err
+		}
+		if err = binary.Read(r, binary.LittleEndian, &txIn.Sequence); err != nil {
+			return nil, err
+		}
+		tx.AddTxIn(&txIn)
+	}
+
+	count, err = readCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		txOut := wire.TxOut{}
+		if err = binary.Read(r, binary.LittleEndian, &txOut.Value); err != nil {
+			return nil, err
+		}
+		if txOut.PkScript, err = wire.ReadVarBytes(r, 0, maxScriptSize, "pk script"); err != nil {
+			return nil, err
+		}
+		tx.AddTxOut(&txOut)
+	}
+
+	if flags&witnessFlag != 0 {
+		for _, txIn := range tx.TxIn {
+			items, err := readCount(r)
+			if err != nil {
+				return nil, err
+			}
+			txIn.Witness = make(wire.TxWitness, items)
+			for j := range txIn.Witness {
+				if txIn.Witness[j], err = wire.ReadVarBytes(r, 0, maxScriptSize, "witness"); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// optional MWEB transaction
+	mweb, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if mweb != 0 {
+		return nil, fmt.Errorf("unsupported MWEB transaction data")
+	}
+	if err = binary.Read(r, binary.LittleEndian, &tx.LockTime); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// readCount reads the number of items, each of them takes at least a byte.
+func readCount(r *bytes.Reader) (uint64, error) {
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return 0, err
+	}
+	if count > uint64(r.Len()) {
+		return 0, fmt.Errorf("invalid count %d", count)
+	}
+	return count, nil
+}
@@ -0,0 +1,50 @@
+package ltc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeBlock(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, wire.TxWitness{{0x30, 0x01}, {0x02}}))
+	tx.AddTxOut(wire.NewTxOut(100000000, []byte{0x00, 0x14}))
+	tx.LockTime = 7
+
+	hogEx := wire.NewMsgTx(2)
+	hogEx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 1), nil, nil))
+	hogEx.AddTxOut(wire.NewTxOut(5000, []byte{0x58, 0x20}))
+	var base bytes.Buffer
+	assert.Nil(t, hogEx.SerializeNoWitness(&base), "unexpected error")
+	raw := base.Bytes()
+
+	var b bytes.Buffer
+	assert.Nil(t, (&wire.BlockHeader{Version: 0x20000000, PrevBlock: chainhash.Hash{7}}).Serialize(&b), "unexpected error")
+	b.Write([]byte{0x02})
+	assert.Nil(t, tx.Serialize(&b), "unexpected error")
+	// HogEx: version, marker, MWEB flag, inputs and outputs, empty MWEB part, lock time
+	b.Write(raw[:4])
+	b.Write([]byte{0x00, mwebFlag})
+	b.Write(raw[4 : len(raw)-4])
+	b.Write([]byte{0x00})
+	b.Write(raw[len(raw)-4:])
+	// MWEB extension block
+	b.Write([]byte{0x01, 0xde, 0xad})
+
+	block, err := DecodeBlock(b.Bytes())
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, chainhash.Hash{7}, block.Header.PrevBlock, "unexpected previous block")
+	assert.Len(t, block.Transactions, 2, "unexpected transactions")
+	assert.Equal(t, tx.TxHash(), block.Transactions[0].TxHash(), "unexpected tx")
+	assert.Equal(t, tx.TxIn[0].Witness, block.Transactions[0].TxIn[0].Witness, "unexpected witness")
+	assert.Equal(t, hogEx.TxHash(), block.Transactions[1].TxHash(), "unexpected HogEx tx")
+
+	data := b.Bytes()
+	data[len(data)-3-4-1] = 0x01
+	_, err = DecodeBlock(data)
+	assert.NotNil(t, err, "MWEB transaction data is not supported")
+}
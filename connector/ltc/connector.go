@@ -0,0 +1,41 @@
+package ltc
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// Params returns the chain params for the ChainConfig of the wallet, "testnet" and "regtest" use the test network.
+// The bech32 addresses of regtest (rltc) are not supported.
+func Params(chainConfig string) *chaincfg.Params {
+	switch chainConfig {
+	case "testnet", "regtest":
+		return &netparams.LtcTestNet4Params
+	}
+	return &netparams.LtcMainNetParams
+}
+
+// NewChainConnector returns the Litecoin connector on btc_example core,
+// it supports the ltc1 bech32 and M-prefixed P2SH addresses.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (btc_example.IBtcChainConnector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	bcc, err := btc_example.NewChainConnector(walletID, cfg, txBatchSize, Params(cfg.ChainConfig))
+	if err != nil {
+		return nil, err
+	}
+	bcc.BlockDecoderSet(DecodeBlock)
+	return bcc, nil
+}
+
+// NewBlockChainImporter creates the importer of Litecoin blocks.
+func NewBlockChainImporter(node connector.NodeParams, chainConfig string, txBatchSize int) (connector.BlockChainImporter, error) {
+	return btc_example.NewBlockChainImporterWithOptions(node, *Params(chainConfig), txBatchSize,
+		btc_example.ImporterOptions{DecodeBlock: DecodeBlock})
+}
@@ -0,0 +1,40 @@
+package ltc
+
+import (
+	"testing"
+
+	"github.com/stanche/crypto-interface/connector"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nodeParams struct{}
+
+func (nodeParams) GetHost() string     { return "127.0.0.1" }
+func (nodeParams) GetPort() int        { return 9332 }
+func (nodeParams) GetUser() string     { return "user" }
+func (nodeParams) GetPassword() string { return "pass" }
+
+func TestLtcChainConnector_ValidateAddress(t *testing.T) {
+	ltc, err := NewChainConnector(1, &connector.WalletParams{Currency: "LTC", Active: true, Node: nodeParams{}, Core: nodeParams{}}, 1)
+	assert.Nil(t, err, "unexpected error")
+
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", true},
+		{"MJaRnao1s62a2zAKSkmG582KbLKianqb7v", true},
+		{"LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", true},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", false},
+		{"3P14159f73E4gFr7JterCCQh9QjiTjiZrG", false},
+		{"tltc1qw508d6qejxtdg4y5r3zarvary0c5xw7klfsuq0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			valid, err := ltc.ValidateAddress(tt.address)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.want, valid, "unexpected validation")
+		})
+	}
+}
@@ -67,6 +67,9 @@ type (
 	// TxSender is an interface for transaction broadcasting.
 	TxSender interface {
 		TxBroadcast(txHex string) (txHash string, err error)
+		// TxValidate checks whether the node would accept the tx without broadcasting it.
+		// A rejection is reported in TxValidation, the error is returned if the check itself failed.
+		TxValidate(txHex string) (*TxValidation, error)
 	}
 
 	BlockChainImporter interface {
@@ -93,4 +96,12 @@ var (
 
 	ErrNotFound  = fmt.Errorf("not found")
 	ErrClientNil = fmt.Errorf("client is nil")
+
+	// Rejections of TxValidate.
+	ErrTxRejected      = fmt.Errorf("transaction rejected")
+	ErrTxInputsMissing = fmt.Errorf("transaction inputs are missing or spent")
+	ErrTxConflict      = fmt.Errorf("transaction conflicts with mempool")
+	ErrTxAlreadyKnown  = fmt.Errorf("transaction is already known")
+	ErrTxFeeTooLow     = fmt.Errorf("transaction fee is too low")
+	ErrTxFeeTooHigh    = fmt.Errorf("transaction fee is too high")
 )
@@ -44,13 +44,29 @@ type (
 	UtxStruct struct {
 		TxHash string
 		TxPos  int
-		//Value  decimal.Decimal
+		// Value is the amount of the spent output, it's required to spend or send the tokens.
+		Value decimal.Decimal
 		Index uint32
+		// Token is the token carried by the spent output, i.e. BCH CashTokens, nil if none.
+		Token *TokenParsed
 	}
 	OutputParsed struct {
 		Address string
 		Value   *big.Int
 		TxPos   uint
+		// Token is the token carried by the output besides the coins, i.e. BCH CashTokens, nil if none.
+		Token *TokenParsed
+	}
+
+	// TokenParsed describes the tokens of the output.
+	TokenParsed struct {
+		// Category is the token id, for CashTokens it is the genesis txid, which is the token address of the currency.
+		Category string
+		// Amount is the amount of the fungible tokens, zero if the output carries the NFT only.
+		Amount *big.Int
+		// Capability is the NFT capability (none, mutable or minting), empty if the output carries no NFT.
+		Capability string
+		Commitment []byte
 	}
 
 	// OutStruct - defines outputs
@@ -63,6 +79,18 @@ type (
 		IsChange              bool
 	}
 
+	// TxValidation defines response from TxSender.TxValidate()
+	TxValidation struct {
+		TxID    string
+		Allowed bool
+		// VSize and Fee are zero and nil if not reported by the node.
+		VSize int64
+		Fee   *big.Int
+		// RejectReason is the reason reported by the node, Err is the typed rejection.
+		RejectReason string
+		Err          error
+	}
+
 	// TxStatusStruct defines response from Connector.TxGet()
 	// int64 used as answer could contain (-1, -1) in case of fork when the Tx was discarded
 	TxStatusStruct struct {
@@ -0,0 +1,25 @@
+// Package xec provides the eCash connector built on the BCH one.
+package xec
+
+import (
+	"fmt"
+
+	"github.com/stanche/crypto-interface/connector"
+	"github.com/stanche/crypto-interface/connector/bch"
+	"github.com/stanche/crypto-interface/connector/btc_example"
+	"github.com/stanche/crypto-interface/netparams"
+)
+
+// NewChainConnector returns the eCash connector. The addresses have ecash: (ectest:, ecregtest:) CashAddr prefix
+// and the amounts are in XEC with 2 decimal places.
+func NewChainConnector(walletID uint64, cfg *connector.WalletParams, txBatchSize int) (btc_example.IBtcChainConnector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Wallet configuration parameters absent")
+	}
+	return bch.NewForkChainConnector(walletID, cfg, txBatchSize, netparams.XecFork(cfg.ChainConfig))
+}
+
+// NewBlockChainImporter creates the importer of eCash blocks.
+func NewBlockChainImporter(node connector.NodeParams, chainConfig string, txBatchSize int) (connector.BlockChainImporter, error) {
+	return bch.NewForkBlockChainImporter(node, netparams.XecFork(chainConfig), txBatchSize)
+}
@@ -0,0 +1,150 @@
+package ethtx
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+// rlpList is the list item of RLP, the other items are []byte strings.
+type rlpList []interface{}
+
+// rlpEncode encodes []byte, uint64, *big.Int and rlpList items. The numbers are big-endian without leading zeroes.
+func rlpEncode(item interface{}) []byte {
+	switch v := item.(type) {
+	case []byte:
+		if len(v) == 1 && v[0] < 0x80 {
+			return []byte{v[0]}
+		}
+		return append(rlpHeader(0x80, len(v)), v...)
+	case uint64:
+		return rlpEncode(new(big.Int).SetUint64(v).Bytes())
+	case *big.Int:
+		if v == nil {
+			return rlpEncode([]byte{})
+		}
+		return rlpEncode(v.Bytes())
+	case rlpList:
+		var payload bytes.Buffer
+		for _, el := range v {
+			payload.Write(rlpEncode(el))
+		}
+		return append(rlpHeader(0xc0, payload.Len()), payload.Bytes()...)
+	}
+	panic(fmt.Sprintf("rlp: unsupported type %T", item))
+}
+
+// rlpHeader returns the prefix of the string (offset 0x80) or the list (offset 0xc0) of the size.
+func rlpHeader(offset byte, size int) []byte {
+	if size <= 55 {
+		return []byte{offset + byte(size)}
+	}
+	sizeBytes := big.NewInt(int64(size)).Bytes()
+	return append([]byte{offset + 55 + byte(len(sizeBytes))}, sizeBytes...)
+}
+
+// rlpDecode decodes the single item, the data shall have no trailing bytes.
+func rlpDecode(data []byte) (interface{}, error) {
+	item, rest, err := rlpSplit(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("rlp: %d trailing bytes", len(rest))
+	}
+	return item, nil
+}
+
+// rlpSplit decodes the first item of the data and returns the rest. Non-canonical encodings are rejected.
+func rlpSplit(data []byte) (item interface{}, rest []byte, err error) {
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("rlp: unexpected end of data")
+	}
+	prefix := data[0]
+	var offset, size int
+	isList := prefix >= 0xc0
+	switch {
+	case prefix < 0x80:
+		return data[:1], data[1:], nil
+	case prefix <= 0xb7 || (prefix >= 0xc0 && prefix <= 0xf7):
+		offset, size = 1, int(prefix-0x80)
+		if isList {
+			size = int(prefix - 0xc0)
+		}
+	default:
+		lenSize := int(prefix - 0xb7)
+		if isList {
+			lenSize = int(prefix - 0xf7)
+		}
+		if len(data) < 1+lenSize {
+			return nil, nil, fmt.Errorf("rlp: unexpected end of data")
+		}
+		if data[1] == 0 {
+			return nil, nil, fmt.Errorf("rlp: non-canonical size")
+		}
+		s := new(big.Int).SetBytes(data[1 : 1+lenSize])
+		if !s.IsInt64() || s.Int64() > int64(len(data)) || s.Int64() <= 55 {
+			return nil, nil, fmt.Errorf("rlp: invalid size")
+		}
+		offset, size = 1+lenSize, int(s.Int64())
+	}
+	if len(data) < offset+size {
+		return nil, nil, fmt.Errorf("rlp: unexpected end of data")
+	}
+	payload, rest := data[offset:offset+size], data[offset+size:]
+	if !isList {
+		if size == 1 && payload[0] < 0x80 {
+			return nil, nil, fmt.Errorf("rlp: non-canonical single byte")
+		}
+		return payload, rest, nil
+	}
+	list := rlpList{}
+	for len(payload) > 0 {
+		var el interface{}
+		el, payload, err = rlpSplit(payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		list = append(list, el)
+	}
+	return list, rest, nil
+}
+
+// rlpBytes returns the string item of the list.
+func rlpBytes(list rlpList, i int) ([]byte, error) {
+	if i >= len(list) {
+		return nil, fmt.Errorf("rlp: item %d is missing", i)
+	}
+	b, ok := list[i].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("rlp: item %d is not a string", i)
+	}
+	return b, nil
+}
+
+// rlpBig returns the number item of the list.
+func rlpBig(list rlpList, i int) (*big.Int, error) {
+	b, err := rlpBytes(list, i)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 && b[0] == 0 {
+		return nil, fmt.Errorf("rlp: item %d has leading zeroes", i)
+	}
+	if len(b) > 32 {
+		return nil, fmt.Errorf("rlp: item %d is too big", i)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// rlpUint64 returns the uint64 item of the list.
+func rlpUint64(list rlpList, i int) (uint64, error) {
+	n, err := rlpBig(list, i)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("rlp: item %d overflows uint64", i)
+	}
+	return n.Uint64(), nil
+}
@@ -0,0 +1,285 @@
+// Package ethtx encodes and decodes Ethereum transactions: EIP-155 legacy and EIP-1559 dynamic fee ones.
+// It has no dependencies on the connectors, so the signer decodes the tx before signing it.
+package ethtx
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/stanche/crypto-interface/abi"
+	"github.com/stanche/crypto-interface/keccak"
+)
+
+// Transaction types.
+const (
+	TxTypeLegacy     = 0
+	TxTypeDynamicFee = 2
+)
+
+// SignatureSize is the size of the signature: r || s || recovery id.
+const SignatureSize = 65
+
+// Tx is the Ethereum transaction. The legacy transactions are signed with EIP-155 replay protection,
+// the dynamic fee ones are EIP-1559 transactions with the empty access list.
+type Tx struct {
+	Type    uint8
+	ChainID *big.Int
+	Nonce   uint64
+	// GasPrice is the price of the legacy tx, GasTipCap and GasFeeCap are the EIP-1559 ones.
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	Gas       uint64
+	To        []byte
+	Value     *big.Int
+	Data      []byte
+	// V is the recovery id of the signature, R and S are nil if the tx is unsigned.
+	V    byte
+	R, S *big.Int
+}
+
+// SigningPayload returns the serialized unsigned tx which is hashed for the signature.
+// It's the TxBuild result and is decoded with DecodeUnsignedTx.
+func (tx *Tx) SigningPayload() []byte {
+	if tx.Type == TxTypeDynamicFee {
+		return append([]byte{TxTypeDynamicFee}, rlpEncode(tx.dynamicFeeFields())...)
+	}
+	return rlpEncode(append(tx.legacyFields(), tx.ChainID, uint64(0), uint64(0)))
+}
+
+// SigningHash returns the hash signed by the sender key.
+func (tx *Tx) SigningHash() []byte {
+	return keccak.Sum256(tx.SigningPayload())
+}
+
+// Encode serializes the signed tx for eth_sendRawTransaction.
+func (tx *Tx) Encode() ([]byte, error) {
+	if tx.R == nil || tx.S == nil {
+		return nil, fmt.Errorf("tx is not signed")
+	}
+	if tx.Type == TxTypeDynamicFee {
+		return append([]byte{TxTypeDynamicFee}, rlpEncode(append(tx.dynamicFeeFields(), uint64(tx.V), tx.R, tx.S))...), nil
+	}
+	// EIP-155 v = chain id * 2 + 35 + recovery id, 27 or 28 for the tx without the chain id
+	v := big.NewInt(int64(tx.V) + 27)
+	if tx.ChainID != nil {
+		v = new(big.Int).Lsh(tx.ChainID, 1)
+		v.Add(v, big.NewInt(int64(tx.V)+35))
+	}
+	return rlpEncode(append(tx.legacyFields(), v, tx.R, tx.S)), nil
+}
+
+// Hash returns the hash of the signed tx, i.e. the tx id.
+func (tx *Tx) Hash() ([]byte, error) {
+	raw, err := tx.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return keccak.Sum256(raw), nil
+}
+
+// FeeCap returns the maximal price of the gas.
+func (tx *Tx) FeeCap() *big.Int {
+	if tx.Type == TxTypeDynamicFee {
+		return tx.GasFeeCap
+	}
+	return tx.GasPrice
+}
+
+// MaxFee returns the maximal fee of the tx: gas limit * fee cap.
+func (tx *Tx) MaxFee() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas), tx.FeeCap())
+}
+
+// SetSignature sets the signature r || s || recovery id. The high s values are rejected as of EIP-2.
+func (tx *Tx) SetSignature(sig []byte) error {
+	if len(sig) != SignatureSize {
+		return fmt.Errorf("invalid signature size %d", len(sig))
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	halfOrder := new(big.Int).Rsh(btcec.S256().N, 1)
+	if r.Sign() == 0 || s.Sign() == 0 || s.Cmp(halfOrder) > 0 || sig[64] > 1 {
+		return fmt.Errorf("invalid signature values")
+	}
+	tx.V, tx.R, tx.S = sig[64], r, s
+	return nil
+}
+
+// Sender recovers the address of the signed tx sender.
+func (tx *Tx) Sender() ([]byte, error) {
+	if tx.R == nil || tx.S == nil {
+		return nil, fmt.Errorf("tx is not signed")
+	}
+	compact := make([]byte, SignatureSize)
+	compact[0] = 27 + tx.V
+	abi.PutUint(compact[1:33], tx.R)
+	abi.PutUint(compact[33:], tx.S)
+	pub, _, err := btcec.RecoverCompact(btcec.S256(), compact, tx.SigningHash())
+	if err != nil {
+		return nil, fmt.Errorf("cannot recover sender: %s", err.Error())
+	}
+	return PubkeyToAddress(pub.ToECDSA()), nil
+}
+
+// PubkeyToAddress returns the 20-byte address of the public key.
+func PubkeyToAddress(pub *ecdsa.PublicKey) []byte {
+	var uncompressed [64]byte
+	x, y := pub.X.Bytes(), pub.Y.Bytes()
+	copy(uncompressed[32-len(x):], x)
+	copy(uncompressed[64-len(y):], y)
+	return keccak.Sum256(uncompressed[:])[32-abi.AddressSize:]
+}
+
+func (tx *Tx) legacyFields() rlpList {
+	return rlpList{tx.Nonce, tx.GasPrice, tx.Gas, tx.to(), tx.Value, tx.data()}
+}
+
+func (tx *Tx) dynamicFeeFields() rlpList {
+	return rlpList{tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas, tx.to(), tx.Value, tx.data(), rlpList{}}
+}
+
+func (tx *Tx) to() []byte {
+	if tx.To == nil {
+		return []byte{}
+	}
+	return tx.To
+}
+
+func (tx *Tx) data() []byte {
+	if tx.Data == nil {
+		return []byte{}
+	}
+	return tx.Data
+}
+
+// DecodeUnsignedTx decodes the signing payload built with TxBuild.
+func DecodeUnsignedTx(payload []byte) (*Tx, error) {
+	return decodeTx(payload, false)
+}
+
+// DecodeTx decodes the signed tx.
+func DecodeTx(raw []byte) (*Tx, error) {
+	return decodeTx(raw, true)
+}
+
+func decodeTx(data []byte, signed bool) (*Tx, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty tx")
+	}
+	tx := &Tx{Type: TxTypeLegacy}
+	if data[0] < 0xc0 {
+		if data[0] != TxTypeDynamicFee {
+			return nil, fmt.Errorf("unsupported tx type %d", data[0])
+		}
+		tx.Type, data = TxTypeDynamicFee, data[1:]
+	}
+	item, err := rlpDecode(data)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := item.(rlpList)
+	if !ok {
+		return nil, fmt.Errorf("tx is not a list")
+	}
+
+	size := 9
+	if tx.Type == TxTypeDynamicFee && signed {
+		size = 12
+	}
+	if len(list) != size {
+		return nil, fmt.Errorf("unexpected tx fields quantity %d", len(list))
+	}
+	// the fields shared by the types follow the fee ones
+	common := 2
+	if tx.Type == TxTypeDynamicFee {
+		if tx.ChainID, err = rlpBig(list, 0); err != nil {
+			return nil, err
+		}
+		if tx.Nonce, err = rlpUint64(list, 1); err != nil {
+			return nil, err
+		}
+		if tx.GasTipCap, err = rlpBig(list, 2); err != nil {
+			return nil, err
+		}
+		if tx.GasFeeCap, err = rlpBig(list, 3); err != nil {
+			return nil, err
+		}
+		common = 4
+	} else {
+		if tx.Nonce, err = rlpUint64(list, 0); err != nil {
+			return nil, err
+		}
+		if tx.GasPrice, err = rlpBig(list, 1); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Gas, err = rlpUint64(list, common); err != nil {
+		return nil, err
+	}
+	if tx.To, err = rlpBytes(list, common+1); err != nil {
+		return nil, err
+	}
+	if len(tx.To) != 0 && len(tx.To) != abi.AddressSize {
+		return nil, fmt.Errorf("invalid recipient size %d", len(tx.To))
+	}
+	if tx.Value, err = rlpBig(list, common+2); err != nil {
+		return nil, err
+	}
+	if tx.Data, err = rlpBytes(list, common+3); err != nil {
+		return nil, err
+	}
+
+	if tx.Type == TxTypeDynamicFee {
+		if accessList, ok := list[8].(rlpList); !ok || len(accessList) != 0 {
+			return nil, fmt.Errorf("unsupported access list")
+		}
+		if !signed {
+			return tx, nil
+		}
+		return tx, tx.decodeSignature(list[9:])
+	}
+	if !signed {
+		// EIP-155 chain id, 0, 0
+		if tx.ChainID, err = rlpBig(list, 6); err != nil {
+			return nil, err
+		}
+		r, _ := rlpBytes(list, 7)
+		s, _ := rlpBytes(list, 8)
+		if len(r) != 0 || len(s) != 0 {
+			return nil, fmt.Errorf("unexpected signature of the unsigned tx")
+		}
+		return tx, nil
+	}
+	return tx, tx.decodeSignature(list[6:])
+}
+
+// decodeSignature decodes v, r, s. EIP-155 v of the legacy tx contains the chain id.
+func (tx *Tx) decodeSignature(list rlpList) error {
+	v, err := rlpBig(list, 0)
+	if err != nil {
+		return err
+	}
+	if tx.R, err = rlpBig(list, 1); err != nil {
+		return err
+	}
+	if tx.S, err = rlpBig(list, 2); err != nil {
+		return err
+	}
+	switch {
+	case tx.Type == TxTypeDynamicFee && v.IsUint64() && v.Uint64() <= 1:
+		tx.V = byte(v.Uint64())
+	case tx.Type == TxTypeLegacy && v.IsUint64() && (v.Uint64() == 27 || v.Uint64() == 28):
+		tx.V = byte(v.Uint64() - 27)
+	case tx.Type == TxTypeLegacy && v.Cmp(big.NewInt(35)) >= 0:
+		v.Sub(v, big.NewInt(35))
+		tx.V = byte(v.Bit(0))
+		tx.ChainID = v.Rsh(v, 1)
+	default:
+		return fmt.Errorf("invalid signature v %s", v.String())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package ethtx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/abi"
+)
+
+// signTx signs the tx with the key, the signature is r || s || recovery id.
+func signTx(t *testing.T, tx *Tx, key *btcec.PrivateKey) {
+	compact, err := btcec.SignCompact(btcec.S256(), key, tx.SigningHash(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.SetSignature(append(compact[1:], compact[0]-27)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTx_EIP155(t *testing.T) {
+	// the example of EIP-155
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	tx := &Tx{
+		ChainID:  big.NewInt(1),
+		Nonce:    9,
+		GasPrice: big.NewInt(20000000000),
+		Gas:      21000,
+		To:       bytes.Repeat([]byte{0x35}, 20),
+		Value:    big.NewInt(1000000000000000000),
+	}
+	assert.Equal(t, "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53", hex.EncodeToString(tx.SigningHash()),
+		"unexpected signing hash")
+
+	unsigned, err := DecodeUnsignedTx(tx.SigningPayload())
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, tx.SigningPayload(), unsigned.SigningPayload(), "unexpected unsigned tx")
+
+	signTx(t, tx, key)
+	raw, err := tx.Encode()
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83",
+		hex.EncodeToString(raw), "unexpected signed tx")
+
+	decoded, err := DecodeTx(raw)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, int64(1), decoded.ChainID.Int64(), "unexpected chain id")
+	sender, err := decoded.Sender()
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", hex.EncodeToString(sender), "unexpected sender")
+}
+
+func TestTx_DynamicFee(t *testing.T) {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	tx := &Tx{
+		Type:      TxTypeDynamicFee,
+		ChainID:   big.NewInt(5),
+		Nonce:     300,
+		GasTipCap: big.NewInt(1500000000),
+		GasFeeCap: big.NewInt(61500000000),
+		Gas:       60000,
+		To:        bytes.Repeat([]byte{0x35}, 20),
+		Value:     big.NewInt(0),
+		Data:      []byte{0xa9, 0x05, 0x9c, 0xbb},
+	}
+	assert.Equal(t, byte(TxTypeDynamicFee), tx.SigningPayload()[0], "unexpected type prefix")
+	assert.Equal(t, "3690000000000000", tx.MaxFee().String(), "unexpected max fee")
+
+	unsigned, err := DecodeUnsignedTx(tx.SigningPayload())
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, tx, unsigned, "unexpected unsigned tx")
+
+	_, err = DecodeTx(tx.SigningPayload())
+	assert.NotNil(t, err, "expected error for unsigned tx")
+	_, err = tx.Encode()
+	assert.NotNil(t, err, "expected error for unsigned tx")
+
+	signTx(t, tx, key)
+	raw, err := tx.Encode()
+	assert.Nil(t, err, "unexpected error")
+	decoded, err := DecodeTx(raw)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, tx, decoded, "unexpected decoded tx")
+	sender, err := decoded.Sender()
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", hex.EncodeToString(sender), "unexpected sender")
+
+	// high s
+	sig := make([]byte, SignatureSize)
+	abi.PutUint(sig[:32], tx.R)
+	abi.PutUint(sig[32:64], new(big.Int).Sub(btcec.S256().N, tx.S))
+	assert.NotNil(t, tx.SetSignature(sig), "expected error for high s")
+}
+
+func TestRLP(t *testing.T) {
+	for _, tc := range []struct {
+		item interface{}
+		hex  string
+	}{
+		{[]byte("dog"), "83646f67"},
+		{rlpList{[]byte("cat"), []byte("dog")}, "c88363617483646f67"},
+		{[]byte{}, "80"},
+		{rlpList{}, "c0"},
+		{uint64(0), "80"},
+		{uint64(15), "0f"},
+		{uint64(1024), "820400"},
+		{bytes.Repeat([]byte{'a'}, 56), "b838" + hex.EncodeToString(bytes.Repeat([]byte{'a'}, 56))},
+	} {
+		encoded := rlpEncode(tc.item)
+		assert.Equal(t, tc.hex, hex.EncodeToString(encoded), "unexpected encoding of %v", tc.item)
+		decoded, err := rlpDecode(encoded)
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, encoded, rlpEncode(decoded), "unexpected decoding of %v", tc.item)
+	}
+
+	for name, data := range map[string]string{
+		"single byte":     "8100",
+		"short long size": "b80100",
+		"truncated":       "83646f",
+		"trailing":        "83646f6700",
+	} {
+		raw, _ := hex.DecodeString(data)
+		_, err := rlpDecode(raw)
+		assert.NotNil(t, err, "expected error for %s", name)
+	}
+}
@@ -0,0 +1,16 @@
+// Package keccak implements the legacy Keccak-256 hash used by Ethereum, it has no dependencies
+// on the connectors, so the signer uses it as well.
+package keccak
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+// Sum256 returns the legacy Keccak-256 hash of the concatenated data.
+func Sum256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
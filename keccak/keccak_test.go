@@ -0,0 +1,14 @@
+package keccak
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum256(t *testing.T) {
+	assert.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(Sum256()),
+		"unexpected hash of the empty data")
+	assert.Equal(t, Sum256([]byte("safe tx")), Sum256([]byte("safe"), []byte(" tx")), "the data shall be concatenated")
+}
@@ -0,0 +1,72 @@
+package netparams
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// ForkParams defines the parameters of BCH and the chains forked from it.
+// The forks share the transaction format and the legacy address versions of BTC.
+type ForkParams struct {
+	// Code is the currency code.
+	Code string
+	// ForkID is put into the upper 24 bits of the SIGHASH_FORKID signature hash type.
+	ForkID uint32
+	// CashAddrPrefix is the CashAddr prefix of the network, it is empty if the chain uses the legacy addresses only.
+	CashAddrPrefix string
+	// Net provides the legacy address versions.
+	Net *chaincfg.Params
+	// Precision is the number of decimal places of the coin, i.e. satoshis per coin is 10^Precision.
+	Precision uint8
+	// Schnorr is set if the chain accepts 64-byte Schnorr signatures.
+	Schnorr bool
+	// CashTokens is set if the outputs may carry the tokens prefix.
+	CashTokens bool
+	// Standard is the relay policy of the nodes.
+	Standard StandardPolicy
+}
+
+// BCH networks.
+var (
+	BchMainNetFork = ForkParams{Code: "BCH", CashAddrPrefix: "bitcoincash", Net: &chaincfg.MainNetParams, Precision: 8, Schnorr: true, CashTokens: true, Standard: BchStandardPolicy}
+	BchTestNetFork = ForkParams{Code: "BCH", CashAddrPrefix: "bchtest", Net: &chaincfg.TestNet3Params, Precision: 8, Schnorr: true, CashTokens: true, Standard: BchStandardPolicy}
+	BchRegTestFork = ForkParams{Code: "BCH", CashAddrPrefix: "bchreg", Net: &chaincfg.TestNet3Params, Precision: 8, Schnorr: true, CashTokens: true, Standard: BchStandardPolicy}
+)
+
+// eCash networks. XEC is redenominated: 1 XEC is 100 satoshis.
+var (
+	XecMainNetFork = ForkParams{Code: "XEC", CashAddrPrefix: "ecash", Net: &chaincfg.MainNetParams, Precision: 2, Schnorr: true, Standard: BchStandardPolicy}
+	XecTestNetFork = ForkParams{Code: "XEC", CashAddrPrefix: "ectest", Net: &chaincfg.TestNet3Params, Precision: 2, Schnorr: true, Standard: BchStandardPolicy}
+	XecRegTestFork = ForkParams{Code: "XEC", CashAddrPrefix: "ecregtest", Net: &chaincfg.TestNet3Params, Precision: 2, Schnorr: true, Standard: BchStandardPolicy}
+)
+
+// BSV networks. BSV dropped CashAddr and does not accept Schnorr signatures.
+var (
+	BsvMainNetFork = ForkParams{Code: "BSV", Net: &chaincfg.MainNetParams, Precision: 8, Standard: BsvStandardPolicy}
+	BsvTestNetFork = ForkParams{Code: "BSV", Net: &chaincfg.TestNet3Params, Precision: 8, Standard: BsvStandardPolicy}
+	BsvRegTestFork = ForkParams{Code: "BSV", Net: &chaincfg.TestNet3Params, Precision: 8, Standard: BsvStandardPolicy}
+)
+
+// BchFork returns the BCH parameters for the ChainConfig of the wallet.
+func BchFork(chainConfig string) ForkParams {
+	return forkByChainConfig(chainConfig, BchMainNetFork, BchTestNetFork, BchRegTestFork)
+}
+
+// XecFork returns the eCash parameters for the ChainConfig of the wallet.
+func XecFork(chainConfig string) ForkParams {
+	return forkByChainConfig(chainConfig, XecMainNetFork, XecTestNetFork, XecRegTestFork)
+}
+
+// BsvFork returns the BSV parameters for the ChainConfig of the wallet.
+func BsvFork(chainConfig string) ForkParams {
+	return forkByChainConfig(chainConfig, BsvMainNetFork, BsvTestNetFork, BsvRegTestFork)
+}
+
+func forkByChainConfig(chainConfig string, mainNet, testNet, regTest ForkParams) ForkParams {
+	switch chainConfig {
+	case "regtest":
+		return regTest
+	case "testnet":
+		return testNet
+	}
+	return mainNet
+}
@@ -0,0 +1,119 @@
+// Package netparams defines the chain parameters of the BTC-like currencies which are absent in btcd.
+// The parameters are registered in btcd chaincfg, so the bech32 addresses of the chains can be decoded.
+package netparams
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// LtcMainNetParams defines the network parameters of the Litecoin main network.
+var LtcMainNetParams = chaincfg.Params{
+	Name:        "ltc-mainnet",
+	Net:         wire.BitcoinNet(0xdbb6c0fb),
+	DefaultPort: "9333",
+
+	Bech32HRPSegwit: "ltc",
+
+	PubKeyHashAddrID: 0x30, // starts with L
+	ScriptHashAddrID: 0x32, // starts with M
+	PrivateKeyID:     0xb0,
+
+	HDPrivateKeyID: [4]byte{0x04, 0x88, 0xad, 0xe4}, // xprv
+	HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e}, // xpub
+	HDCoinType:     2,
+}
+
+// LtcTestNet4Params defines the network parameters of the Litecoin test network (version 4).
+// The regression test network uses the same addresses except bech32 ones (rltc).
+var LtcTestNet4Params = chaincfg.Params{
+	Name:        "ltc-testnet4",
+	Net:         wire.BitcoinNet(0xf1c8d2fd),
+	DefaultPort: "19335",
+
+	Bech32HRPSegwit: "tltc",
+
+	PubKeyHashAddrID: 0x6f, // starts with m or n
+	ScriptHashAddrID: 0x3a, // starts with Q
+	PrivateKeyID:     0xef,
+
+	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // tprv
+	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // tpub
+	HDCoinType:     1,
+}
+
+// DogeMainNetParams defines the network parameters of the Dogecoin main network.
+var DogeMainNetParams = chaincfg.Params{
+	Name:        "doge-mainnet",
+	Net:         wire.BitcoinNet(0xc0c0c0c0),
+	DefaultPort: "22556",
+
+	PubKeyHashAddrID: 0x1e, // starts with D
+	ScriptHashAddrID: 0x16, // starts with 9 or A
+	PrivateKeyID:     0x9e,
+
+	HDPrivateKeyID: [4]byte{0x02, 0xfa, 0xc3, 0x98}, // dgpv
+	HDPublicKeyID:  [4]byte{0x02, 0xfa, 0xca, 0xfd}, // dgub
+	HDCoinType:     3,
+}
+
+// DogeTestNetParams defines the network parameters of the Dogecoin test network.
+var DogeTestNetParams = chaincfg.Params{
+	Name:        "doge-testnet",
+	Net:         wire.BitcoinNet(0xdcb7c1fc),
+	DefaultPort: "44556",
+
+	PubKeyHashAddrID: 0x71, // starts with n
+	ScriptHashAddrID: 0xc4, // starts with 2
+	PrivateKeyID:     0xf1,
+
+	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // tprv
+	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // tpub
+	HDCoinType:     1,
+}
+
+// DashMainNetParams defines the network parameters of the Dash main network.
+var DashMainNetParams = chaincfg.Params{
+	Name:        "dash-mainnet",
+	Net:         wire.BitcoinNet(0xbd6b0cbf),
+	DefaultPort: "9999",
+
+	PubKeyHashAddrID: 0x4c, // starts with X
+	ScriptHashAddrID: 0x10, // starts with 7
+	PrivateKeyID:     0xcc,
+
+	HDPrivateKeyID: [4]byte{0x04, 0x88, 0xad, 0xe4}, // xprv
+	HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e}, // xpub
+	HDCoinType:     5,
+}
+
+// DashTestNetParams defines the network parameters of the Dash test network.
+// The regression test network uses the same addresses.
+var DashTestNetParams = chaincfg.Params{
+	Name:        "dash-testnet",
+	Net:         wire.BitcoinNet(0xffcae2ce),
+	DefaultPort: "19999",
+
+	PubKeyHashAddrID: 0x8c, // starts with y
+	ScriptHashAddrID: 0x13, // starts with 8 or 9
+	PrivateKeyID:     0xef,
+
+	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // tprv
+	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // tpub
+	HDCoinType:     1,
+}
+
+func mustRegister(params *chaincfg.Params) {
+	if err := chaincfg.Register(params); err != nil {
+		panic("failed to register network " + params.Name + ": " + err.Error())
+	}
+}
+
+func init() {
+	mustRegister(&LtcMainNetParams)
+	mustRegister(&LtcTestNet4Params)
+	mustRegister(&DogeMainNetParams)
+	mustRegister(&DogeTestNetParams)
+	mustRegister(&DashMainNetParams)
+	mustRegister(&DashTestNetParams)
+}
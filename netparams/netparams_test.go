@@ -0,0 +1,69 @@
+package netparams
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams_addresses(t *testing.T) {
+	hash := bytes.Repeat([]byte{0x42}, 20)
+
+	tests := []struct {
+		name       string
+		params     *chaincfg.Params
+		pkhPrefix  string
+		p2shPrefix string
+	}{
+		{"ltc mainnet", &LtcMainNetParams, "L", "M"},
+		{"ltc testnet", &LtcTestNet4Params, "m", "Q"},
+		{"doge mainnet", &DogeMainNetParams, "D", "9"},
+		{"doge testnet", &DogeTestNetParams, "n", "2"},
+		{"dash mainnet", &DashMainNetParams, "X", "7"},
+		{"dash testnet", &DashTestNetParams, "y", "8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkh, err := btcutil.NewAddressPubKeyHash(hash, tt.params)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.pkhPrefix, pkh.EncodeAddress()[:1], "unexpected P2PKH address %s", pkh.EncodeAddress())
+
+			sh, err := btcutil.NewAddressScriptHashFromHash(hash, tt.params)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.p2shPrefix, sh.EncodeAddress()[:1], "unexpected P2SH address %s", sh.EncodeAddress())
+
+			for _, addr := range []btcutil.Address{pkh, sh} {
+				decoded, err := btcutil.DecodeAddress(addr.EncodeAddress(), tt.params)
+				assert.Nil(t, err, "unexpected error")
+				assert.True(t, decoded.IsForNet(tt.params), "address shall be for the net")
+				assert.Equal(t, addr.ScriptAddress(), decoded.ScriptAddress(), "unexpected hash")
+				_, err = btcutil.DecodeAddress(addr.EncodeAddress(), &chaincfg.MainNetParams)
+				assert.NotNil(t, err, "address of another net shall be rejected")
+			}
+		})
+	}
+}
+
+func TestParams_bech32(t *testing.T) {
+	// BIP173 example program with the Litecoin prefix
+	addr, err := btcutil.DecodeAddress("ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", &LtcMainNetParams)
+	assert.Nil(t, err, "unexpected error")
+	assert.True(t, addr.IsForNet(&LtcMainNetParams), "address shall be for the net")
+	assert.False(t, addr.IsForNet(&chaincfg.MainNetParams), "address shall not be for BTC")
+	assert.Equal(t, "751e76e8199196d454941c45d1b3a323f1433bd6", hex.EncodeToString(addr.ScriptAddress()), "unexpected program")
+
+	_, err = btcutil.DecodeAddress("tltc1qw508d6qejxtdg4y5r3zarvary0c5xw7klfsuq0", &LtcTestNet4Params)
+	assert.Nil(t, err, "unexpected error")
+}
+
+func TestStandard(t *testing.T) {
+	assert.Equal(t, BtcStandardPolicy, Standard(&chaincfg.MainNetParams), "unexpected BTC policy")
+	assert.Equal(t, BtcStandardPolicy, Standard(nil), "unexpected default policy")
+	assert.Equal(t, LtcStandardPolicy, Standard(&LtcTestNet4Params), "unexpected LTC policy")
+	assert.Equal(t, DogeStandardPolicy, Standard(&DogeMainNetParams), "unexpected DOGE policy")
+	assert.Equal(t, DashStandardPolicy, Standard(&DashTestNetParams), "unexpected DASH policy")
+}
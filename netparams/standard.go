@@ -0,0 +1,54 @@
+package netparams
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// StandardPolicy defines the relay policy limits which differ between the chains.
+type StandardPolicy struct {
+	// MaxVersion is the maximal standard version of the transactions.
+	MaxVersion int32
+	// DustRelayFeeRate is the fee rate in satoshi per 1000 bytes defining the dust threshold
+	// as the cost of the output and of its spending.
+	DustRelayFeeRate int64
+	// DustLimit is the fixed minimal value of an output, it replaces DustRelayFeeRate if set.
+	DustLimit int64
+	// MaxOpReturnRelay is the maximal size of the null data script, zero disables the limit.
+	MaxOpReturnRelay int
+	// NoScriptHash rejects P2SH outputs: BSV does not evaluate them as P2SH since the Genesis upgrade,
+	// so anyone revealing the redeem script spends them, and the nodes do not relay them.
+	NoScriptHash bool
+	// LockingScript strips the chain specific prefix of the output script, i.e. BCH CashTokens,
+	// nil if the outputs carry the locking script only.
+	LockingScript func(pkScript []byte) ([]byte, error)
+}
+
+// Standardness policies of the nodes.
+var (
+	BtcStandardPolicy  = StandardPolicy{MaxVersion: 2, DustRelayFeeRate: 3000, MaxOpReturnRelay: 83}
+	LtcStandardPolicy  = StandardPolicy{MaxVersion: 2, DustRelayFeeRate: 30000, MaxOpReturnRelay: 83}
+	DogeStandardPolicy = StandardPolicy{MaxVersion: 2, DustLimit: 1000000, MaxOpReturnRelay: 83} // 0.01 DOGE
+	// Dash special transactions (DIP2) use the version 3.
+	DashStandardPolicy = StandardPolicy{MaxVersion: 3, DustRelayFeeRate: 3000, MaxOpReturnRelay: 83}
+	// BCH and eCash allow 223 bytes of the null data.
+	BchStandardPolicy = StandardPolicy{MaxVersion: 2, DustRelayFeeRate: 3000, MaxOpReturnRelay: 223}
+	// BSV relays outputs of 1 satoshi, does not limit the null data and rejects P2SH.
+	BsvStandardPolicy = StandardPolicy{MaxVersion: 2, DustLimit: 1, NoScriptHash: true}
+)
+
+// Standard returns the standardness policy of the chain, BTC one for unknown chains.
+// The forks of BCH share the networks of BTC, their policy is in ForkParams.
+func Standard(net *chaincfg.Params) StandardPolicy {
+	if net == nil {
+		return BtcStandardPolicy
+	}
+	switch net.Net {
+	case LtcMainNetParams.Net, LtcTestNet4Params.Net:
+		return LtcStandardPolicy
+	case DogeMainNetParams.Net, DogeTestNetParams.Net:
+		return DogeStandardPolicy
+	case DashMainNetParams.Net, DashTestNetParams.Net:
+		return DashStandardPolicy
+	}
+	return BtcStandardPolicy
+}
@@ -0,0 +1,167 @@
+// Package safetx defines the Safe (Gnosis Safe v1.3) transaction signed by the owners: EIP-712 hash
+// and the payload passed from the ETH connector to the signer. It has no dependencies on the connectors.
+package safetx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/stanche/crypto-interface/abi"
+	"github.com/stanche/crypto-interface/keccak"
+)
+
+// Operations of the Safe tx.
+const (
+	Call         = 0
+	DelegateCall = 1
+)
+
+// EIP-712 type hashes of Safe.
+var (
+	DomainTypeHash = keccak.Sum256([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	TxTypeHash     = keccak.Sum256([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation," +
+		"uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+type (
+	// Tx is the transaction of the Safe. The gas refund fields are zero if the executor pays the fee.
+	Tx struct {
+		ChainID        *big.Int `json:"chainId"`
+		Safe           []byte   `json:"safe"`
+		To             []byte   `json:"to"`
+		Value          *big.Int `json:"value"`
+		Data           []byte   `json:"data"`
+		Operation      uint8    `json:"operation"`
+		SafeTxGas      *big.Int `json:"safeTxGas"`
+		BaseGas        *big.Int `json:"baseGas"`
+		GasPrice       *big.Int `json:"gasPrice"`
+		GasToken       []byte   `json:"gasToken"`
+		RefundReceiver []byte   `json:"refundReceiver"`
+		Nonce          *big.Int `json:"nonce"`
+	}
+
+	// Payload is the unsigned tx of the Safe wallet: the Safe tx signed by the owners and the unsigned tx
+	// of the executor account (without the data) which sends execTransaction to the Safe.
+	// Owners are the owner addresses of the Safe checked by TxBuild.
+	Payload struct {
+		Tx        *Tx      `json:"tx"`
+		Threshold uint8    `json:"threshold"`
+		Owners    [][]byte `json:"owners"`
+		Exec      []byte   `json:"exec"`
+	}
+)
+
+// DomainSeparator returns EIP-712 domain separator of the Safe.
+func (tx *Tx) DomainSeparator() []byte {
+	return keccak.Sum256(DomainTypeHash, abi.UintWord(tx.ChainID), abi.AddressWord(tx.Safe))
+}
+
+// Hash returns EIP-712 hash of the tx signed by the owners.
+func (tx *Tx) Hash() []byte {
+	structHash := keccak.Sum256(
+		TxTypeHash,
+		abi.AddressWord(tx.To),
+		abi.UintWord(tx.Value),
+		keccak.Sum256(tx.Data),
+		abi.UintWord(big.NewInt(int64(tx.Operation))),
+		abi.UintWord(tx.SafeTxGas),
+		abi.UintWord(tx.BaseGas),
+		abi.UintWord(tx.GasPrice),
+		abi.AddressWord(tx.GasToken),
+		abi.AddressWord(tx.RefundReceiver),
+		abi.UintWord(tx.Nonce),
+	)
+	return keccak.Sum256([]byte{0x19, 0x01}, tx.DomainSeparator(), structHash)
+}
+
+// Check returns error unless the tx is the plain call of the Safe on the chain paid by the executor.
+// The owners shall not sign the delegatecall, which runs the code of the callee in the Safe, and
+// the gas refund, which pays the submitter from the Safe; safeTxGas lets the call fail without revert.
+func (tx *Tx) Check() error {
+	if tx.ChainID == nil || tx.ChainID.Sign() <= 0 {
+		return fmt.Errorf("invalid chain id of the Safe tx")
+	}
+	if len(tx.Safe) != abi.AddressSize || isZero(tx.Safe) {
+		return fmt.Errorf("invalid Safe address")
+	}
+	if len(tx.To) != abi.AddressSize || bytes.Equal(tx.To, tx.Safe) {
+		return fmt.Errorf("invalid recipient of the Safe tx")
+	}
+	if tx.Operation != Call {
+		return fmt.Errorf("unsupported operation %d of the Safe tx", tx.Operation)
+	}
+	for _, n := range []*big.Int{tx.SafeTxGas, tx.BaseGas, tx.GasPrice} {
+		if n != nil && n.Sign() != 0 {
+			return fmt.Errorf("gas refund of the Safe tx is not supported")
+		}
+	}
+	if !isZero(tx.GasToken) || !isZero(tx.RefundReceiver) {
+		return fmt.Errorf("gas refund of the Safe tx is not supported")
+	}
+	if (tx.Value != nil && tx.Value.Sign() < 0) || (tx.Nonce != nil && tx.Nonce.Sign() < 0) {
+		return fmt.Errorf("invalid Safe tx")
+	}
+	return nil
+}
+
+// isZero returns true for the empty or zero address.
+func isZero(addr []byte) bool {
+	for _, b := range addr {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsOwner returns true if the address is one of the owners of the payload.
+func (p *Payload) IsOwner(addr []byte) bool {
+	for _, owner := range p.Owners {
+		if bytes.Equal(owner, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode returns the hex encoded payload.
+func (p *Payload) Encode() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// IsPayload returns true if the hex encoded tx is Safe payload, not the tx of the account.
+func IsPayload(txHex string) bool {
+	// JSON object
+	return len(txHex) >= 2 && txHex[:2] == "7b"
+}
+
+// DecodePayload decodes the hex encoded payload.
+func DecodePayload(txHex string) (*Payload, error) {
+	data, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	var p Payload
+	if err = json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid Safe payload: %s", err.Error())
+	}
+	if p.Tx == nil || len(p.Tx.Safe) != abi.AddressSize || len(p.Tx.To) != abi.AddressSize || p.Threshold == 0 {
+		return nil, fmt.Errorf("invalid Safe payload")
+	}
+	if len(p.Owners) < int(p.Threshold) {
+		return nil, fmt.Errorf("invalid Safe payload: %d owners of threshold %d", len(p.Owners), p.Threshold)
+	}
+	for _, owner := range p.Owners {
+		if len(owner) != abi.AddressSize {
+			return nil, fmt.Errorf("invalid Safe payload owner")
+		}
+	}
+	return &p, nil
+}
@@ -0,0 +1,103 @@
+package safetx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTx_Hash(t *testing.T) {
+	assert.Equal(t, "47e79534a245952e8b16893a336b85a3d9ea9fa8c573f3d803afb92a79469218", hex.EncodeToString(DomainTypeHash),
+		"unexpected domain type hash")
+	assert.Equal(t, "bb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8", hex.EncodeToString(TxTypeHash),
+		"unexpected tx type hash")
+
+	tx := &Tx{
+		ChainID: big.NewInt(1),
+		Safe:    bytes.Repeat([]byte{0x5a}, 20),
+		To:      bytes.Repeat([]byte{0x35}, 20),
+		Value:   big.NewInt(1000),
+		Nonce:   big.NewInt(3),
+	}
+	hash := tx.Hash()
+	other := *tx
+	other.ChainID = big.NewInt(5)
+	assert.NotEqual(t, hash, other.Hash(), "the hash shall depend on the chain")
+	other = *tx
+	other.Nonce = big.NewInt(4)
+	assert.NotEqual(t, hash, other.Hash(), "the hash shall depend on the nonce")
+}
+
+func TestPayload(t *testing.T) {
+	p := Payload{
+		Tx: &Tx{
+			ChainID: big.NewInt(1),
+			Safe:    bytes.Repeat([]byte{0x5a}, 20),
+			To:      bytes.Repeat([]byte{0x35}, 20),
+			Value:   big.NewInt(1000),
+			Nonce:   big.NewInt(3),
+		},
+		Threshold: 2,
+		Owners:    [][]byte{bytes.Repeat([]byte{0x01}, 20), bytes.Repeat([]byte{0x02}, 20)},
+		Exec:      []byte{0x02, 0xc0},
+	}
+	txHex, err := p.Encode()
+	assert.Nil(t, err, "unexpected error")
+	assert.True(t, IsPayload(txHex), "Safe payload expected")
+	assert.False(t, IsPayload("02c0"), "tx payload expected")
+
+	decoded, err := DecodePayload(txHex)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, &p, decoded, "unexpected payload")
+	assert.Equal(t, p.Tx.Hash(), decoded.Tx.Hash(), "unexpected hash")
+
+	assert.True(t, decoded.IsOwner(bytes.Repeat([]byte{0x02}, 20)), "owner expected")
+	assert.False(t, decoded.IsOwner(bytes.Repeat([]byte{0x03}, 20)), "unexpected owner")
+
+	_, err = DecodePayload(hex.EncodeToString([]byte(`{"threshold":2}`)))
+	assert.NotNil(t, err, "expected error for the payload without tx")
+	p.Owners = p.Owners[:1]
+	txHex, _ = p.Encode()
+	_, err = DecodePayload(txHex)
+	assert.NotNil(t, err, "expected error for the owners less than the threshold")
+}
+
+func TestTx_Check(t *testing.T) {
+	valid := func() *Tx {
+		return &Tx{
+			ChainID: big.NewInt(1),
+			Safe:    bytes.Repeat([]byte{0x5a}, 20),
+			To:      bytes.Repeat([]byte{0x35}, 20),
+			Value:   big.NewInt(1000),
+			Nonce:   big.NewInt(3),
+		}
+	}
+	assert.Nil(t, valid().Check(), "unexpected error")
+
+	for name, modify := range map[string]func(tx *Tx){
+		"delegatecall":     func(tx *Tx) { tx.Operation = DelegateCall },
+		"gas price":        func(tx *Tx) { tx.GasPrice = big.NewInt(1) },
+		"base gas":         func(tx *Tx) { tx.BaseGas = big.NewInt(21000) },
+		"safe tx gas":      func(tx *Tx) { tx.SafeTxGas = big.NewInt(21000) },
+		"gas token":        func(tx *Tx) { tx.GasToken = bytes.Repeat([]byte{0x77}, 20) },
+		"refund receiver":  func(tx *Tx) { tx.RefundReceiver = bytes.Repeat([]byte{0x77}, 20) },
+		"no chain id":      func(tx *Tx) { tx.ChainID = nil },
+		"zero chain id":    func(tx *Tx) { tx.ChainID = big.NewInt(0) },
+		"zero Safe":        func(tx *Tx) { tx.Safe = make([]byte, 20) },
+		"short Safe":       func(tx *Tx) { tx.Safe = tx.Safe[:19] },
+		"call of the Safe": func(tx *Tx) { tx.To = tx.Safe },
+		"negative value":   func(tx *Tx) { tx.Value = big.NewInt(-1) },
+	} {
+		tx := valid()
+		modify(tx)
+		assert.NotNil(t, tx.Check(), "expected error for %s", name)
+	}
+
+	tx := valid()
+	tx.GasToken = make([]byte, 20)
+	tx.GasPrice = big.NewInt(0)
+	assert.Nil(t, tx.Check(), "zero refund fields shall be accepted")
+}
@@ -9,35 +9,58 @@ import (
 	"math/big"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stanche/crypto-interface/signer/bip39"
+	"github.com/stanche/crypto-interface/signer/schnorr"
 	"github.com/stanche/crypto-interface/signer/script"
 )
 
 var masterKey = []byte("Bitcoin seed")
 
 // Signer256k1 implements a key provider on sec256k1 curve.
+// The master key and the parents of the derived keys are cached, call Zero to wipe them.
 type Signer256k1 struct {
 	keyData   []byte
 	ethKostil bool
+	keys      *keyCache
 }
 
 // New creates a BTC-like key provider.
 func New(secret []byte) Signer256k1 {
-	return Signer256k1{keyData: secret}
+	return Signer256k1{keyData: secret, keys: newKeyCache()}
+}
+
+// NewFromMnemonic creates a BTC-like key provider from BIP39 mnemonic and an optional passphrase.
+func NewFromMnemonic(mnemonic, passphrase string) (Signer256k1, error) {
+	seed, err := bip39.NewSeedWithValidation(mnemonic, passphrase)
+	if err != nil {
+		return Signer256k1{}, err
+	}
+	return New(seed), nil
 }
 
 // NewLegacyETH creates a key provider with a support for old ETH address generation.
 func NewLegacyETH(secret []byte) Signer256k1 {
-	return Signer256k1{keyData: secret, ethKostil: true}
+	return Signer256k1{keyData: secret, ethKostil: true, keys: newKeyCache()}
 }
 
-func (s Signer256k1) getKey(path []uint32) (*btcec.PrivateKey, error) {
-	if s.ethKostil && len(path) == 2 && path[0] == 0 && path[1] == 0 {
-		key, err := ecdsa.GenerateKey(btcec.S256(), bytes.NewReader(s.keyData))
-		if err != nil {
-			return nil, err
-		}
-		return (*btcec.PrivateKey)(key), nil
+// Zero wipes the cached keys. The signer stays usable and derives the keys again on demand.
+func (s Signer256k1) Zero() {
+	if s.keys != nil {
+		s.keys.zero()
+	}
+}
+
+// SignsConcurrently implements ConcurrentKeyProvider.
+func (s Signer256k1) SignsConcurrently() bool {
+	return true
+}
+
+// deriveKey returns the extended key on path, the caller shall zero it.
+func (s Signer256k1) deriveKey(path []uint32) (*hdkeychain.ExtendedKey, error) {
+	if s.keys != nil && len(path) > 0 {
+		return s.keys.child(s.keyData, path)
 	}
 	// build the master key
 	xkey, err := hdkeychain.NewMaster(s.keyData, &BtcNetParams)
@@ -48,10 +71,24 @@ func (s Signer256k1) getKey(path []uint32) (*btcec.PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(path) == 0 {
+		return xkey, nil
+	}
 	// clear the key
 	defer xkey.Zero()
 
-	xkeyIndex, err := script.ChildFromXkeyPath(xkey, path)
+	return script.ChildFromXkeyPath(xkey, path)
+}
+
+func (s Signer256k1) getKey(path []uint32) (*btcec.PrivateKey, error) {
+	if s.ethKostil && len(path) == 2 && path[0] == 0 && path[1] == 0 {
+		key, err := ecdsa.GenerateKey(btcec.S256(), bytes.NewReader(s.keyData))
+		if err != nil {
+			return nil, err
+		}
+		return (*btcec.PrivateKey)(key), nil
+	}
+	xkeyIndex, err := s.deriveKey(path)
 	if err == nil && xkeyIndex == nil {
 		err = fmt.Errorf("child xkey is nil")
 	}
@@ -96,6 +133,15 @@ func (s Signer256k1) SignDerived(hash []byte, path []uint32) ([]byte, error) {
 	return signature.Serialize(), nil
 }
 
+// SignSchnorrDerived signs the hash with BCH Schnorr scheme using the key on path in HD tree.
+func (s Signer256k1) SignSchnorrDerived(hash []byte, path []uint32) ([]byte, error) {
+	prvKey, err := s.getKey(path)
+	if err != nil {
+		return nil, err
+	}
+	return schnorr.Sign(prvKey, hash)
+}
+
 // DerivedPubkey returns a ecdsa.PublicKey that relates to path on HD tree. 0,0] path is considered a special case for ethKostil.
 func (s Signer256k1) DerivedPubkey(path []uint32) (*ecdsa.PublicKey, error) {
 	prvKey, err := s.getKey(path)
@@ -120,19 +166,25 @@ func (s Signer256k1) GetPublicKey() (*ecdsa.PublicKey, error) {
 		return &key.PublicKey, nil
 	}
 
-	// build the master key
-	xkey, err := hdkeychain.NewMaster(s.keyData, &BtcNetParams)
-	if err != nil {
-		return nil, err
-	}
-	if xkey == nil {
-		return nil, fmt.Errorf("xkey is nil")
-	}
-
-	// clear the key
-	defer xkey.Zero()
+	var xpubExp *hdkeychain.ExtendedKey
+	var err error
+	if s.keys != nil {
+		xpubExp, err = s.keys.neuteredMaster(s.keyData)
+	} else {
+		// build the master key
+		var xkey *hdkeychain.ExtendedKey
+		xkey, err = hdkeychain.NewMaster(s.keyData, &BtcNetParams)
+		if err == nil && xkey == nil {
+			err = fmt.Errorf("xkey is nil")
+		}
+		if err != nil {
+			return nil, err
+		}
+		// clear the key
+		defer xkey.Zero()
 
-	xpubExp, err := xkey.Neuter()
+		xpubExp, err = xkey.Neuter()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +201,31 @@ func (s Signer256k1) GetPublicKey() (*ecdsa.PublicKey, error) {
 	return pk.ToECDSA(), err
 }
 
+// ExtendedPublicKey returns the extended public key on path in HD tree, i.e. an account xpub
+// for BIP44/45/48 layouts. Hardened steps are allowed.
+func (s Signer256k1) ExtendedPublicKey(path []uint32, net *chaincfg.Params) (string, error) {
+	xkey, err := hdkeychain.NewMaster(s.keyData, net)
+	if err == nil && xkey == nil {
+		err = fmt.Errorf("xkey is nil")
+	}
+	if err != nil {
+		return "", err
+	}
+	defer xkey.Zero()
+
+	xkeyPath, err := script.ChildFromXkeyPath(xkey, path)
+	if err != nil {
+		return "", err
+	}
+	defer xkeyPath.Zero()
+
+	xpub, err := xkeyPath.Neuter()
+	if err != nil {
+		return "", err
+	}
+	return xpub.String(), nil
+}
+
 // GetChainCode returns a deterministic chain code.
 func (s Signer256k1) GetChainCode() ([]byte, error) {
 	// First take the HMAC-SHA512 of the master key and the seed data:
@@ -5,21 +5,76 @@ import (
 
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/stanche/crypto-interface/netparams"
 )
 
 const (
 	sigHashForkID = 0x40
 )
 
+// Input signature functions of the BCH forks.
+var (
+	XecTxInputSignature        = ForkTxInputSignature(netparams.XecMainNetFork)
+	XecSchnorrTxInputSignature = ForkSchnorrTxInputSignature(netparams.XecMainNetFork)
+	BsvTxInputSignature        = ForkTxInputSignature(netparams.BsvMainNetFork)
+)
+
 // BchTxInputSignature defines input signature function for BTC
 func BchTxInputSignature(tx *wire.MsgTx, idx int, subScript []byte,
 	xpath []uint32, amount uint64, keyProvider KeyProvider) ([]byte, error) {
+	return forkTxInputSignature(tx, idx, subScript, xpath, amount, keyProvider, netparams.BchMainNetFork.ForkID, false)
+}
+
+// BchSchnorrTxInputSignature defines input signature function for BCH producing Schnorr signatures.
+// The key provider shall implement SchnorrKeyProvider.
+func BchSchnorrTxInputSignature(tx *wire.MsgTx, idx int, subScript []byte,
+	xpath []uint32, amount uint64, keyProvider KeyProvider) ([]byte, error) {
+	return forkTxInputSignature(tx, idx, subScript, xpath, amount, keyProvider, netparams.BchMainNetFork.ForkID, true)
+}
+
+// ForkTxInputSignature returns ECDSA input signature function with SIGHASH_FORKID of the fork.
+func ForkTxInputSignature(fork netparams.ForkParams) RawTxInputSignature {
+	return func(tx *wire.MsgTx, idx int, subScript []byte,
+		xpath []uint32, amount uint64, keyProvider KeyProvider) ([]byte, error) {
+		return forkTxInputSignature(tx, idx, subScript, xpath, amount, keyProvider, fork.ForkID, false)
+	}
+}
+
+// ForkSchnorrTxInputSignature returns Schnorr input signature function with SIGHASH_FORKID of the fork.
+// The function fails if the fork does not accept Schnorr signatures.
+func ForkSchnorrTxInputSignature(fork netparams.ForkParams) RawTxInputSignature {
+	return func(tx *wire.MsgTx, idx int, subScript []byte,
+		xpath []uint32, amount uint64, keyProvider KeyProvider) ([]byte, error) {
+		if !fork.Schnorr {
+			return nil, fmt.Errorf("%s does not accept Schnorr signatures", fork.Code)
+		}
+		return forkTxInputSignature(tx, idx, subScript, xpath, amount, keyProvider, fork.ForkID, true)
+	}
+}
+
+func forkTxInputSignature(tx *wire.MsgTx, idx int, subScript []byte,
+	xpath []uint32, amount uint64, keyProvider KeyProvider, forkID uint32, schnorr bool) ([]byte, error) {
+	var schnorrProvider SchnorrKeyProvider
+	if schnorr {
+		var ok bool
+		schnorrProvider, ok = keyProvider.(SchnorrKeyProvider)
+		if !ok {
+			return nil, fmt.Errorf("key provider does not support Schnorr signatures")
+		}
+	}
 	hashType := txscript.SigHashAll
-	hash, err := bip143SignatureHash(subScript, txscript.NewTxSigHashes(tx), hashType, tx, idx, amount, sigHashForkID)
+	hash, err := bip143SignatureHash(subScript, txscript.NewTxSigHashes(tx), hashType, tx, idx, amount,
+		forkID<<8|sigHashForkID)
 	if err != nil {
 		return nil, err
 	}
-	sig, err := keyProvider.SignDerived(hash, xpath)
+	var sig []byte
+	if schnorr {
+		sig, err = schnorrProvider.SignSchnorrDerived(hash, xpath)
+	} else {
+		sig, err = keyProvider.SignDerived(hash, xpath)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("cannot sign tx input: %s", err)
 	}
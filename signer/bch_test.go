@@ -8,6 +8,8 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/stanche/crypto-interface/netparams"
 )
 
 func TestBchSigner_Sign(t *testing.T) {
@@ -172,6 +174,47 @@ func TestBchSigner_Sign(t *testing.T) {
 				assert.Equal(t, signExpected, sign, "unexpected signature")
 			},
 		},
+		{
+			"Positive Schnorr signing tx with one input (signer-a)",
+			func(t *testing.T) {
+				kp := New(component1)
+				signer := NewBtcSigner("BCH", kp, &BtcNetParams, BchSchnorrTxInputSignature)
+				txData := []byte("0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000")
+				signParams := []uint64{
+					110000000, // 1.1 BCH
+				}
+
+				// 64-byte signature with SIGHASH_ALL|SIGHASH_FORKID
+				txSign, _ := hex.DecodeString("42cc995ad72865a45439e69db0aa72e24178be2960f936c22f8a2b3e0f95573bd442127baa962de059919fbc2c14a6c1c199b98170bc501068e60162764017ba41")
+				signStruct := btcSignature{
+					Ind: 0,
+					Val: txSign,
+				}
+				byteSignature, _ := json.Marshal(signStruct)
+				signature := base64.StdEncoding.EncodeToString(byteSignature)
+
+				signExpected := []string{
+					signature,
+				}
+				sign, err := signer.Sign(txData, signParams)
+				//Then
+				assert.Equal(t, nil, err, "unexpected error")
+				assert.Equal(t, signExpected, sign, "unexpected signature")
+			},
+		},
+		{
+			"Negative Schnorr signing with a key provider without Schnorr support",
+			func(t *testing.T) {
+				kp := struct{ KeyProvider }{New(component1)}
+				signer := NewBtcSigner("BCH", kp, &BtcNetParams, BchSchnorrTxInputSignature)
+				txData := []byte("0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000")
+
+				//When
+				_, err := signer.Sign(txData, []uint64{110000000})
+				//Then
+				assert.NotNil(t, err, "expected error")
+			},
+		},
 		{
 			"Positive signing tx with one input (signer-c)",
 			func(t *testing.T) {
@@ -206,3 +249,41 @@ func TestBchSigner_Sign(t *testing.T) {
 		})
 	}
 }
+
+func TestForkTxInputSignature(t *testing.T) {
+	component1, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	kp := New(component1)
+	txData := []byte("0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000")
+	signParams := []uint64{110000000}
+
+	sign := func(inSign RawTxInputSignature) ([]string, error) {
+		return NewBtcSigner("BCH", kp, &BtcNetParams, inSign).Sign(txData, signParams)
+	}
+	bchSign, err := sign(BchTxInputSignature)
+	assert.Nil(t, err, "unexpected error")
+	bchSchnorrSign, err := sign(BchSchnorrTxInputSignature)
+	assert.Nil(t, err, "unexpected error")
+
+	// eCash and BSV keep the fork id of BCH
+	xecSign, err := sign(XecTxInputSignature)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, bchSign, xecSign, "unexpected XEC signature")
+
+	xecSchnorrSign, err := sign(XecSchnorrTxInputSignature)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, bchSchnorrSign, xecSchnorrSign, "unexpected XEC signature")
+
+	bsvSign, err := sign(BsvTxInputSignature)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, bchSign, bsvSign, "unexpected BSV signature")
+
+	_, err = sign(ForkSchnorrTxInputSignature(netparams.BsvMainNetFork))
+	assert.NotNil(t, err, "BSV does not accept Schnorr signatures")
+
+	// another fork id changes the digest
+	fork := netparams.BchMainNetFork
+	fork.ForkID = 79
+	forkSign, err := sign(ForkTxInputSignature(fork))
+	assert.Nil(t, err, "unexpected error")
+	assert.NotEqual(t, bchSign, forkSign, "the signature shall depend on the fork id")
+}
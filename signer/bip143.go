@@ -25,8 +25,9 @@ const (
 // being spent, in addition to the final transaction fee. In the case the
 // wallet if fed an invalid input amount, the real sighash will differ causing
 // the produced signature to be invalid.
+// forkHashType is combined with the hash type, it is zero for BTC.
 func bip143SignatureHash(subScript []byte, sigHashes *txscript.TxSigHashes,
-	hashType txscript.SigHashType, tx *wire.MsgTx, index int, amount uint64, forkID byte) ([]byte, error) {
+	hashType txscript.SigHashType, tx *wire.MsgTx, index int, amount uint64, forkHashType uint32) ([]byte, error) {
 
 	// As a sanity check, ensure the passed input index for the transaction
 	// is valid.
@@ -110,7 +111,7 @@ func bip143SignatureHash(subScript []byte, sigHashes *txscript.TxSigHashes,
 	binary.LittleEndian.PutUint32(bLockTime[:], tx.LockTime)
 	sigHash.Write(bLockTime[:])
 	var bHashType [4]byte
-	binary.LittleEndian.PutUint32(bHashType[:], uint32(hashType|txscript.SigHashType(forkID)))
+	binary.LittleEndian.PutUint32(bHashType[:], uint32(hashType)|forkHashType)
 	sigHash.Write(bHashType[:])
 
 	return chainhash.DoubleHashB(sigHash.Bytes()), nil
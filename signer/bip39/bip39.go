@@ -0,0 +1,158 @@
+package bip39
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Wordlist is a BIP39 list of 2048 words with the separator used in mnemonic sentences.
+type Wordlist struct {
+	Name      string
+	Words     []string
+	Separator string
+	// normalized are the NFKD forms of the words the mnemonics are decoded with
+	normalized []string
+	index      map[string]int
+}
+
+var (
+	English            = newWordlist("english", wordlists.English, " ")
+	Japanese           = newWordlist("japanese", wordlists.Japanese, "\u3000")
+	Korean             = newWordlist("korean", wordlists.Korean, " ")
+	Spanish            = newWordlist("spanish", wordlists.Spanish, " ")
+	ChineseSimplified  = newWordlist("chinese_simplified", wordlists.ChineseSimplified, " ")
+	ChineseTraditional = newWordlist("chinese_traditional", wordlists.ChineseTraditional, " ")
+	French             = newWordlist("french", wordlists.French, " ")
+	Italian            = newWordlist("italian", wordlists.Italian, " ")
+	Czech              = newWordlist("czech", wordlists.Czech, " ")
+
+	// Wordlists are the supported wordlists in the order of detection.
+	Wordlists = []*Wordlist{English, Japanese, Korean, Spanish, ChineseSimplified, ChineseTraditional, French, Italian, Czech}
+
+	// ErrInvalidEntropy is returned for entropy which is not 128-256 bits long with 32 bits step.
+	ErrInvalidEntropy = bip39.ErrEntropyLengthInvalid
+	// ErrInvalidChecksum is returned when the mnemonic checksum does not match.
+	ErrInvalidChecksum = bip39.ErrChecksumIncorrect
+	// ErrUnknownWordlist is returned when the mnemonic words do not belong to a single wordlist.
+	ErrUnknownWordlist = fmt.Errorf("mnemonic words do not match any wordlist")
+
+	// wordlistMu guards the package-wide wordlist of go-bip39
+	wordlistMu sync.Mutex
+)
+
+func newWordlist(name string, words []string, separator string) *Wordlist {
+	normalized := make([]string, len(words))
+	index := make(map[string]int, len(words))
+	for i, w := range words {
+		normalized[i] = norm.NFKD.String(w)
+		index[normalized[i]] = i
+	}
+	return &Wordlist{Name: name, Words: words, Separator: separator, normalized: normalized, index: index}
+}
+
+// NewEntropy returns random entropy of bitSize bits for a new mnemonic.
+func NewEntropy(bitSize int) ([]byte, error) {
+	return bip39.NewEntropy(bitSize)
+}
+
+// NewMnemonic encodes the entropy as a mnemonic sentence with the words from wordlist.
+func NewMnemonic(entropy []byte, wordlist *Wordlist) (string, error) {
+	if wordlist == nil || len(wordlist.Words) != len(wordlists.English) {
+		return "", fmt.Errorf("invalid wordlist")
+	}
+	var mnemonic string
+	err := withWordlist(wordlist.Words, func() (err error) {
+		mnemonic, err = bip39.NewMnemonic(entropy)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(mnemonic, " ", wordlist.Separator, -1), nil
+}
+
+// EntropyFromMnemonic decodes the mnemonic and checks its checksum. The wordlist is detected from the words.
+func EntropyFromMnemonic(mnemonic string) ([]byte, *Wordlist, error) {
+	var entropy []byte
+	wordlist, err := detectWordlist(mnemonic, func(words string) (err error) {
+		entropy, err = bip39.EntropyFromMnemonic(words)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entropy, wordlist, nil
+}
+
+// Validate checks the words and the checksum of the mnemonic.
+func Validate(mnemonic string) error {
+	_, _, err := EntropyFromMnemonic(mnemonic)
+	return err
+}
+
+// NewSeed derives the 64-byte seed from the mnemonic and an optional passphrase with PBKDF2.
+// The mnemonic is not validated, use NewSeedWithValidation to check it.
+func NewSeed(mnemonic, passphrase string) []byte {
+	return bip39.NewSeed(norm.NFKD.String(mnemonic), norm.NFKD.String(passphrase))
+}
+
+// NewSeedWithValidation validates the mnemonic and derives the seed.
+func NewSeedWithValidation(mnemonic, passphrase string) ([]byte, error) {
+	var seed []byte
+	_, err := detectWordlist(mnemonic, func(words string) (err error) {
+		seed, err = bip39.NewSeedWithErrorChecking(words, norm.NFKD.String(passphrase))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// detectWordlist normalizes the mnemonic and calls decode with it for every wordlist
+// containing all the words, until decode succeeds: some words are shared between the wordlists,
+// so the checksum decides.
+func detectWordlist(mnemonic string, decode func(words string) error) (*Wordlist, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	count := len(words)
+	if count < 12 || count > 24 || count%3 != 0 {
+		return nil, fmt.Errorf("invalid number of mnemonic words: %d", count)
+	}
+	normalized := strings.Join(words, " ")
+	err := ErrUnknownWordlist
+	for _, wordlist := range Wordlists {
+		if !wordlist.contains(words) {
+			continue
+		}
+		err = withWordlist(wordlist.normalized, func() error {
+			return decode(normalized)
+		})
+		if err == nil {
+			return wordlist, nil
+		}
+	}
+	return nil, err
+}
+
+// withWordlist calls f with words set as the wordlist of go-bip39.
+func withWordlist(words []string, f func() error) error {
+	wordlistMu.Lock()
+	defer wordlistMu.Unlock()
+	bip39.SetWordList(words)
+	defer bip39.SetWordList(wordlists.English)
+	return f()
+}
+
+func (wl *Wordlist) contains(words []string) bool {
+	for _, w := range words {
+		if _, ok := wl.index[w]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package bip39
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMnemonic(t *testing.T) {
+	// test vectors from https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+	tests := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+		xprv     string
+	}{
+		{
+			entropy:  "00000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+			xprv:     "xprv9s21ZrQH143K3h3fDYiay8mocZ3afhfULfb5GX8kCBdno77K4HiA15Tg23wpbeF1pLfs1c5SPmYHrEpTuuRhxMwvKDwqdKiGJS9XFKzUsAF",
+		},
+		{
+			entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+			seed:     "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+			xprv:     "xprv9s21ZrQH143K2gA81bYFHqU68xz1cX2APaSq5tt6MFSLeXnCKV1RVUJt9FWNTbrrryem4ZckN8k4Ls1H6nwdvDTvnV7zEXs2HgPezuVccsq",
+		},
+		{
+			entropy:  "8080808080808080808080808080808080808080808080808080808080808080",
+			mnemonic: "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic bless",
+			seed:     "c0c519bd0e91a2ed54357d9d1ebef6f5af218a153624cf4f2da911a0ed8f7a09e2ef61af0aca007096df430022f7a2b6fb91661a9589097069720d015e4e982f",
+			xprv:     "xprv9s21ZrQH143K3CSnQNYC3MqAAqHwxeTLhDbhF43A4ss4ciWNmCY9zQGvAKUSqVUf2vPHBTSE1rB2pg4avopqSiLVzXEU8KziNnVPauTqLRo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mnemonic, func(t *testing.T) {
+			entropy, _ := hex.DecodeString(tt.entropy)
+
+			mnemonic, err := NewMnemonic(entropy, English)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.mnemonic, mnemonic, "unexpected mnemonic")
+
+			decoded, wordlist, err := EntropyFromMnemonic(mnemonic)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, entropy, decoded, "unexpected entropy")
+			assert.Equal(t, English, wordlist, "unexpected wordlist")
+
+			seed, err := NewSeedWithValidation(mnemonic, "TREZOR")
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.seed, hex.EncodeToString(seed), "unexpected seed")
+
+			xkey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+			assert.Nil(t, err, "unexpected error")
+			assert.Equal(t, tt.xprv, xkey.String(), "unexpected master key")
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.Equal(t, ErrInvalidChecksum,
+		Validate("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"))
+	assert.Equal(t, ErrUnknownWordlist,
+		Validate("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon bitcoins"))
+	assert.NotNil(t, Validate("abandon abandon about"))
+
+	entropy, err := NewEntropy(256)
+	assert.Nil(t, err, "unexpected error")
+	mnemonic, err := NewMnemonic(entropy, Japanese)
+	assert.Nil(t, err, "unexpected error")
+	_, wordlist, err := EntropyFromMnemonic(mnemonic)
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, Japanese, wordlist, "unexpected wordlist")
+	seed, err := NewSeedWithValidation(mnemonic, "")
+	assert.Nil(t, err, "unexpected error")
+	assert.Equal(t, NewSeed(mnemonic, ""), seed, "unexpected seed")
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"github.com/apex/log"
+	"github.com/stanche/crypto-interface/netparams"
 	"github.com/stanche/crypto-interface/signer/script"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -19,6 +23,13 @@ import (
 
 var BtcNetParams = chaincfg.MainNetParams // TestNet3Params
 
+// Network params of the BTC-like currencies signed with BtcTxInputSignature.
+var (
+	LtcNetParams  = netparams.LtcMainNetParams  // LtcTestNet4Params
+	DogeNetParams = netparams.DogeMainNetParams // DogeTestNetParams
+	DashNetParams = netparams.DashMainNetParams // DashTestNetParams
+)
+
 type (
 
 	// RawTxInputSignature defines signature function for BTC-class currencies
@@ -31,6 +42,7 @@ type (
 		net            *chaincfg.Params
 		keyProvider    KeyProvider
 		inputSignature RawTxInputSignature
+		policy         *PolicyEngine
 	}
 
 	btcSignature struct {
@@ -75,6 +87,12 @@ func BtcTxInputSignature(tx *wire.MsgTx, idx int, subScript []byte,
 	return append(sig, byte(hashType)), nil
 }
 
+// PolicySet sets the policies evaluated before signing, nil disables the checks.
+// signParams of Sign shall contain the input amounts for the fee policies.
+func (signer *BtcSigner) PolicySet(policy *PolicyEngine) {
+	signer.policy = policy
+}
+
 // CurrencyType implements Signer interface
 func (signer *BtcSigner) CurrencyType() string {
 	return signer.currency
@@ -105,6 +123,20 @@ func (signer *BtcSigner) Sign(txHex []byte, signParams []uint64) ([]string, erro
 		return nil, err
 	}
 
+	var commit *PolicyCommit
+	if signer.policy != nil {
+		net := signer.net
+		if net == nil {
+			net = &BtcNetParams
+		}
+		_, commit, err = signer.policy.Evaluate(txHex, net, xpubExp, signParams)
+		if err != nil {
+			return nil, err
+		}
+		// the stateful policies count the transaction only if it is signed
+		defer commit.Rollback()
+	}
+
 	// extract TxIn count
 	countTxIn := len(tx.MsgTx().TxIn)
 	signatures := make([]string, countTxIn)
@@ -115,18 +147,13 @@ func (signer *BtcSigner) Sign(txHex []byte, signParams []uint64) ([]string, erro
 		err = fmt.Errorf("inconsistnence signParams (%d) and countTxIn(%d)", len(signParams), countTxIn)
 		return nil, err
 	}
-	// loop for TxIn's
-	for indexTxIn := 0; indexTxIn < countTxIn; indexTxIn++ {
-
-		// initialize dummy value
-		//indexes[indexTxIn] = -1
-
+	signInput := func(indexTxIn int) (string, error) {
 		redeemScript, err := script.RedeemScriptFromTxin(tx.MsgTx().TxIn[indexTxIn])
 		if err == nil && redeemScript == nil {
 			err = fmt.Errorf("redeemScript is nil")
 		}
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		m, pubkeys, index, xpath, err := script.PubkeysIndexPathFromScript(redeemScript, xpubExp)
@@ -134,15 +161,14 @@ func (signer *BtcSigner) Sign(txHex []byte, signParams []uint64) ([]string, erro
 			err = fmt.Errorf("pubkeys is nil")
 		}
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		// check the input belong to the key
 		if index < 0 {
-			continue
+			return "", nil
 		}
 		if xpath == nil {
-			err = fmt.Errorf("xpath is nil")
-			return nil, err
+			return "", fmt.Errorf("xpath is nil")
 		}
 
 		msScript, index, err := script.MultisigScriptFromPubkeys(m, pubkeys, index)
@@ -150,7 +176,7 @@ func (signer *BtcSigner) Sign(txHex []byte, signParams []uint64) ([]string, erro
 			err = fmt.Errorf("multisig script is nil")
 		}
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		var amount uint64
@@ -162,7 +188,7 @@ func (signer *BtcSigner) Sign(txHex []byte, signParams []uint64) ([]string, erro
 			err = fmt.Errorf("sign is nil")
 		}
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		log.Debugf("sign[%d]: %s\n", indexTxIn, hex.EncodeToString(sign))
 
@@ -172,19 +198,64 @@ func (signer *BtcSigner) Sign(txHex []byte, signParams []uint64) ([]string, erro
 		}
 
 		byteSignature, err := json.Marshal(btcSignature)
+		if err != nil {
+			return "", err
+		}
+		signature := base64.StdEncoding.EncodeToString(byteSignature)
+		log.Debugf("sign[%d]: %s", indexTxIn, signature)
+		return signature, nil
+	}
+
+	// the sighash calculation only reads the tx, so the inputs may be signed in parallel
+	workers := 1
+	if kp, ok := signer.keyProvider.(ConcurrentKeyProvider); ok && kp.SignsConcurrently() {
+		workers = runtime.NumCPU()
+	}
+	if workers > countTxIn {
+		workers = countTxIn
+	}
+	errs := make([]error, countTxIn)
+	var next int32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				indexTxIn := int(atomic.AddInt32(&next, 1) - 1)
+				if indexTxIn >= countTxIn {
+					return
+				}
+				signatures[indexTxIn], errs[indexTxIn] = signInput(indexTxIn)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
-		signatures[indexTxIn] = base64.StdEncoding.EncodeToString(byteSignature)
-		log.Debugf("sign[%d]: %s", indexTxIn, signatures[indexTxIn])
 	}
+	commit.Commit()
 	return signatures, nil
 }
 
 // Public returns Extended Public Key as string
 func (signer *BtcSigner) Public() (interface{}, error) {
+	xpub, err := extendedPublicKey(signer.keyProvider)
+	if err != nil {
+		return "", err
+	}
+	return BtcPublicAttributes{
+		XPub: xpub,
+	}, nil
+}
+
+// extendedPublicKey returns the master xpub of the key provider.
+func extendedPublicKey(keyProvider KeyProvider) (string, error) {
 	net := &BtcNetParams
-	pk, err := signer.keyProvider.GetPublicKey()
+	pk, err := keyProvider.GetPublicKey()
 	if err != nil {
 
 		return "", err
@@ -195,7 +266,7 @@ func (signer *BtcSigner) Public() (interface{}, error) {
 
 	pub := (*btcec.PublicKey)(pk)
 	key := pub.SerializeCompressed()
-	chainCode, err := signer.keyProvider.GetChainCode()
+	chainCode, err := keyProvider.GetChainCode()
 	if err != nil {
 
 		return "", err
@@ -208,7 +279,5 @@ func (signer *BtcSigner) Public() (interface{}, error) {
 	if xpub == nil {
 		return "", fmt.Errorf("xpub is nil")
 	}
-	return BtcPublicAttributes{
-		XPub: xpub.String(),
-	}, nil
+	return xpub.String(), nil
 }
@@ -0,0 +1,132 @@
+package signers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/stanche/crypto-interface/ethtx"
+	"github.com/stanche/crypto-interface/safetx"
+)
+
+// EthSigner signs Ethereum transactions built by the ETH connector.
+type EthSigner struct {
+	currency    string
+	keyProvider KeyProvider
+}
+
+// NewEthSigner returns new instance of EthSigner.
+func NewEthSigner(currencyCode string, keyProvider KeyProvider) *EthSigner {
+	return &EthSigner{
+		currency:    currencyCode,
+		keyProvider: keyProvider,
+	}
+}
+
+// CurrencyType implements Signer interface
+func (signer *EthSigner) CurrencyType() string {
+	return signer.currency
+}
+
+// Sign implements Signer interface. txHex is the hex encoded signing payload of TxBuild:
+// the unsigned EIP-1559 or EIP-155 tx, the other data is rejected.
+// signParams is the derivation path of the sender key, i.e. 0, address index.
+// The result is the single hex encoded signature r || s || recovery id for TxRebuild.
+// The Safe payload is signed by the owner key with EIP-712, the signature is r || s || v as Safe expects.
+// Only the plain call of the Safe is signed, see safetx.Tx.Check.
+func (signer *EthSigner) Sign(txHex []byte, signParams []uint64) ([]string, error) {
+	path := make([]uint32, len(signParams))
+	for i, p := range signParams {
+		if p > 0xffffffff {
+			return nil, fmt.Errorf("invalid path index %d", p)
+		}
+		path[i] = uint32(p)
+	}
+	if safetx.IsPayload(string(txHex)) {
+		payload, err := safetx.DecodePayload(string(txHex))
+		if err != nil {
+			return nil, err
+		}
+		if err = payload.Tx.Check(); err != nil {
+			return nil, err
+		}
+		sig, err := EthSignHash(signer.keyProvider, payload.Tx.Hash(), path)
+		if err != nil {
+			return nil, err
+		}
+		sig[64] += 27
+		return []string{hex.EncodeToString(sig)}, nil
+	}
+
+	payload, err := hex.DecodeString(string(txHex))
+	if err != nil {
+		return nil, err
+	}
+	// only the tx is signed, not the arbitrary hash
+	tx, err := ethtx.DecodeUnsignedTx(payload)
+	if err != nil {
+		return nil, err
+	}
+	if tx.ChainID == nil || tx.ChainID.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid chain id of the tx")
+	}
+	sig, err := EthSignHash(signer.keyProvider, tx.SigningHash(), path)
+	if err != nil {
+		return nil, err
+	}
+	return []string{hex.EncodeToString(sig)}, nil
+}
+
+// Public returns Extended Public Key as string, the addresses are generated from it.
+func (signer *EthSigner) Public() (interface{}, error) {
+	xpub, err := extendedPublicKey(signer.keyProvider)
+	if err != nil {
+		return "", err
+	}
+	return BtcPublicAttributes{
+		XPub: xpub,
+	}, nil
+}
+
+// EthSignHash signs the hash with the key on path and returns the recoverable signature r || s || recovery id.
+// The recovery id is found by matching the recovered key against the derived one.
+func EthSignHash(keyProvider KeyProvider, hash []byte, path []uint32) ([]byte, error) {
+	der, err := keyProvider.SignDerived(hash, path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign hash: %s", err.Error())
+	}
+	sig, err := btcec.ParseDERSignature(der, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	// EIP-2 accepts the low s only
+	halfOrder := new(big.Int).Rsh(btcec.S256().N, 1)
+	if sig.S.Cmp(halfOrder) > 0 {
+		sig.S = new(big.Int).Sub(btcec.S256().N, sig.S)
+	}
+	pub, err := keyProvider.DerivedPubkey(path)
+	if err != nil {
+		return nil, err
+	}
+	if pub == nil {
+		return nil, fmt.Errorf("public key is nil")
+	}
+	expected := (*btcec.PublicKey)(pub).SerializeCompressed()
+
+	compact := make([]byte, 65)
+	rb, sb := sig.R.Bytes(), sig.S.Bytes()
+	copy(compact[33-len(rb):33], rb)
+	copy(compact[65-len(sb):], sb)
+	for recID := byte(0); recID < 2; recID++ {
+		compact[0] = 27 + recID
+		recovered, _, err := btcec.RecoverCompact(btcec.S256(), compact, hash)
+		if err != nil || !bytes.Equal(recovered.SerializeCompressed(), expected) {
+			continue
+		}
+		return append(compact[1:], recID), nil
+	}
+	return nil, fmt.Errorf("cannot recover the public key of the signature")
+}
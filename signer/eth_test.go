@@ -0,0 +1,144 @@
+package signers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+
+	addreth "github.com/stanche/crypto-interface/address/eth"
+	"github.com/stanche/crypto-interface/safetx"
+)
+
+func TestEthSigner_Sign(t *testing.T) {
+	component1, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	// EIP-155 example payload
+	payload := "ec098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080018080"
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive getting XPub",
+			func(t *testing.T) {
+				signer := NewEthSigner("ETH", New(component1))
+
+				xpub, err := signer.Public()
+
+				assert.Equal(t, nil, err, "unexpected error")
+				assert.Equal(t, BtcPublicAttributes{
+					XPub: "xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK",
+				}, xpub, "unexpected xpub")
+				assert.Equal(t, "ETH", signer.CurrencyType(), "unexpected CurrencyType")
+			},
+		},
+		{
+			"Positive signing the payload with the derived key",
+			func(t *testing.T) {
+				for _, tc := range []struct {
+					kp   KeyProvider
+					path []uint32
+				}{
+					{New(component1), []uint32{0, 0}},
+					{New(component1), []uint32{0, 7}},
+					{NewLegacyETH(component1), []uint32{0, 7}},
+				} {
+					signer := NewEthSigner("ETH", tc.kp)
+
+					signatures, err := signer.Sign([]byte(payload), []uint64{uint64(tc.path[0]), uint64(tc.path[1])})
+
+					assert.Nil(t, err, "unexpected error")
+					assert.Len(t, signatures, 1, "unexpected signatures")
+					sig, _ := hex.DecodeString(signatures[0])
+					assert.Len(t, sig, 65, "unexpected signature size")
+					raw, _ := hex.DecodeString(payload)
+					recovered, _, err := btcec.RecoverCompact(btcec.S256(),
+						append([]byte{27 + sig[64]}, sig[:64]...), addreth.Keccak256(raw))
+					assert.Nil(t, err, "unexpected error")
+					pub, _ := tc.kp.DerivedPubkey(tc.path)
+					assert.True(t, bytes.Equal(addreth.PubkeyToAddress(pub), addreth.PubkeyToAddress(recovered.ToECDSA())),
+						"unexpected signer of %v", tc.path)
+				}
+			},
+		},
+		{
+			"Positive and negative signing of Safe tx",
+			func(t *testing.T) {
+				signer := NewEthSigner("ETH", New(component1))
+				payload := func(modify func(tx *safetx.Tx)) []byte {
+					p := safetx.Payload{
+						Tx: &safetx.Tx{
+							ChainID: big.NewInt(1),
+							Safe:    bytes.Repeat([]byte{0x5a}, 20),
+							To:      bytes.Repeat([]byte{0x35}, 20),
+							Value:   big.NewInt(1000),
+							Nonce:   big.NewInt(3),
+						},
+						Threshold: 2,
+						Owners:    [][]byte{bytes.Repeat([]byte{0x01}, 20), bytes.Repeat([]byte{0x02}, 20)},
+					}
+					modify(p.Tx)
+					txHex, _ := p.Encode()
+					return []byte(txHex)
+				}
+
+				signatures, err := signer.Sign(payload(func(*safetx.Tx) {}), []uint64{0, 7})
+				assert.Nil(t, err, "unexpected error")
+				sig, _ := hex.DecodeString(signatures[0])
+				assert.Contains(t, []byte{27, 28}, sig[64], "unexpected signature type")
+
+				for name, modify := range map[string]func(tx *safetx.Tx){
+					"delegatecall":    func(tx *safetx.Tx) { tx.Operation = safetx.DelegateCall },
+					"gas price":       func(tx *safetx.Tx) { tx.GasPrice = big.NewInt(1) },
+					"gas token":       func(tx *safetx.Tx) { tx.GasToken = bytes.Repeat([]byte{0x77}, 20) },
+					"refund receiver": func(tx *safetx.Tx) { tx.RefundReceiver = bytes.Repeat([]byte{0x77}, 20) },
+					"chain id":        func(tx *safetx.Tx) { tx.ChainID = big.NewInt(0) },
+					"zero Safe":       func(tx *safetx.Tx) { tx.Safe = make([]byte, 20) },
+				} {
+					_, err = signer.Sign(payload(modify), []uint64{0, 7})
+					assert.NotNil(t, err, "expected error for %s", name)
+				}
+			},
+		},
+		{
+			"Negative signing invalid payload",
+			func(t *testing.T) {
+				signer := NewEthSigner("ETH", New(component1))
+
+				_, err := signer.Sign([]byte("zz"), []uint64{0, 0})
+				assert.NotNil(t, err, "expected error")
+				_, err = signer.Sign([]byte(""), []uint64{0, 0})
+				assert.NotNil(t, err, "expected error")
+				_, err = signer.Sign([]byte(payload), []uint64{0, 1 << 32})
+				assert.NotNil(t, err, "expected error")
+
+				// EIP-712 digest input of Safe tx, the signature would be valid for the Safe
+				safeTx := &safetx.Tx{
+					ChainID: big.NewInt(1),
+					Safe:    bytes.Repeat([]byte{0x5a}, 20),
+					To:      bytes.Repeat([]byte{0x35}, 20),
+					Nonce:   big.NewInt(3),
+				}
+				digest := append([]byte{0x19, 0x01}, safeTx.DomainSeparator()...)
+				digest = append(digest, bytes.Repeat([]byte{0x11}, 32)...)
+				_, err = signer.Sign([]byte(hex.EncodeToString(digest)), []uint64{0, 0})
+				assert.NotNil(t, err, "expected error for the message which is not a tx")
+				// the signed tx and EIP-155 tx without the chain id
+				signed := "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+				_, err = signer.Sign([]byte(signed), []uint64{0, 0})
+				assert.NotNil(t, err, "expected error for the signed tx")
+				_, err = signer.Sign([]byte("ec098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080808080"),
+					[]uint64{0, 0})
+				assert.NotNil(t, err, "expected error for the zero chain id")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, c.exec)
+	}
+}
@@ -0,0 +1,335 @@
+package signers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stanche/crypto-interface/signer/script"
+	"github.com/stanche/crypto-interface/txsize"
+)
+
+type (
+	// InspectOptions defines the data which is not present in the transaction itself.
+	InspectOptions struct {
+		// Amounts of the spent outputs in satoshi, in the order of inputs. Fee is calculated if they are set.
+		Amounts []uint64
+		// ChangeIndexes are the wallet address indexes which may receive the change
+		// in addition to the indexes of the inputs.
+		ChangeIndexes []uint32
+		// PublicKey is the master public key of the signer, it marks inputs the signer is a cosigner of.
+		PublicKey *ecdsa.PublicKey
+	}
+
+	// TxInputSummary describes an input of the inspected transaction.
+	TxInputSummary struct {
+		PrevOut  string
+		Sequence uint32
+		// Amount is zero if the amounts are not provided.
+		Amount   uint64
+		Required int
+		// XPubs are the cosigner xpubs in the script order and Path is the derivation path
+		// of the cosigner keys. They are empty for signed inputs.
+		XPubs []string
+		Path  []uint32
+		// PubKeys are the cosigner public keys in the script order.
+		PubKeys    []string
+		Signatures int
+		// Mine is true if the signer is a cosigner of the unsigned input. It's not set for the signed inputs:
+		// they have the derived keys without the xpubs, which the master public key cannot be matched with.
+		Mine bool
+	}
+
+	// TxOutputSummary describes an output of the inspected transaction.
+	TxOutputSummary struct {
+		Amount uint64
+		// Address is empty for non-standard scripts.
+		Address string
+		Class   string
+		// IsChange is true if the output returns to the wallet of the inputs on ChangePath.
+		IsChange   bool
+		ChangePath []uint32
+	}
+
+	// TxSummary is a structured description of an unsigned or partially signed transaction.
+	TxSummary struct {
+		Version  int32
+		LockTime uint32
+		Inputs   []TxInputSummary
+		Outputs  []TxOutputSummary
+		// OutputTotal includes the change, ChangeTotal is the change only.
+		OutputTotal uint64
+		ChangeTotal uint64
+		// InputTotal, Fee and FeeRate are set only if FeeKnown.
+		FeeKnown   bool
+		InputTotal uint64
+		Fee        uint64
+		// Size is the estimated size of the fully signed transaction in bytes.
+		Size int
+		// FeeRate is in satoshi per byte of the estimated size.
+		FeeRate float64
+	}
+
+	// inspectWallet is a multisig wallet detected from the inputs.
+	inspectWallet struct {
+		m      byte
+		xpubs  []*hdkeychain.ExtendedKey
+		branch uint32
+	}
+)
+
+// Spent returns the amount leaving the wallet: the inputs minus the change, so the fee is included.
+// If the input amounts are unknown, the outputs minus the change are returned.
+func (s *TxSummary) Spent() uint64 {
+	if s.FeeKnown {
+		return s.InputTotal - s.ChangeTotal
+	}
+	return s.OutputTotal - s.ChangeTotal
+}
+
+// Inspect decodes the transaction before signing. The net and the public key of the signer are used.
+func (signer *BtcSigner) Inspect(txHex []byte, amounts []uint64, changeIndexes ...uint32) (*TxSummary, error) {
+	net := signer.net
+	if net == nil {
+		net = &BtcNetParams
+	}
+	pk, err := signer.keyProvider.GetPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return InspectTx(txHex, net, InspectOptions{
+		Amounts:       amounts,
+		ChangeIndexes: changeIndexes,
+		PublicKey:     pk,
+	})
+}
+
+// InspectTx decodes an unsigned (Electrum-like) or signed BTC-like multisig transaction into a summary.
+func InspectTx(txHex []byte, net *chaincfg.Params, options InspectOptions) (*TxSummary, error) {
+	txData, err := hex.DecodeString(string(txHex))
+	if err != nil {
+		return nil, err
+	}
+	var tx wire.MsgTx
+	if err = tx.Deserialize(bytes.NewReader(txData)); err != nil {
+		return nil, err
+	}
+	if len(options.Amounts) > 0 && len(options.Amounts) != len(tx.TxIn) {
+		return nil, fmt.Errorf("inconsistnence amounts (%d) and countTxIn(%d)", len(options.Amounts), len(tx.TxIn))
+	}
+
+	summary := &TxSummary{
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+		Inputs:   make([]TxInputSummary, len(tx.TxIn)),
+		Outputs:  make([]TxOutputSummary, len(tx.TxOut)),
+		Size:     tx.SerializeSize(),
+	}
+
+	var wallets []inspectWallet
+	candidates := make(map[uint32]struct{})
+	for _, index := range options.ChangeIndexes {
+		candidates[index] = struct{}{}
+	}
+
+	for i, txIn := range tx.TxIn {
+		input := &summary.Inputs[i]
+		input.PrevOut = txIn.PreviousOutPoint.String()
+		input.Sequence = txIn.Sequence
+		if len(options.Amounts) > 0 {
+			input.Amount = options.Amounts[i]
+		}
+
+		var wallet *inspectWallet
+		wallet, err = inspectInput(txIn, input, options.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %s", i, err.Error())
+		}
+		if wallet != nil {
+			candidates[input.Path[1]] = struct{}{}
+			if !containsWallet(wallets, wallet) {
+				wallets = append(wallets, *wallet)
+			}
+		}
+
+		// replace the current script with the signed one for the size estimation
+		scriptSize := txsize.ScriptSigSize(input.Required, len(input.PubKeys))
+		if len(input.PubKeys) > 0 {
+			summary.Size += scriptSize + wire.VarIntSerializeSize(uint64(scriptSize)) -
+				len(txIn.SignatureScript) - wire.VarIntSerializeSize(uint64(len(txIn.SignatureScript)))
+		}
+	}
+
+	changeScripts, err := changeScriptHashes(wallets, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, txOut := range tx.TxOut {
+		output := &summary.Outputs[i]
+		output.Amount = uint64(txOut.Value)
+		summary.OutputTotal += output.Amount
+
+		class, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, net)
+		output.Class = class.String()
+		if err == nil && len(addrs) == 1 {
+			output.Address = addrs[0].EncodeAddress()
+		}
+		if class == txscript.ScriptHashTy && len(addrs) == 1 {
+			if path, ok := changeScripts[string(addrs[0].ScriptAddress())]; ok {
+				output.IsChange = true
+				output.ChangePath = path
+				summary.ChangeTotal += output.Amount
+			}
+		}
+	}
+
+	if len(options.Amounts) > 0 {
+		for _, amount := range options.Amounts {
+			summary.InputTotal += amount
+		}
+		if summary.InputTotal < summary.OutputTotal {
+			return nil, fmt.Errorf("outputs (%d) exceed inputs (%d)", summary.OutputTotal, summary.InputTotal)
+		}
+		summary.FeeKnown = true
+		summary.Fee = summary.InputTotal - summary.OutputTotal
+		summary.FeeRate = float64(summary.Fee) / float64(summary.Size)
+	}
+	return summary, nil
+}
+
+// inspectInput fills the input description, it returns the wallet of an unsigned input.
+func inspectInput(txIn *wire.TxIn, input *TxInputSummary, pk *ecdsa.PublicKey) (*inspectWallet, error) {
+	redeemScript, err := script.RedeemScriptFromTxin(txIn)
+	if err == nil {
+		m, xpubs, paths, err := script.XpubsFromScript(redeemScript)
+		if err != nil {
+			return nil, err
+		}
+		input.Required = int(m)
+		input.Path = paths[0]
+		for i, xpub := range xpubs {
+			if !equalPaths(paths[i], input.Path) || len(input.Path) != 2 {
+				return nil, fmt.Errorf("unexpected path of xpub %d", i)
+			}
+			if pk != nil && isKey(xpub, pk) {
+				input.Mine = true
+			}
+			child, err := script.ChildFromXkeyPath(xpub, paths[i])
+			if err != nil {
+				return nil, err
+			}
+			pub, err := child.ECPubKey()
+			if err != nil {
+				return nil, err
+			}
+			input.XPubs = append(input.XPubs, xpub.String())
+			input.PubKeys = append(input.PubKeys, hex.EncodeToString(pub.SerializeCompressed()))
+		}
+		return &inspectWallet{m: m, xpubs: xpubs, branch: input.Path[0]}, nil
+	}
+
+	// signed P2SH multisig input: OP_0 <sig>... <redeem script>
+	pushes, err := txscript.PushedData(txIn.SignatureScript)
+	if err != nil || len(pushes) < 2 {
+		return nil, fmt.Errorf("unsupported signature script")
+	}
+	redeemScript = pushes[len(pushes)-1]
+	if txscript.GetScriptClass(redeemScript) != txscript.MultiSigTy {
+		return nil, fmt.Errorf("unsupported redeem script")
+	}
+	_, input.Required, err = txscript.CalcMultiSigStats(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	redeem, err := txscript.PushedData(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range redeem {
+		input.PubKeys = append(input.PubKeys, hex.EncodeToString(key))
+	}
+	for _, sig := range pushes[1 : len(pushes)-1] {
+		if len(sig) > 1 {
+			input.Signatures++
+		}
+	}
+	return nil, nil
+}
+
+// changeScriptHashes returns the P2SH script hashes of the wallet addresses on the candidate indexes.
+func changeScriptHashes(wallets []inspectWallet, candidates map[uint32]struct{}) (map[string][]uint32, error) {
+	hashes := make(map[string][]uint32)
+	for _, wallet := range wallets {
+		branches := make([]*hdkeychain.ExtendedKey, len(wallet.xpubs))
+		for i, xpub := range wallet.xpubs {
+			var err error
+			branches[i], err = xpub.Child(wallet.branch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for index := range candidates {
+			pubkeys := make([]*btcec.PublicKey, len(branches))
+			for i, branch := range branches {
+				child, err := branch.Child(index)
+				if err != nil {
+					return nil, err
+				}
+				pubkeys[i], err = child.ECPubKey()
+				if err != nil {
+					return nil, err
+				}
+			}
+			msScript, _, err := script.MultisigScriptFromPubkeys(wallet.m, pubkeys, 0)
+			if err != nil {
+				return nil, err
+			}
+			hashes[string(btcutil.Hash160(msScript))] = []uint32{wallet.branch, index}
+		}
+	}
+	return hashes, nil
+}
+
+func containsWallet(wallets []inspectWallet, wallet *inspectWallet) bool {
+	for _, w := range wallets {
+		if w.m != wallet.m || w.branch != wallet.branch || len(w.xpubs) != len(wallet.xpubs) {
+			continue
+		}
+		same := true
+		for i := range w.xpubs {
+			if w.xpubs[i].String() != wallet.xpubs[i].String() {
+				same = false
+				break
+			}
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+func isKey(xpub *hdkeychain.ExtendedKey, pk *ecdsa.PublicKey) bool {
+	pub, err := xpub.ECPubKey()
+	return err == nil && pub.IsEqual((*btcec.PublicKey)(pk))
+}
+
+func equalPaths(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
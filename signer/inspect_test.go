@@ -0,0 +1,132 @@
+package signers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// inspectTxHex spends 0/2 of 2-of-3 wallet of component1..3 to 32dS5pNuf2dJ6Rzm5rGjHt9hsLj1NiZdwk, fee is 1700.
+const inspectTxHex = "0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000"
+
+// inspectTxWithChange moves 100000 of the payout to the wallet address 0/7.
+func inspectTxWithChange() []byte {
+	raw, _ := hex.DecodeString(inspectTxHex)
+	var tx wire.MsgTx
+	_ = tx.Deserialize(bytes.NewReader(raw))
+	tx.TxOut[0].Value -= 100000
+	change, _ := btcutil.DecodeAddress("37oCWpJwCtfud8P391zd2Q2EyiNbabhSVh", &BtcNetParams)
+	changeScript, _ := txscript.PayToAddrScript(change)
+	tx.AddTxOut(wire.NewTxOut(100000, changeScript))
+	var b bytes.Buffer
+	_ = tx.Serialize(&b)
+	return []byte(hex.EncodeToString(b.Bytes()))
+}
+
+func TestInspectTx(t *testing.T) {
+	component1, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	txData := []byte(inspectTxHex)
+	txChange := inspectTxWithChange()
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive inspecting unsigned tx",
+			func(t *testing.T) {
+				signer := NewBtcSigner("BTC", New(component1), &BtcNetParams, BtcTxInputSignature)
+
+				summary, err := signer.Inspect(txData, []uint64{66600000})
+				assert.Nil(t, err, "unexpected error")
+				assert.Len(t, summary.Inputs, 1, "unexpected inputs")
+				assert.Equal(t, []uint32{0, 2}, summary.Inputs[0].Path, "unexpected path")
+				assert.Equal(t, 2, summary.Inputs[0].Required, "unexpected required signatures")
+				assert.Equal(t, "xpub661MyMwAqRbcEtBNvF5oTnmGFSkZvy6ShetrnbVXTz7hyKYJSNBEtKiiY9HnMeTpLKDFJRYW2QSbNGtCGdpCzwZVSPRKevufqeGBwALkBUK", summary.Inputs[0].XPubs[0], "unexpected xpub")
+				assert.True(t, summary.Inputs[0].Mine, "input shall belong to the signer")
+				assert.Equal(t, "32dS5pNuf2dJ6Rzm5rGjHt9hsLj1NiZdwk", summary.Outputs[0].Address, "unexpected address")
+				assert.False(t, summary.Outputs[0].IsChange, "unexpected change")
+				assert.True(t, summary.FeeKnown, "fee shall be known")
+				assert.Equal(t, uint64(1700), summary.Fee, "unexpected fee")
+				assert.Equal(t, 341, summary.Size, "unexpected size")
+			},
+		},
+		{
+			"Positive detecting change",
+			func(t *testing.T) {
+				summary, err := InspectTx(txChange, &BtcNetParams, InspectOptions{ChangeIndexes: []uint32{7}})
+				assert.Nil(t, err, "unexpected error")
+				assert.Len(t, summary.Outputs, 2, "unexpected outputs")
+				assert.False(t, summary.Outputs[0].IsChange, "unexpected change")
+				assert.True(t, summary.Outputs[1].IsChange, "change is not detected")
+				assert.Equal(t, []uint32{0, 7}, summary.Outputs[1].ChangePath, "unexpected change path")
+				assert.Equal(t, uint64(66498300), summary.Spent(), "unexpected spent amount")
+				assert.False(t, summary.FeeKnown, "fee shall be unknown without amounts")
+
+				summary, err = InspectTx(txChange, &BtcNetParams, InspectOptions{})
+				assert.Nil(t, err, "unexpected error")
+				assert.False(t, summary.Outputs[1].IsChange, "change index is not provided")
+			},
+		},
+		{
+			"Positive inspecting partially signed tx",
+			func(t *testing.T) {
+				signer := NewBtcSigner("BTC", New(component1), &BtcNetParams, BtcTxInputSignature)
+				unsigned, err := signer.Inspect(txData, nil)
+				assert.Nil(t, err, "unexpected error")
+
+				var pubKeys []*btcutil.AddressPubKey
+				for _, key := range unsigned.Inputs[0].PubKeys {
+					serialized, _ := hex.DecodeString(key)
+					pubKey, err := btcutil.NewAddressPubKey(serialized, &BtcNetParams)
+					assert.Nil(t, err, "unexpected error")
+					pubKeys = append(pubKeys, pubKey)
+				}
+				redeemScript, err := txscript.MultiSigScript(pubKeys, 2)
+				assert.Nil(t, err, "unexpected error")
+				// OP_0 <signature of the first cosigner> OP_0 <redeem script>
+				sigScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+					AddData(append(bytes.Repeat([]byte{0x30}, 71), byte(txscript.SigHashAll))).
+					AddOp(txscript.OP_0).AddData(redeemScript).Script()
+				assert.Nil(t, err, "unexpected error")
+
+				raw, _ := hex.DecodeString(inspectTxHex)
+				var tx wire.MsgTx
+				_ = tx.Deserialize(bytes.NewReader(raw))
+				tx.TxIn[0].SignatureScript = sigScript
+				var b bytes.Buffer
+				_ = tx.Serialize(&b)
+
+				summary, err := signer.Inspect([]byte(hex.EncodeToString(b.Bytes())), []uint64{66600000})
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, 2, summary.Inputs[0].Required, "unexpected required signatures")
+				assert.Equal(t, 1, summary.Inputs[0].Signatures, "unexpected signatures")
+				assert.Equal(t, unsigned.Inputs[0].PubKeys, summary.Inputs[0].PubKeys, "unexpected keys")
+				assert.Empty(t, summary.Inputs[0].XPubs, "unexpected xpubs")
+				assert.False(t, summary.Inputs[0].Mine, "signed input shall not be marked")
+				assert.Equal(t, unsigned.Size, summary.Size, "unexpected size")
+			},
+		},
+		{
+			"Negative inspecting with outputs exceeding inputs",
+			func(t *testing.T) {
+				_, err := InspectTx(txData, &BtcNetParams, InspectOptions{Amounts: []uint64{1000}})
+				assert.NotNil(t, err, "error expected")
+
+				_, err = InspectTx(txData, &BtcNetParams, InspectOptions{Amounts: []uint64{1000, 2000}})
+				assert.NotNil(t, err, "error expected")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
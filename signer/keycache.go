@@ -0,0 +1,103 @@
+package signers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stanche/crypto-interface/address/hd"
+	"github.com/stanche/crypto-interface/signer/script"
+)
+
+// keyCache keeps the master key and the parents of the derived keys (account or branch level
+// extended private keys), so a transaction with many inputs derives only the last path step per input.
+// It is safe for concurrent use. zero wipes all the cached keys.
+type keyCache struct {
+	mu      sync.RWMutex
+	master  *hdkeychain.ExtendedKey
+	parents map[string]*hdkeychain.ExtendedKey
+}
+
+func newKeyCache() *keyCache {
+	return &keyCache{parents: make(map[string]*hdkeychain.ExtendedKey)}
+}
+
+// child derives the key on path from the seed. The caller shall zero the returned key.
+func (c *keyCache) child(seed []byte, path []uint32) (*hdkeychain.ExtendedKey, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	last := len(path) - 1
+	id := pathID(path[:last])
+
+	c.mu.RLock()
+	parent, ok := c.parents[id]
+	if ok {
+		defer c.mu.RUnlock()
+		return hd.Child(parent, path[last])
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	parent, ok = c.parents[id]
+	if !ok {
+		if c.master == nil {
+			master, err := hdkeychain.NewMaster(seed, &BtcNetParams)
+			if err == nil && master == nil {
+				err = fmt.Errorf("xkey is nil")
+			}
+			if err != nil {
+				return nil, err
+			}
+			c.master = master
+		}
+		var err error
+		parent, err = script.ChildFromXkeyPath(c.master, path[:last])
+		if err != nil {
+			return nil, err
+		}
+		c.parents[id] = parent
+	}
+	return hd.Child(parent, path[last])
+}
+
+// neuteredMaster returns the master extended public key.
+func (c *keyCache) neuteredMaster(seed []byte) (*hdkeychain.ExtendedKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.master == nil {
+		master, err := hdkeychain.NewMaster(seed, &BtcNetParams)
+		if err == nil && master == nil {
+			err = fmt.Errorf("xkey is nil")
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.master = master
+	}
+	return c.master.Neuter()
+}
+
+// zero wipes and drops the cached keys.
+func (c *keyCache) zero() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.master != nil {
+		c.master.Zero()
+		c.master = nil
+	}
+	for id, xkey := range c.parents {
+		xkey.Zero()
+		delete(c.parents, id)
+	}
+}
+
+func pathID(path []uint32) string {
+	id := make([]byte, 4*len(path))
+	for i, step := range path {
+		binary.BigEndian.PutUint32(id[4*i:], step)
+	}
+	return string(id)
+}
@@ -0,0 +1,97 @@
+package signers
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner256k1_keyCache(t *testing.T) {
+	seed, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	hash, _ := hex.DecodeString("be2ec7bbd0f1b1e35e1b0d6d0a3d0e4e3e4e3d0ed3a0b8f0e1c2d3e4f5a6b7c8")
+	paths := [][]uint32{{0, 0}, {0, 1}, {0, 1000}, {1, 5}, {hdkeychain.HardenedKeyStart + 45, 0, 7}, {3}}
+
+	cached := New(seed)
+	uncached := Signer256k1{keyData: seed}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive cached keys match uncached derivation",
+			func(t *testing.T) {
+				for _, path := range paths {
+					sigExpected, err := uncached.SignDerived(hash, path)
+					assert.Nil(t, err, "unexpected error")
+					sig, err := cached.SignDerived(hash, path)
+					assert.Nil(t, err, "unexpected error")
+					assert.Equal(t, sigExpected, sig, "unexpected signature for %v", path)
+				}
+				pkExpected, _ := uncached.GetPublicKey()
+				pk, err := cached.GetPublicKey()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, pkExpected, pk, "unexpected public key")
+			},
+		},
+		{
+			"Positive signing after Zero",
+			func(t *testing.T) {
+				sigExpected, _ := cached.SignDerived(hash, []uint32{0, 1})
+				cached.Zero()
+				assert.Nil(t, cached.keys.master, "master key shall be dropped")
+				assert.Empty(t, cached.keys.parents, "cached keys shall be dropped")
+
+				sig, err := cached.SignDerived(hash, []uint32{0, 1})
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, sigExpected, sig, "unexpected signature")
+			},
+		},
+		{
+			"Positive concurrent derivation",
+			func(t *testing.T) {
+				signer := New(seed)
+				var wg sync.WaitGroup
+				for i := uint32(0); i < 32; i++ {
+					wg.Add(1)
+					go func(i uint32) {
+						defer wg.Done()
+						pkExpected, _ := uncached.DerivedPubkey([]uint32{0, i})
+						pk, err := signer.DerivedPubkey([]uint32{0, i})
+						assert.Nil(t, err, "unexpected error")
+						assert.Equal(t, pkExpected, pk, "unexpected public key")
+					}(i)
+				}
+				wg.Wait()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestSigner256k1_LeadingZeros(t *testing.T) {
+	// BIP32 test vector 4: the key m/0H has leading zeros
+	seed, _ := hex.DecodeString("3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678")
+	xprv, _ := hdkeychain.NewKeyFromString("xprv9xJocDuwtYCMNAo3Zw76WENQeAS6WGXQ55RCy7tDJ8oALr4FWkuVoHJeHVAcAqiZLE7Je3vZJHxspZdFHfnBEjHqU5hG1Jaj32dVoS6XLT1")
+	xpubExpected, _ := xprv.Neuter()
+	pkExpected, _ := xpubExpected.ECPubKey()
+	path := []uint32{hdkeychain.HardenedKeyStart, hdkeychain.HardenedKeyStart + 1}
+
+	for _, signer := range []Signer256k1{New(seed), {keyData: seed}} {
+		xpub, err := signer.ExtendedPublicKey(path, &BtcNetParams)
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, xpubExpected.String(), xpub, "unexpected xpub")
+
+		pk, err := signer.DerivedPubkey(path)
+		assert.Nil(t, err, "unexpected error")
+		assert.Equal(t, pkExpected.ToECDSA(), pk, "unexpected public key")
+	}
+}
@@ -0,0 +1,358 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	signers "github.com/stanche/crypto-interface/signer"
+)
+
+const (
+	version    = 1
+	kdfScrypt  = "scrypt"
+	cipherName = "aes-256-gcm"
+
+	keyLen  = 32
+	saltLen = 32
+
+	// StandardScryptN and StandardScryptP are the recommended scrypt parameters.
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP are scrypt parameters for tests and low-end hosts.
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR = 8
+)
+
+type (
+	// Keystore is a KeyProvider which keeps the seed encrypted in a file.
+	// The seed is decrypted only for the duration of a KeyProvider call.
+	Keystore struct {
+		mu        sync.RWMutex
+		path      string
+		file      keyFile
+		kek       []byte
+		legacyETH bool
+	}
+
+	keyFile struct {
+		Version    int       `json:"version"`
+		KDF        string    `json:"kdf"`
+		KDFParams  kdfParams `json:"kdfparams"`
+		Cipher     string    `json:"cipher"`
+		Nonce      string    `json:"nonce"`
+		CipherText string    `json:"ciphertext"`
+		LegacyETH  bool      `json:"legacy_eth,omitempty"`
+	}
+
+	kdfParams struct {
+		N    int    `json:"n"`
+		R    int    `json:"r"`
+		P    int    `json:"p"`
+		Salt string `json:"salt"`
+	}
+)
+
+var (
+	// ErrLocked is returned when the keystore is used before Unlock.
+	ErrLocked = fmt.Errorf("keystore is locked")
+	// ErrDecrypt is returned when the passphrase is wrong or the file is damaged.
+	ErrDecrypt = fmt.Errorf("could not decrypt the seed with the given passphrase")
+)
+
+// Create encrypts the seed with the passphrase and stores it in a new keystore file.
+// If legacyETH is set, the keystore provides keys as signers.NewLegacyETH does.
+func Create(path string, seed, passphrase []byte, scryptN, scryptP int, legacyETH bool) (*Keystore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("keystore file %s already exists", path)
+	}
+	ks := &Keystore{path: path, legacyETH: legacyETH}
+	kek, err := ks.encrypt(seed, passphrase, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	zero(kek)
+	if err = ks.save(true); err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("keystore file %s already exists", path)
+		}
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Open loads the keystore file. The keystore is locked until Unlock.
+func Open(path string) (*Keystore, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("keystore file %s: %s", path, err.Error())
+	}
+	if file.Version != version || file.KDF != kdfScrypt || file.Cipher != cipherName {
+		return nil, fmt.Errorf("unsupported keystore file %s: version %d, %s, %s",
+			path, file.Version, file.KDF, file.Cipher)
+	}
+	return &Keystore{path: path, file: file, legacyETH: file.LegacyETH}, nil
+}
+
+// Unlock checks the passphrase and keeps the derived key encryption key in memory.
+// The seed itself stays encrypted.
+func (ks *Keystore) Unlock(passphrase []byte) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	kek, err := ks.deriveKey(passphrase, ks.file.KDFParams)
+	if err != nil {
+		return err
+	}
+	seed, err := ks.decrypt(kek)
+	if err != nil {
+		zero(kek)
+		return err
+	}
+	zero(seed)
+
+	zero(ks.kek)
+	ks.kek = kek
+	return nil
+}
+
+// Lock wipes the key encryption key from memory.
+func (ks *Keystore) Lock() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	zero(ks.kek)
+	ks.kek = nil
+}
+
+// IsLocked reports whether the keystore requires Unlock.
+func (ks *Keystore) IsLocked() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.kek == nil
+}
+
+// ChangePassphrase re-encrypts the seed with the new passphrase and rewrites the file.
+// The keystore is left locked.
+func (ks *Keystore) ChangePassphrase(oldPassphrase, newPassphrase []byte) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	kek, err := ks.deriveKey(oldPassphrase, ks.file.KDFParams)
+	if err != nil {
+		return err
+	}
+	seed, err := ks.decrypt(kek)
+	zero(kek)
+	if err != nil {
+		return err
+	}
+	defer zero(seed)
+
+	prev := ks.file
+	kek, err = ks.encrypt(seed, newPassphrase, prev.KDFParams.N, prev.KDFParams.P)
+	if err != nil {
+		return err
+	}
+	zero(kek)
+	if err = ks.save(false); err != nil {
+		ks.file = prev
+		return err
+	}
+	zero(ks.kek)
+	ks.kek = nil
+	return nil
+}
+
+// SignDerived implements signers.KeyProvider.
+func (ks *Keystore) SignDerived(hash []byte, path []uint32) (sig []byte, err error) {
+	err = ks.withSigner(func(s signers.Signer256k1) error {
+		sig, err = s.SignDerived(hash, path)
+		return err
+	})
+	return
+}
+
+// SignSchnorrDerived implements signers.SchnorrKeyProvider.
+func (ks *Keystore) SignSchnorrDerived(hash []byte, path []uint32) (sig []byte, err error) {
+	err = ks.withSigner(func(s signers.Signer256k1) error {
+		sig, err = s.SignSchnorrDerived(hash, path)
+		return err
+	})
+	return
+}
+
+// DerivedPubkey implements signers.KeyProvider.
+func (ks *Keystore) DerivedPubkey(path []uint32) (pk *ecdsa.PublicKey, err error) {
+	err = ks.withSigner(func(s signers.Signer256k1) error {
+		pk, err = s.DerivedPubkey(path)
+		return err
+	})
+	return
+}
+
+// GetPublicKey implements signers.KeyProvider.
+func (ks *Keystore) GetPublicKey() (pk *ecdsa.PublicKey, err error) {
+	err = ks.withSigner(func(s signers.Signer256k1) error {
+		pk, err = s.GetPublicKey()
+		return err
+	})
+	return
+}
+
+// GetChainCode implements signers.KeyProvider.
+func (ks *Keystore) GetChainCode() (chainCode []byte, err error) {
+	err = ks.withSigner(func(s signers.Signer256k1) error {
+		chainCode, err = s.GetChainCode()
+		return err
+	})
+	return
+}
+
+// SignsConcurrently implements signers.ConcurrentKeyProvider.
+func (ks *Keystore) SignsConcurrently() bool {
+	return true
+}
+
+// withSigner decrypts the seed, calls f and wipes the seed.
+func (ks *Keystore) withSigner(f func(signers.Signer256k1) error) error {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if ks.kek == nil {
+		return ErrLocked
+	}
+	seed, err := ks.decrypt(ks.kek)
+	if err != nil {
+		return err
+	}
+	defer zero(seed)
+
+	signer := signers.New(seed)
+	if ks.legacyETH {
+		signer = signers.NewLegacyETH(seed)
+	}
+	defer signer.Zero()
+	return f(signer)
+}
+
+func (ks *Keystore) deriveKey(passphrase []byte, params kdfParams) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return scrypt.Key(passphrase, salt, params.N, params.R, params.P, keyLen)
+}
+
+// encrypt derives a new key encryption key with a fresh salt, encrypts the seed and updates ks.file.
+func (ks *Keystore) encrypt(seed, passphrase []byte, scryptN, scryptP int) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := kdfParams{N: scryptN, R: scryptR, P: scryptP, Salt: hex.EncodeToString(salt)}
+	kek, err := ks.deriveKey(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(kek)
+	if err != nil {
+		zero(kek)
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		zero(kek)
+		return nil, err
+	}
+	ks.file = keyFile{
+		Version:    version,
+		KDF:        kdfScrypt,
+		KDFParams:  params,
+		Cipher:     cipherName,
+		Nonce:      hex.EncodeToString(nonce),
+		CipherText: hex.EncodeToString(aead.Seal(nil, nonce, seed, additionalData(params))),
+		LegacyETH:  ks.legacyETH,
+	}
+	return kek, nil
+}
+
+func (ks *Keystore) decrypt(kek []byte) ([]byte, error) {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(ks.file.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	cipherText, err := hex.DecodeString(ks.file.CipherText)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	seed, err := aead.Open(nil, nonce, cipherText, additionalData(ks.file.KDFParams))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return seed, nil
+}
+
+// save writes the keystore file atomically. If create is set, an existing file is not replaced.
+func (ks *Keystore) save(create bool) error {
+	data, err := json.MarshalIndent(ks.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(ks.path), "."+filepath.Base(ks.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if create {
+		// unlike rename, link fails if the file has been created meanwhile
+		return os.Link(tmp.Name(), ks.path)
+	}
+	return os.Rename(tmp.Name(), ks.path)
+}
+
+func newAEAD(kek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the ciphertext to the KDF parameters.
+func additionalData(params kdfParams) []byte {
+	return []byte(fmt.Sprintf("%s:%d:%d:%d:%s", kdfScrypt, params.N, params.R, params.P, params.Salt))
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,104 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	signers "github.com/stanche/crypto-interface/signer"
+)
+
+func TestKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signer-a.json")
+
+	seed, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	local := signers.New(seed)
+	hash, _ := hex.DecodeString("be2ec7bbd0f1b1e35e1b0d6d0a3d0e4e3e4e3d0ed3a0b8f0e1c2d3e4f5a6b7c8")
+
+	ks, err := Create(path, seed, []byte("passphrase"), LightScryptN, LightScryptP, false)
+	assert.Nil(t, err, "unexpected error")
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Negative signing with locked keystore",
+			func(t *testing.T) {
+				_, err := ks.SignDerived(hash, []uint32{0, 1})
+				assert.Equal(t, ErrLocked, err, "unexpected error")
+			},
+		},
+		{
+			"Negative unlock with wrong passphrase",
+			func(t *testing.T) {
+				err := ks.Unlock([]byte("wrong"))
+				assert.Equal(t, ErrDecrypt, err, "unexpected error")
+				assert.True(t, ks.IsLocked(), "keystore shall stay locked")
+			},
+		},
+		{
+			"Positive signing with unlocked keystore",
+			func(t *testing.T) {
+				assert.Nil(t, ks.Unlock([]byte("passphrase")), "unexpected error")
+
+				sigExpected, _ := local.SignDerived(hash, []uint32{0, 1})
+				sig, err := ks.SignDerived(hash, []uint32{0, 1})
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, sigExpected, sig, "unexpected signature")
+
+				pkExpected, _ := local.GetPublicKey()
+				pk, err := ks.GetPublicKey()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, pkExpected, pk, "unexpected public key")
+
+				ks.Lock()
+				assert.True(t, ks.IsLocked(), "keystore shall be locked")
+			},
+		},
+		{
+			"Negative creating over existing keystore",
+			func(t *testing.T) {
+				data, _ := ioutil.ReadFile(path)
+				_, err := Create(path, []byte("other seed"), []byte("other"), LightScryptN, LightScryptP, false)
+				assert.NotNil(t, err, "existing keystore shall not be overwritten")
+				// the file created after the check is not replaced either
+				assert.True(t, os.IsExist(ks.save(true)), "existing keystore shall not be overwritten")
+				kept, _ := ioutil.ReadFile(path)
+				assert.Equal(t, data, kept, "unexpected keystore file")
+			},
+		},
+		{
+			"Positive passphrase rotation",
+			func(t *testing.T) {
+				assert.Equal(t, ErrDecrypt, ks.ChangePassphrase([]byte("wrong"), []byte("new")), "unexpected error")
+				assert.Nil(t, ks.ChangePassphrase([]byte("passphrase"), []byte("new passphrase")), "unexpected error")
+
+				reopened, err := Open(path)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, ErrDecrypt, reopened.Unlock([]byte("passphrase")), "old passphrase shall not work")
+				assert.Nil(t, reopened.Unlock([]byte("new passphrase")), "unexpected error")
+
+				ccExpected, _ := local.GetChainCode()
+				cc, err := reopened.GetChainCode()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, ccExpected, cc, "unexpected chain code")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -22,3 +22,18 @@ type KeyProvider interface {
 	GetChainCode() ([]byte, error)
 	DerivedPubkey(path []uint32) (*ecdsa.PublicKey, error)
 }
+
+// ConcurrentKeyProvider is implemented by key providers which allow SignDerived calls from several goroutines.
+// Inputs of a transaction are signed in parallel for such providers.
+type ConcurrentKeyProvider interface {
+	KeyProvider
+	SignsConcurrently() bool
+}
+
+// SchnorrKeyProvider is implemented by key providers which produce BCH Schnorr signatures.
+type SchnorrKeyProvider interface {
+	KeyProvider
+	// SignSchnorrDerived signs the hash using the path in HD tree. The resulting signature is 64 bytes: R.x || s,
+	// the hash type is not appended.
+	SignSchnorrDerived(hash []byte, path []uint32) ([]byte, error)
+}
@@ -0,0 +1,405 @@
+package signers
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Reasons of policy violations.
+const (
+	ReasonDestination   = "destination_not_allowed"
+	ReasonTxLimit       = "tx_limit_exceeded"
+	ReasonDailyLimit    = "daily_limit_exceeded"
+	ReasonFeeUnknown    = "fee_unknown"
+	ReasonAmountUnknown = "amount_unknown"
+	ReasonFee           = "fee_exceeded"
+	ReasonFeeRate       = "feerate_exceeded"
+	ReasonForeignInput  = "foreign_input"
+	ReasonForeignOutput = "foreign_output"
+)
+
+type (
+	// Policy checks a transaction before signing. Check returns *PolicyViolation to reject the transaction.
+	Policy interface {
+		Check(summary *TxSummary) error
+	}
+
+	// PolicyCommitter is implemented by stateful policies, Commit is called when all the policies accepted the transaction.
+	// The returned function reverts the commit if the transaction is not signed.
+	PolicyCommitter interface {
+		Commit(summary *TxSummary) (rollback func())
+	}
+
+	// PolicyCommit is the state of the stateful policies changed by an accepted transaction.
+	// It shall be committed when the transaction is signed and rolled back otherwise.
+	PolicyCommit struct {
+		mu        sync.Mutex
+		rollbacks []func()
+	}
+
+	// PolicyViolation is a machine-readable rejection of a policy.
+	PolicyViolation struct {
+		Reason string `json:"reason"`
+		// Output is the index of the violating output, -1 if the violation is not related to an output.
+		Output  int    `json:"output"`
+		Message string `json:"message"`
+	}
+
+	// PolicyError is returned by Sign when the transaction is rejected. It contains all the violations found.
+	PolicyError struct {
+		Violations []PolicyViolation
+	}
+
+	// PolicyEngine inspects a transaction and evaluates the policies.
+	PolicyEngine struct {
+		// ChangeIndexes are the wallet address indexes allowed to receive the change, see InspectOptions.
+		ChangeIndexes []uint32
+		Policies      []Policy
+		mu            sync.Mutex
+	}
+
+	// Whitelist allows to send only to the listed addresses. The change is always allowed.
+	Whitelist map[string]struct{}
+
+	// TxLimit limits the amount leaving the wallet in a transaction including the fee.
+	// The input amounts shall be provided.
+	TxLimit uint64
+
+	// MaxFee limits the fee of a transaction. The input amounts shall be provided.
+	MaxFee uint64
+
+	// MaxFeeRate limits the fee rate (satoshi per byte) of a transaction. The input amounts shall be provided.
+	MaxFeeRate float64
+
+	// SameWalletChange requires all the inputs to be spent from a single wallet, so the change
+	// can only return to it. If MaxPayouts is non-zero, the number of outputs which are not the change is limited,
+	// so an output to a foreign address cannot pretend to be the change.
+	SameWalletChange struct {
+		MaxPayouts int
+	}
+
+	// DailyLimit limits the amount leaving the wallet in a rolling window of 24 hours including the fees.
+	// The input amounts shall be provided.
+	DailyLimit struct {
+		Limit  uint64
+		Window time.Duration
+		mu     sync.Mutex
+		spends []*dailySpend
+		now    func() time.Time
+	}
+
+	dailySpend struct {
+		at     time.Time
+		amount uint64
+	}
+)
+
+// NewPolicyEngine creates a policy engine.
+func NewPolicyEngine(changeIndexes []uint32, policies ...Policy) *PolicyEngine {
+	return &PolicyEngine{ChangeIndexes: changeIndexes, Policies: policies}
+}
+
+// Evaluate inspects the transaction and checks it against all the policies.
+// A *PolicyError is returned if any of them rejects the transaction. Otherwise the stateful policies
+// count the transaction and the returned PolicyCommit shall be committed after signing or rolled back.
+func (e *PolicyEngine) Evaluate(txHex []byte, net *chaincfg.Params, pk *ecdsa.PublicKey, amounts []uint64) (*TxSummary, *PolicyCommit, error) {
+	summary, err := InspectTx(txHex, net, InspectOptions{
+		Amounts:       amounts,
+		ChangeIndexes: e.ChangeIndexes,
+		PublicKey:     pk,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// stateful policies shall see the transactions one by one
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var policyErr PolicyError
+	for _, policy := range e.Policies {
+		err = policy.Check(summary)
+		if err == nil {
+			continue
+		}
+		switch v := err.(type) {
+		case *PolicyViolation:
+			policyErr.Violations = append(policyErr.Violations, *v)
+		case *PolicyError:
+			policyErr.Violations = append(policyErr.Violations, v.Violations...)
+		default:
+			return nil, nil, err
+		}
+	}
+	if len(policyErr.Violations) > 0 {
+		return summary, nil, &policyErr
+	}
+	commit := &PolicyCommit{}
+	for _, policy := range e.Policies {
+		if committer, ok := policy.(PolicyCommitter); ok {
+			commit.rollbacks = append(commit.rollbacks, committer.Commit(summary))
+		}
+	}
+	return summary, commit, nil
+}
+
+// Commit keeps the changes of the stateful policies. It is safe to call it on nil.
+func (c *PolicyCommit) Commit() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollbacks = nil
+}
+
+// Rollback reverts the changes of the stateful policies unless committed. It is safe to call it on nil.
+func (c *PolicyCommit) Rollback() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rollback := range c.rollbacks {
+		rollback()
+	}
+	c.rollbacks = nil
+}
+
+func (v *PolicyViolation) Error() string {
+	if v.Output >= 0 {
+		return fmt.Sprintf("%s: output %d: %s", v.Reason, v.Output, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Reason, v.Message)
+}
+
+func (e *PolicyError) Error() string {
+	items := make([]string, len(e.Violations))
+	for i := range e.Violations {
+		items[i] = e.Violations[i].Error()
+	}
+	return "policy violation: " + strings.Join(items, "; ")
+}
+
+// Reasons returns the reasons of the violations.
+func (e *PolicyError) Reasons() []string {
+	reasons := make([]string, len(e.Violations))
+	for i := range e.Violations {
+		reasons[i] = e.Violations[i].Reason
+	}
+	return reasons
+}
+
+// NewWhitelist creates a whitelist of the destination addresses.
+func NewWhitelist(addresses ...string) Whitelist {
+	w := make(Whitelist, len(addresses))
+	for _, addr := range addresses {
+		w[addr] = struct{}{}
+	}
+	return w
+}
+
+// Check implements Policy.
+func (w Whitelist) Check(summary *TxSummary) error {
+	var policyErr PolicyError
+	for i, output := range summary.Outputs {
+		if output.IsChange {
+			continue
+		}
+		if _, ok := w[output.Address]; !ok || output.Address == "" {
+			policyErr.Violations = append(policyErr.Violations, PolicyViolation{
+				Reason:  ReasonDestination,
+				Output:  i,
+				Message: fmt.Sprintf("address %q is not whitelisted", output.Address),
+			})
+		}
+	}
+	if len(policyErr.Violations) > 0 {
+		return &policyErr
+	}
+	return nil
+}
+
+// Check implements Policy.
+func (l TxLimit) Check(summary *TxSummary) error {
+	if !summary.FeeKnown {
+		return amountUnknown()
+	}
+	if spent := summary.Spent(); spent > uint64(l) {
+		return &PolicyViolation{
+			Reason:  ReasonTxLimit,
+			Output:  -1,
+			Message: fmt.Sprintf("spent %d exceeds limit %d", spent, uint64(l)),
+		}
+	}
+	return nil
+}
+
+// Check implements Policy.
+func (f MaxFee) Check(summary *TxSummary) error {
+	if !summary.FeeKnown {
+		return feeUnknown()
+	}
+	if summary.Fee > uint64(f) {
+		return &PolicyViolation{
+			Reason:  ReasonFee,
+			Output:  -1,
+			Message: fmt.Sprintf("fee %d exceeds limit %d", summary.Fee, uint64(f)),
+		}
+	}
+	return nil
+}
+
+// Check implements Policy.
+func (f MaxFeeRate) Check(summary *TxSummary) error {
+	if !summary.FeeKnown {
+		return feeUnknown()
+	}
+	if summary.FeeRate > float64(f) {
+		return &PolicyViolation{
+			Reason:  ReasonFeeRate,
+			Output:  -1,
+			Message: fmt.Sprintf("fee rate %.2f exceeds limit %.2f", summary.FeeRate, float64(f)),
+		}
+	}
+	return nil
+}
+
+func feeUnknown() error {
+	return &PolicyViolation{
+		Reason:  ReasonFeeUnknown,
+		Output:  -1,
+		Message: "input amounts are not provided",
+	}
+}
+
+func amountUnknown() error {
+	return &PolicyViolation{
+		Reason:  ReasonAmountUnknown,
+		Output:  -1,
+		Message: "input amounts are not provided",
+	}
+}
+
+// Check implements Policy.
+func (c SameWalletChange) Check(summary *TxSummary) error {
+	var policyErr PolicyError
+	var wallet []string
+	for i, input := range summary.Inputs {
+		if len(input.XPubs) == 0 {
+			policyErr.Violations = append(policyErr.Violations, PolicyViolation{
+				Reason:  ReasonForeignInput,
+				Output:  -1,
+				Message: fmt.Sprintf("wallet of input %d is unknown", i),
+			})
+			continue
+		}
+		if wallet == nil {
+			wallet = input.XPubs
+			continue
+		}
+		if strings.Join(wallet, ",") != strings.Join(input.XPubs, ",") {
+			policyErr.Violations = append(policyErr.Violations, PolicyViolation{
+				Reason:  ReasonForeignInput,
+				Output:  -1,
+				Message: fmt.Sprintf("input %d is from another wallet", i),
+			})
+		}
+	}
+	if c.MaxPayouts > 0 {
+		payouts := 0
+		for i, output := range summary.Outputs {
+			if output.IsChange {
+				continue
+			}
+			payouts++
+			if payouts > c.MaxPayouts {
+				policyErr.Violations = append(policyErr.Violations, PolicyViolation{
+					Reason:  ReasonForeignOutput,
+					Output:  i,
+					Message: fmt.Sprintf("output to %q is neither a payout nor the change", output.Address),
+				})
+			}
+		}
+	}
+	if len(policyErr.Violations) > 0 {
+		return &policyErr
+	}
+	return nil
+}
+
+// NewDailyLimit creates a limit for a rolling window of 24 hours.
+func NewDailyLimit(limit uint64) *DailyLimit {
+	return &DailyLimit{Limit: limit, Window: 24 * time.Hour, now: time.Now}
+}
+
+// Check implements Policy.
+func (d *DailyLimit) Check(summary *TxSummary) error {
+	if !summary.FeeKnown {
+		return amountUnknown()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	total := d.spentLocked() + summary.Spent()
+	if total > d.Limit {
+		return &PolicyViolation{
+			Reason:  ReasonDailyLimit,
+			Output:  -1,
+			Message: fmt.Sprintf("spent %d in the window exceeds limit %d", total, d.Limit),
+		}
+	}
+	return nil
+}
+
+// Commit implements PolicyCommitter, it records the amount spent by the transaction.
+func (d *DailyLimit) Commit(summary *TxSummary) func() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	spend := &dailySpend{at: d.clock(), amount: summary.Spent()}
+	d.spends = append(d.spends, spend)
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for i := range d.spends {
+			if d.spends[i] == spend {
+				d.spends = append(d.spends[:i], d.spends[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Spent returns the amount spent in the current window.
+func (d *DailyLimit) Spent() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.spentLocked()
+}
+
+func (d *DailyLimit) spentLocked() uint64 {
+	since := d.clock().Add(-d.Window)
+	// drop expired spends
+	i := 0
+	for i < len(d.spends) && !d.spends[i].at.After(since) {
+		i++
+	}
+	d.spends = d.spends[i:]
+
+	var total uint64
+	for _, spend := range d.spends {
+		total += spend.amount
+	}
+	return total
+}
+
+func (d *DailyLimit) clock() time.Time {
+	if d.now == nil {
+		return time.Now()
+	}
+	return d.now()
+}
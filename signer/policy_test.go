@@ -0,0 +1,161 @@
+package signers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingKeyProvider fails to sign.
+type failingKeyProvider struct {
+	Signer256k1
+}
+
+func (failingKeyProvider) SignDerived(hash []byte, path []uint32) ([]byte, error) {
+	return nil, fmt.Errorf("signing failed")
+}
+
+func TestPolicyEngine(t *testing.T) {
+	component1, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	pk, _ := New(component1).GetPublicKey()
+	txData := []byte(inspectTxHex)
+	txChange := inspectTxWithChange()
+	amounts := []uint64{66600000}
+	payout := "32dS5pNuf2dJ6Rzm5rGjHt9hsLj1NiZdwk"
+
+	reasons := func(err error) []string {
+		policyErr, ok := err.(*PolicyError)
+		if !ok {
+			return nil
+		}
+		return policyErr.Reasons()
+	}
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive accepting whitelisted destination with the change",
+			func(t *testing.T) {
+				engine := NewPolicyEngine([]uint32{7},
+					NewWhitelist(payout), TxLimit(66500000), MaxFee(2000), MaxFeeRate(10), SameWalletChange{MaxPayouts: 1})
+				summary, _, err := engine.Evaluate(txChange, &BtcNetParams, pk, amounts)
+				assert.Nil(t, err, "unexpected error")
+				// the fee is spent as well
+				assert.Equal(t, uint64(66500000), summary.Spent(), "unexpected spent amount")
+
+				engine = NewPolicyEngine([]uint32{7}, TxLimit(66499999))
+				_, _, err = engine.Evaluate(txChange, &BtcNetParams, pk, amounts)
+				assert.Equal(t, []string{ReasonTxLimit}, reasons(err), "unexpected reasons")
+			},
+		},
+		{
+			"Negative rejecting unknown destination and the limits",
+			func(t *testing.T) {
+				engine := NewPolicyEngine(nil, NewWhitelist("3AnotherAddress"), TxLimit(1000000), MaxFee(1000), MaxFeeRate(1))
+				_, _, err := engine.Evaluate(txData, &BtcNetParams, pk, amounts)
+				assert.Equal(t, []string{ReasonDestination, ReasonTxLimit, ReasonFee, ReasonFeeRate}, reasons(err), "unexpected reasons")
+				assert.Equal(t, 0, err.(*PolicyError).Violations[0].Output, "unexpected output")
+			},
+		},
+		{
+			"Negative rejecting fee and limit policies without amounts",
+			func(t *testing.T) {
+				engine := NewPolicyEngine(nil, MaxFee(2000), TxLimit(100000000), NewDailyLimit(100000000))
+				_, _, err := engine.Evaluate(txData, &BtcNetParams, pk, nil)
+				assert.Equal(t, []string{ReasonFeeUnknown, ReasonAmountUnknown, ReasonAmountUnknown}, reasons(err), "unexpected reasons")
+			},
+		},
+		{
+			"Negative rejecting change to an address which is not the wallet change",
+			func(t *testing.T) {
+				// the change index is not declared, so the output is a second payout
+				engine := NewPolicyEngine(nil, SameWalletChange{MaxPayouts: 1})
+				_, _, err := engine.Evaluate(txChange, &BtcNetParams, pk, amounts)
+				assert.Equal(t, []string{ReasonForeignOutput}, reasons(err), "unexpected reasons")
+				assert.Equal(t, 1, err.(*PolicyError).Violations[0].Output, "unexpected output")
+			},
+		},
+		{
+			"Positive rolling daily limit",
+			func(t *testing.T) {
+				now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+				limit := NewDailyLimit(100000000)
+				limit.now = func() time.Time { return now }
+				engine := NewPolicyEngine(nil, limit, MaxFee(1000))
+
+				// rejected transactions are not counted
+				_, _, err := engine.Evaluate(txData, &BtcNetParams, pk, amounts)
+				assert.Equal(t, []string{ReasonFee}, reasons(err), "unexpected reasons")
+				assert.Equal(t, uint64(0), limit.Spent(), "unexpected spent amount")
+
+				// rolled back transactions are not counted
+				engine.Policies = engine.Policies[:1]
+				_, commit, err := engine.Evaluate(txData, &BtcNetParams, pk, amounts)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, uint64(66600000), limit.Spent(), "unexpected reserved amount")
+				commit.Rollback()
+				assert.Equal(t, uint64(0), limit.Spent(), "unexpected spent amount")
+
+				_, commit, err = engine.Evaluate(txData, &BtcNetParams, pk, amounts)
+				assert.Nil(t, err, "unexpected error")
+				commit.Commit()
+				commit.Rollback()
+				assert.Equal(t, uint64(66600000), limit.Spent(), "unexpected spent amount")
+
+				now = now.Add(23 * time.Hour)
+				_, _, err = engine.Evaluate(txData, &BtcNetParams, pk, amounts)
+				assert.Equal(t, []string{ReasonDailyLimit}, reasons(err), "unexpected reasons")
+
+				now = now.Add(2 * time.Hour)
+				_, _, err = engine.Evaluate(txData, &BtcNetParams, pk, amounts)
+				assert.Nil(t, err, "unexpected error")
+			},
+		},
+		{
+			"Negative signing rejected by the policy",
+			func(t *testing.T) {
+				signer := NewBtcSigner("BTC", New(component1), &BtcNetParams, BtcTxInputSignature)
+				signer.PolicySet(NewPolicyEngine(nil, TxLimit(1000)))
+
+				sign, err := signer.Sign(txData, amounts)
+				assert.Nil(t, sign, "unexpected signatures")
+				assert.Equal(t, []string{ReasonTxLimit}, reasons(err), "unexpected reasons")
+
+				signer.PolicySet(NewPolicyEngine(nil, NewWhitelist(payout)))
+				sign, err = signer.Sign(txData, nil)
+				assert.Nil(t, err, "unexpected error")
+				assert.Len(t, sign, 1, "unexpected signatures")
+			},
+		},
+		{
+			"Positive counting the daily limit only for signed transactions",
+			func(t *testing.T) {
+				limit := NewDailyLimit(100000000)
+				engine := NewPolicyEngine(nil, limit)
+
+				failing := NewBtcSigner("BTC", failingKeyProvider{New(component1)}, &BtcNetParams, BtcTxInputSignature)
+				failing.PolicySet(engine)
+				_, err := failing.Sign(txData, amounts)
+				assert.NotNil(t, err, "expected error")
+				assert.Equal(t, uint64(0), limit.Spent(), "unexpected spent amount")
+
+				signer := NewBtcSigner("BTC", New(component1), &BtcNetParams, BtcTxInputSignature)
+				signer.PolicySet(engine)
+				_, err = signer.Sign(txData, amounts)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, uint64(66600000), limit.Spent(), "unexpected spent amount")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Client implements signers.KeyProvider by forwarding the calls to a remote signing Server.
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	secret     []byte
+}
+
+// NewClient creates a key provider connected to the signing server at rpcURL.
+// secret is shared with the server and used to authenticate the requests and the responses.
+func NewClient(rpcURL string, secret []byte, timeout int) (*Client, error) {
+	if err := checkSecret(secret); err != nil {
+		return nil, err
+	}
+	return &Client{
+		URL:        strings.TrimRight(rpcURL, "/"),
+		HTTPClient: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		secret:     secret,
+	}, nil
+}
+
+// SignDerived signs the hash on the remote signer using the key on path in HD tree.
+func (c *Client) SignDerived(hash []byte, path []uint32) ([]byte, error) {
+	return c.send(pathSignDerived, signDerivedRequest{Hash: hash, Path: path})
+}
+
+// SignSchnorrDerived signs the hash on the remote signer with BCH Schnorr scheme using the key on path in HD tree.
+func (c *Client) SignSchnorrDerived(hash []byte, path []uint32) ([]byte, error) {
+	return c.send(pathSignSchnorr, signDerivedRequest{Hash: hash, Path: path})
+}
+
+// DerivedPubkey returns a public key that relates to path on HD tree of the remote signer.
+func (c *Client) DerivedPubkey(path []uint32) (*ecdsa.PublicKey, error) {
+	data, err := c.send(pathDerivedPubkey, derivedPubkeyRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	return parsePubkey(data)
+}
+
+// GetPublicKey returns a master public key of the remote signer.
+func (c *Client) GetPublicKey() (*ecdsa.PublicKey, error) {
+	data, err := c.send(pathPublicKey, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return parsePubkey(data)
+}
+
+// GetChainCode returns a master chain code of the remote signer.
+func (c *Client) GetChainCode() ([]byte, error) {
+	return c.send(pathChainCode, struct{}{})
+}
+
+func (c *Client) send(path string, request interface{}) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	requestCode, err := authenticate(req, c.secret, req.URL.Path, body)
+	if err != nil {
+		return nil, fmt.Errorf("remote.send.authenticate: %s", err.Error())
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote.send.http: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("remote.send.ReadAll: %s", err.Error())
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrUnauthorized
+	}
+	if err = verifyResponse(resp, c.secret, requestCode, respBytes); err != nil {
+		return nil, err
+	}
+	var res response
+	err = json.Unmarshal(respBytes, &res)
+	if err != nil {
+		return nil, fmt.Errorf("http status: %s (%d)", resp.Status, resp.StatusCode)
+	}
+	if res.Error != "" {
+		return nil, fmt.Errorf("remote signer: %s", res.Error)
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("remote signer response is empty")
+	}
+	return res.Data, nil
+}
+
+func parsePubkey(data []byte) (*ecdsa.PublicKey, error) {
+	pub, err := btcec.ParsePubKey(data, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	return pub.ToECDSA(), nil
+}
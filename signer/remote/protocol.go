@@ -0,0 +1,166 @@
+package remote
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	pathSignDerived   = "/sign_derived"
+	pathSignSchnorr   = "/sign_schnorr"
+	pathDerivedPubkey = "/derived_pubkey"
+	pathPublicKey     = "/public_key"
+	pathChainCode     = "/chain_code"
+
+	headerTimestamp = "X-Signer-Timestamp"
+	headerSignature = "X-Signer-Signature"
+	headerNonce     = "X-Signer-Nonce"
+
+	// MinSecretSize is the minimal size of the secret shared by the Client and the Server.
+	MinSecretSize = 32
+	nonceSize     = 16
+
+	// maxClockSkew limits the age of a request to protect against replays.
+	maxClockSkew = 30 * time.Second
+)
+
+type (
+	signDerivedRequest struct {
+		Hash []byte   `json:"hash"`
+		Path []uint32 `json:"path"`
+	}
+
+	derivedPubkeyRequest struct {
+		Path []uint32 `json:"path"`
+	}
+
+	// response carries either the requested bytes or an error message.
+	response struct {
+		Data  []byte `json:"data,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+)
+
+// ErrUnauthorized is returned when the request or the response authentication fails.
+var ErrUnauthorized = fmt.Errorf("unauthorized")
+
+func checkSecret(secret []byte) error {
+	if len(secret) < MinSecretSize {
+		return fmt.Errorf("secret is too short: %d bytes, at least %d required", len(secret), MinSecretSize)
+	}
+	return nil
+}
+
+// authCode calculates HMAC-SHA256 over the request path, timestamp, nonce and body.
+func authCode(secret []byte, path string, timestamp int64, nonce string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(path))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{0})
+	mac.Write([]byte(nonce))
+	mac.Write([]byte{0})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// responseCode calculates HMAC-SHA256 over the response bound to the request by its authentication code.
+func responseCode(secret []byte, requestCode []byte, status int, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("response"))
+	mac.Write([]byte{0})
+	mac.Write(requestCode)
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.Itoa(status)))
+	mac.Write([]byte{0})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// authenticate signs the request and returns its authentication code.
+func authenticate(req *http.Request, secret []byte, path string, body []byte) ([]byte, error) {
+	nonceBytes := make([]byte, nonceSize)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, err
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	timestamp := time.Now().Unix()
+	code := authCode(secret, path, timestamp, nonce, body)
+	req.Header.Set(headerTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(headerNonce, nonce)
+	req.Header.Set(headerSignature, hex.EncodeToString(code))
+	return code, nil
+}
+
+// verifyAuth checks the request authentication code and remembers the nonce to reject replays.
+func verifyAuth(req *http.Request, secret []byte, body []byte, nonces *nonceCache) error {
+	timestamp, err := strconv.ParseInt(req.Header.Get(headerTimestamp), 10, 64)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		return ErrUnauthorized
+	}
+	nonce := req.Header.Get(headerNonce)
+	if len(nonce) != 2*nonceSize {
+		return ErrUnauthorized
+	}
+	signature, err := hex.DecodeString(req.Header.Get(headerSignature))
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if !hmac.Equal(signature, authCode(secret, req.URL.Path, timestamp, nonce, body)) {
+		return ErrUnauthorized
+	}
+	if !nonces.add(nonce, time.Unix(timestamp, 0).Add(maxClockSkew)) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// verifyResponse checks the response authentication code against the request one.
+func verifyResponse(resp *http.Response, secret []byte, requestCode []byte, body []byte) error {
+	signature, err := hex.DecodeString(resp.Header.Get(headerSignature))
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if !hmac.Equal(signature, responseCode(secret, requestCode, resp.StatusCode, body)) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// nonceCache remembers the nonces of the requests until their timestamps leave the allowed window.
+type nonceCache struct {
+	mu     sync.Mutex
+	expiry map[string]time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{expiry: make(map[string]time.Time)}
+}
+
+// add returns false if the nonce has been already seen.
+func (c *nonceCache) add(nonce string, expiry time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for n, e := range c.expiry {
+		if now.After(e) {
+			delete(c.expiry, n)
+		}
+	}
+	if _, ok := c.expiry[nonce]; ok {
+		return false
+	}
+	c.expiry[nonce] = expiry
+	return true
+}
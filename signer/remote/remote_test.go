@@ -0,0 +1,160 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	signers "github.com/stanche/crypto-interface/signer"
+)
+
+func TestClient_KeyProvider(t *testing.T) {
+	secret := []byte("shared secret of the remote signer")
+	component1, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	local := signers.New(component1)
+
+	handler, err := NewServer(local, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	remoteClient, err := NewClient(server.URL, secret, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var client signers.KeyProvider = remoteClient
+
+	cases := []struct {
+		name string
+		exec func(t *testing.T)
+	}{
+		{
+			"Positive getting public key and chain code",
+			func(t *testing.T) {
+				pkExpected, _ := local.GetPublicKey()
+				ccExpected, _ := local.GetChainCode()
+
+				pk, err := client.GetPublicKey()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, pkExpected, pk, "unexpected public key")
+
+				cc, err := client.GetChainCode()
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, ccExpected, cc, "unexpected chain code")
+			},
+		},
+		{
+			"Positive getting derived public key",
+			func(t *testing.T) {
+				path := []uint32{0, 1000}
+				pkExpected, _ := local.DerivedPubkey(path)
+
+				pk, err := client.DerivedPubkey(path)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, pkExpected, pk, "unexpected public key")
+			},
+		},
+		{
+			"Positive signing tx through the remote signer (signer-a)",
+			func(t *testing.T) {
+				txData := []byte("0200000001db172762bebe28c7f79bcea59647ca37e4e38603618bebbf8407bf44b727c58f00000000fd16010001ff01ff4d0e01524c57ff0488b21e0000000000000000002231c2b6a33377bc6fb0806268e3627602987340ed2c5e6be0d7be7f24161bae038b8001ff63faf92876effaa8cb774ee8a7260b014922607e191b22fb88d3ef1700000000020000004c57ff0488b21e000000000000000000d77de533cea4f03402d513aa6b682cd1a69409564a6c4cddb37c8eed4705d0c603d2a614051301da597eea74316d7e404d89d5eb850238c2c1b3d536c5d5c07a5900000000020000004c57ff0488b21e0000000000000000005c65a74ec6c4922e3df98f50f7c297f62477d123989d9c69ad7de1322cc8394c02cc24a901a51e4e1525343049f11ded77391bf579bc020f08e6956a6eadb13b5a000000000200000053aeffffffff019c35f8030000000017a9140a4aa12d8ff4bf38647a21bb9f72c3602fecaa448700000000")
+
+				signExpected, _ := signers.NewBtcSigner("BTC", local, &signers.BtcNetParams, signers.BtcTxInputSignature).Sign(txData, nil)
+				sign, err := signers.NewBtcSigner("BTC", client, &signers.BtcNetParams, signers.BtcTxInputSignature).Sign(txData, nil)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, signExpected, sign, "unexpected signature")
+			},
+		},
+		{
+			"Positive Schnorr signing through the remote signer",
+			func(t *testing.T) {
+				hash := make([]byte, 32)
+				path := []uint32{0, 2}
+				signExpected, _ := local.SignSchnorrDerived(hash, path)
+
+				sign, err := client.(signers.SchnorrKeyProvider).SignSchnorrDerived(hash, path)
+				assert.Nil(t, err, "unexpected error")
+				assert.Equal(t, signExpected, sign, "unexpected signature")
+			},
+		},
+		{
+			"Negative wrong secret",
+			func(t *testing.T) {
+				other, err := NewClient(server.URL, []byte("other secret of the remote signer"), 10)
+				assert.Nil(t, err, "unexpected error")
+
+				_, err = other.GetChainCode()
+				assert.Equal(t, ErrUnauthorized, err, "unexpected error")
+			},
+		},
+		{
+			"Negative short secret",
+			func(t *testing.T) {
+				_, err := NewServer(local, []byte("shared secret"))
+				assert.NotNil(t, err, "expected error for the server")
+				_, err = NewClient(server.URL, []byte("shared secret"), 10)
+				assert.NotNil(t, err, "expected error for the client")
+			},
+		},
+		{
+			"Negative replayed request",
+			func(t *testing.T) {
+				body := []byte(`{"path":[0,1]}`)
+				req, _ := http.NewRequest(http.MethodPost, server.URL+pathDerivedPubkey, bytes.NewReader(body))
+				_, err := authenticate(req, secret, pathDerivedPubkey, body)
+				assert.Nil(t, err, "unexpected error")
+
+				resp, err := http.DefaultClient.Do(req)
+				assert.Nil(t, err, "unexpected error")
+				resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status")
+
+				replay, _ := http.NewRequest(http.MethodPost, server.URL+pathDerivedPubkey, bytes.NewReader(body))
+				replay.Header = req.Header
+				resp, err = http.DefaultClient.Do(replay)
+				assert.Nil(t, err, "unexpected error")
+				resp.Body.Close()
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "replayed request shall be rejected")
+			},
+		},
+		{
+			"Negative forged response",
+			func(t *testing.T) {
+				// the proxy passes the request to the server and substitutes the public key
+				forged, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+				proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					body, _ := ioutil.ReadAll(req.Body)
+					forward, _ := http.NewRequest(http.MethodPost, server.URL+req.URL.Path, bytes.NewReader(body))
+					forward.Header = req.Header
+					resp, err := http.DefaultClient.Do(forward)
+					if err != nil {
+						w.WriteHeader(http.StatusBadGateway)
+						return
+					}
+					resp.Body.Close()
+					w.Header().Set(headerSignature, resp.Header.Get(headerSignature))
+					json.NewEncoder(w).Encode(response{Data: forged})
+				}))
+				defer proxy.Close()
+
+				proxied, _ := NewClient(proxy.URL, secret, 10)
+				_, err := proxied.DerivedPubkey([]uint32{0, 1})
+				assert.Equal(t, ErrUnauthorized, err, "unexpected error")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
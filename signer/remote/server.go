@@ -0,0 +1,123 @@
+package remote
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	signers "github.com/stanche/crypto-interface/signer"
+)
+
+// maxRequestSize limits the size of the request body accepted by the Server.
+const maxRequestSize = 1 << 16
+
+// Server exposes a local key provider to the remote Client over HTTP.
+type Server struct {
+	keyProvider signers.KeyProvider
+	secret      []byte
+	nonces      *nonceCache
+}
+
+// NewServer wraps keyProvider into http.Handler. secret is shared with the clients.
+func NewServer(keyProvider signers.KeyProvider, secret []byte) (*Server, error) {
+	if err := checkSecret(secret); err != nil {
+		return nil, err
+	}
+	return &Server{
+		keyProvider: keyProvider,
+		secret:      secret,
+		nonces:      newNonceCache(),
+	}, nil
+}
+
+// ServeHTTP implements http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	// the response is bound to the request by its authentication code
+	requestCode, _ := hex.DecodeString(req.Header.Get(headerSignature))
+	writeResponse := func(w http.ResponseWriter, status int, data []byte, err error) {
+		s.writeResponse(w, requestCode, status, data, err)
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestSize))
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, nil, err)
+		return
+	}
+	if err = verifyAuth(req, s.secret, body, s.nonces); err != nil {
+		writeResponse(w, http.StatusUnauthorized, nil, err)
+		return
+	}
+
+	var data []byte
+	switch req.URL.Path {
+	case pathSignDerived:
+		var r signDerivedRequest
+		if err = json.Unmarshal(body, &r); err != nil {
+			writeResponse(w, http.StatusBadRequest, nil, err)
+			return
+		}
+		data, err = s.keyProvider.SignDerived(r.Hash, r.Path)
+	case pathSignSchnorr:
+		var r signDerivedRequest
+		if err = json.Unmarshal(body, &r); err != nil {
+			writeResponse(w, http.StatusBadRequest, nil, err)
+			return
+		}
+		schnorrProvider, ok := s.keyProvider.(signers.SchnorrKeyProvider)
+		if !ok {
+			writeResponse(w, http.StatusNotImplemented, nil, fmt.Errorf("schnorr signatures are not supported"))
+			return
+		}
+		data, err = schnorrProvider.SignSchnorrDerived(r.Hash, r.Path)
+	case pathDerivedPubkey:
+		var r derivedPubkeyRequest
+		if err = json.Unmarshal(body, &r); err != nil {
+			writeResponse(w, http.StatusBadRequest, nil, err)
+			return
+		}
+		var pk *ecdsa.PublicKey
+		pk, err = s.keyProvider.DerivedPubkey(r.Path)
+		data = serializePubkey(pk)
+	case pathPublicKey:
+		var pk *ecdsa.PublicKey
+		pk, err = s.keyProvider.GetPublicKey()
+		data = serializePubkey(pk)
+	case pathChainCode:
+		data, err = s.keyProvider.GetChainCode()
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, nil, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, data, nil)
+}
+
+func serializePubkey(pk *ecdsa.PublicKey) []byte {
+	if pk == nil {
+		return nil
+	}
+	return (*btcec.PublicKey)(pk).SerializeCompressed()
+}
+
+func (s *Server) writeResponse(w http.ResponseWriter, requestCode []byte, status int, data []byte, err error) {
+	res := response{Data: data}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	body, _ := json.Marshal(res)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(headerSignature, hex.EncodeToString(responseCode(s.secret, requestCode, status, body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,122 @@
+// Package schnorr implements the Schnorr signatures accepted by BCH since the May 2019 upgrade.
+// A signature is 64 bytes: R.x || s, where R.y is a quadratic residue and
+// e = sha256(R.x || compressed P || m).
+package schnorr
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/stanche/crypto-interface/abi"
+)
+
+// SignatureSize is the size of the signature without the hash type.
+const SignatureSize = 64
+
+// nonceAlgo is passed as the additional data to RFC6979, so that the nonces differ from ECDSA ones.
+var nonceAlgo = []byte("Schnorr+SHA256  ")
+
+// Sign signs the 32-byte hash with the private key.
+func Sign(key *btcec.PrivateKey, hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("invalid hash size %d", len(hash))
+	}
+	curve := btcec.S256()
+	d := key.D
+	if d.Sign() == 0 || d.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	k := nonceRFC6979(d, hash, nonceAlgo)
+	rx, ry := curve.ScalarBaseMult(k.Bytes())
+	if big.Jacobi(ry, curve.P) != 1 {
+		k.Sub(curve.N, k)
+	}
+	pub := (*btcec.PublicKey)(&key.PublicKey)
+	e := challenge(rx, pub, hash)
+
+	s := new(big.Int).Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+
+	sig := make([]byte, SignatureSize)
+	abi.PutUint(sig[:32], rx)
+	abi.PutUint(sig[32:], s)
+	return sig, nil
+}
+
+// Verify checks the 64-byte signature of the hash against the public key.
+func Verify(pub *btcec.PublicKey, hash, sig []byte) bool {
+	if len(sig) != SignatureSize || len(hash) != 32 || pub == nil {
+		return false
+	}
+	curve := btcec.S256()
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Cmp(curve.P) >= 0 || s.Cmp(curve.N) >= 0 {
+		return false
+	}
+	e := challenge(r, pub, hash)
+	// R = s*G - e*P
+	e.Sub(curve.N, e)
+	sx, sy := curve.ScalarBaseMult(s.Bytes())
+	ex, ey := curve.ScalarMult(pub.X, pub.Y, e.Bytes())
+	rx, ry := curve.Add(sx, sy, ex, ey)
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return false
+	}
+	if big.Jacobi(ry, curve.P) != 1 {
+		return false
+	}
+	return rx.Cmp(r) == 0
+}
+
+// challenge calculates e = sha256(R.x || compressed P || m) mod n.
+func challenge(rx *big.Int, pub *btcec.PublicKey, hash []byte) *big.Int {
+	var buf [32]byte
+	abi.PutUint(buf[:], rx)
+	h := sha256.New()
+	h.Write(buf[:])
+	h.Write(pub.SerializeCompressed())
+	h.Write(hash)
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, btcec.S256().N)
+}
+
+// nonceRFC6979 generates the deterministic nonce as described in RFC6979 section 3.2 with the additional data.
+func nonceRFC6979(d *big.Int, hash []byte, extra []byte) *big.Int {
+	n := btcec.S256().N
+	var x [32]byte
+	abi.PutUint(x[:], d)
+	h := new(big.Int).SetBytes(hash)
+	h.Mod(h, n)
+	var hb [32]byte
+	abi.PutUint(hb[:], h)
+
+	k := make([]byte, 32)
+	v := bytes.Repeat([]byte{0x01}, 32)
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(sha256.New, key)
+		for _, b := range data {
+			m.Write(b)
+		}
+		return m.Sum(nil)
+	}
+	k = mac(k, v, []byte{0x00}, x[:], hb[:], extra)
+	v = mac(k, v)
+	k = mac(k, v, []byte{0x01}, x[:], hb[:], extra)
+	v = mac(k, v)
+	for {
+		v = mac(k, v)
+		nonce := new(big.Int).SetBytes(v)
+		if nonce.Sign() > 0 && nonce.Cmp(n) < 0 {
+			return nonce
+		}
+		k = mac(k, v, []byte{0x00})
+		v = mac(k, v)
+	}
+}
@@ -0,0 +1,90 @@
+package schnorr
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		pubkey string
+		msg    string
+		sig    string
+		valid  bool
+	}{
+		{
+			name:   "vector 1",
+			pubkey: "0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			msg:    "0000000000000000000000000000000000000000000000000000000000000000",
+			sig:    "787A848E71043D280C50470E8E1532B2DD5D20EE912A45DBDD2BD1DFBF187EF67031A98831859DC34DFFEEDDA86831842CCD0079E1F92AF177F7F22CC1DCED05",
+			valid:  true,
+		},
+		{
+			name:   "vector 2",
+			pubkey: "02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			sig:    "2A298DACAE57395A15D0795DDBFD1DCB564DA82B0F269BC70A74F8220429BA1D1E51A22CCEC35599B8F266912281F8365FFC2D035A230434A1A64DC59F7013FD",
+			valid:  true,
+		},
+		{
+			name:   "modified message",
+			pubkey: "0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			msg:    "0000000000000000000000000000000000000000000000000000000000000001",
+			sig:    "787A848E71043D280C50470E8E1532B2DD5D20EE912A45DBDD2BD1DFBF187EF67031A98831859DC34DFFEEDDA86831842CCD0079E1F92AF177F7F22CC1DCED05",
+			valid:  false,
+		},
+		{
+			name:   "s is not less than n",
+			pubkey: "0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			msg:    "0000000000000000000000000000000000000000000000000000000000000000",
+			sig:    "787A848E71043D280C50470E8E1532B2DD5D20EE912A45DBDD2BD1DFBF187EF6FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+			valid:  false,
+		},
+		{
+			name:   "short signature",
+			pubkey: "0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			msg:    "0000000000000000000000000000000000000000000000000000000000000000",
+			sig:    "787A848E71043D280C50470E8E1532B2DD5D20EE912A45DBDD2BD1DFBF187EF6",
+			valid:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubBytes, _ := hex.DecodeString(tt.pubkey)
+			pub, err := btcec.ParsePubKey(pubBytes, btcec.S256())
+			assert.Nil(t, err, "unexpected error")
+			msg, _ := hex.DecodeString(tt.msg)
+			sig, _ := hex.DecodeString(tt.sig)
+
+			assert.Equal(t, tt.valid, Verify(pub, msg, sig), "unexpected verification result")
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	keyBytes, _ := hex.DecodeString("0635671834e54c61b9352f26595d9615ef1e5840c7f64af198e4a10ed7140dd0")
+	key, pub := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
+	other, _ := btcec.NewPrivateKey(btcec.S256())
+
+	for i := byte(0); i < 16; i++ {
+		hash := make([]byte, 32)
+		hash[0] = i
+
+		sig, err := Sign(key, hash)
+		assert.Nil(t, err, "unexpected error")
+		assert.Len(t, sig, SignatureSize, "unexpected signature size")
+		assert.True(t, Verify(pub, hash, sig), "signature shall verify")
+		assert.False(t, Verify(other.PubKey(), hash, sig), "signature shall not verify with other key")
+
+		// the nonce is deterministic
+		again, _ := Sign(key, hash)
+		assert.Equal(t, sig, again, "unexpected signature")
+	}
+
+	_, err := Sign(key, []byte{1, 2, 3})
+	assert.NotNil(t, err, "expected error on invalid hash size")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/stanche/crypto-interface/address/hd"
 )
 
 const (
@@ -204,7 +205,7 @@ func ChildFromXkeyPath(xkey *hdkeychain.ExtendedKey, path []uint32) (*hdkeychain
 
 	var err error
 	for _, index := range path {
-		xkey, err = xkey.Child(uint32(index))
+		xkey, err = hd.Child(xkey, uint32(index))
 		if err != nil {
 			return nil, err
 		}
@@ -216,14 +217,9 @@ func ChildFromXkeyPath(xkey *hdkeychain.ExtendedKey, path []uint32) (*hdkeychain
 	return xkey, nil
 }
 
-// PubkeysIndexPathFromScript extract xpubs from redeen script,
-// searches for the xpub provided and returns it index in the result list,
-// builds and returns a list of unsorted pubkeys
-func PubkeysIndexPathFromScript(redeem []byte, xpub *ecdsa.PublicKey) (
-	m byte, pubkeys []*btcec.PublicKey, index int, xpath []uint32, err error) {
-
-	var pubSample *btcec.PublicKey
-	index = -1 // not found
+// XpubsFromScript parses Electrum encoded multisig redeem script,
+// returns the number of required signatures and unsorted xpubs with their paths
+func XpubsFromScript(redeem []byte) (m byte, xpubs []*hdkeychain.ExtendedKey, paths [][]uint32, err error) {
 
 	// parse redeemScript
 	msScript, err := parseScript(redeem)
@@ -261,10 +257,8 @@ func PubkeysIndexPathFromScript(redeem []byte, xpub *ecdsa.PublicKey) (
 		return
 	}
 
-	pubkeys = make([]*btcec.PublicKey, n)
-	if xpub != nil {
-		pubSample = (*btcec.PublicKey)(xpub)
-	}
+	xpubs = make([]*hdkeychain.ExtendedKey, n)
+	paths = make([][]uint32, n)
 
 	ofs := 1
 	for i := ofs; i < sz-2; i++ {
@@ -284,44 +278,66 @@ func PubkeysIndexPathFromScript(redeem []byte, xpub *ecdsa.PublicKey) (
 				b)
 			return
 		}
-		var xpub *hdkeychain.ExtendedKey
-		var path []uint32
-		xpub, path, err = xpubFromElectrumEncoded(msScript[i].Data[1:])
+		xpubs[i-ofs], paths[i-ofs], err = xpubFromElectrumEncoded(msScript[i].Data[1:])
 		if err != nil {
 			return
 		}
+	}
+	return
+}
+
+// PubkeysIndexPathFromScript extract xpubs from redeen script,
+// searches for the xpub provided and returns it index in the result list,
+// builds and returns a list of unsorted pubkeys
+func PubkeysIndexPathFromScript(redeem []byte, xpub *ecdsa.PublicKey) (
+	m byte, pubkeys []*btcec.PublicKey, index int, xpath []uint32, err error) {
+
+	var pubSample *btcec.PublicKey
+	index = -1 // not found
+
+	m, xpubs, paths, err := XpubsFromScript(redeem)
+	if err != nil {
+		return
+	}
+
+	pubkeys = make([]*btcec.PublicKey, len(xpubs))
+	if xpub != nil {
+		pubSample = (*btcec.PublicKey)(xpub)
+	}
 
+	for i := range xpubs {
 		var pub *btcec.PublicKey
 		// Extract pubkey from xpub to compare with provided xpub
 		if pubSample != nil {
-			pub, err = xpub.ECPubKey()
+			pub, err = xpubs[i].ECPubKey()
 			if err != nil {
 				return
 			}
 			if pubSample.IsEqual(pub) {
 				if index != -1 || xpath != nil {
-					err = fmt.Errorf("wrong xpub set in script: %d/%d", index, i-ofs)
+					err = fmt.Errorf("wrong xpub set in script: %d/%d", index, i)
 					return
 				}
-				index = i - ofs
-				xpath = path
+				index = i
+				xpath = paths[i]
 			}
 		}
 
-		xpub, err = ChildFromXkeyPath(xpub, path)
+		var child *hdkeychain.ExtendedKey
+		child, err = ChildFromXkeyPath(xpubs[i], paths[i])
 		if err != nil {
 			return
 		}
 
-		pub, err = xpub.ECPubKey()
+		pub, err = child.ECPubKey()
 		if err != nil {
 			return
 		}
 		if pub == nil {
-			err = fmt.Errorf("empty public key [%d]", i-ofs)
+			err = fmt.Errorf("empty public key [%d]", i)
 			return
 		}
-		pubkeys[i-ofs] = pub
+		pubkeys[i] = pub
 	}
 	return
 }
@@ -0,0 +1,36 @@
+// Package txsize estimates the sizes of the signed P2SH multisig inputs, it's shared by the connectors
+// and the signer.
+package txsize
+
+const (
+	// SignaturePush is the maximal size of a pushed DER signature with the hash type.
+	SignaturePush = 1 + 72 + 1
+	// compressedKeySize is the size of a compressed public key.
+	compressedKeySize = 33
+
+	opPushData1 = 0x4c
+)
+
+// PushedSize returns the size of data push of size bytes.
+func PushedSize(size int) int {
+	switch {
+	case size < opPushData1:
+		return 1 + size
+	case size <= 0xff:
+		return 2 + size
+	case size <= 0xffff:
+		return 3 + size
+	}
+	return 5 + size
+}
+
+// MultisigScriptSize returns the size of m-of-n multisig script with compressed keys.
+func MultisigScriptSize(n int) int {
+	return 1 + n*(1+compressedKeySize) + 2
+}
+
+// ScriptSigSize returns the maximal size of m-of-n P2SH multisig scriptSig:
+// OP_0 <signature>... <redeem script>.
+func ScriptSigSize(m, n int) int {
+	return 1 + m*SignaturePush + PushedSize(MultisigScriptSize(n))
+}
@@ -0,0 +1,32 @@
+package txsize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushedSize(t *testing.T) {
+	tests := []struct {
+		size     int
+		expected int
+	}{
+		{0, 1},
+		{75, 76},
+		{76, 78},
+		{0xff, 0x101},
+		{0x100, 0x103},
+		{0x10000, 0x10005},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, PushedSize(tt.size), "unexpected size of %d bytes push", tt.size)
+	}
+}
+
+func TestScriptSigSize(t *testing.T) {
+	// 2-of-3: OP_0, 2 signatures, OP_PUSHDATA1 of 105 bytes script
+	assert.Equal(t, 105, MultisigScriptSize(3), "unexpected script size")
+	assert.Equal(t, 1+2*74+2+105, ScriptSigSize(2, 3), "unexpected scriptSig size")
+	// 15-of-15 fits MaxStandardScriptSigSize of 1650 bytes
+	assert.Equal(t, 1+15*74+3+513, ScriptSigSize(15, 15), "unexpected scriptSig size")
+}